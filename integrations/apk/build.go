@@ -1,7 +1,12 @@
 package apk
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
@@ -15,23 +20,96 @@ import (
 )
 
 type Config struct {
-	Source     *source.Source
+	Source       *source.Source
+	Version      string
+	Prerelease   bool
+	Target       target.Target
+	Keys         []Key
+	SignPackages bool
+	Branches     []Branch
+}
+
+var ErrMissingKey = errors.New("signing packages requires an rsa key")
+
+// Branch is an Alpine branch (e.g. v3.20 or edge) and repository (e.g. main)
+// to publish the releases matching the version constraint to.
+type Branch struct {
+	Name       string
+	Repository string
 	Version    string
-	Prerelease bool
-	Target     target.Target
-	RSAKey     *rsa.PrivateKey
-	KeyName    string
 }
 
-// Build creates or updates an APK repository.
+// Build creates or updates an APK repository. If branches are set, a repository
+// is created for each of them at <branch>/<repository>.
 func Build(ctx context.Context, c *Config) error {
-	repo, err := openRepo(ctx, c.Target)
+	if c.SignPackages && len(c.Keys) == 0 {
+		return ErrMissingKey
+	}
+
+	if len(c.Branches) == 0 {
+		if err := build(ctx, c, c.Target, c.Version); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range c.Branches {
+		t := c.Target.Sub(path.Join(b.Name, cmp.Or(b.Repository, "main")))
+		version := c.Version
+		if b.Version != "" {
+			version = strings.TrimPrefix(version+","+b.Version, ",")
+		}
+		if err := build(ctx, c, t, version); err != nil {
+			return fmt.Errorf("branch %s: %w", b.Name, err)
+		}
+	}
+
+	// The keys are shared by all branches, so they're published at the root.
+	for _, key := range c.Keys {
+		if err := writePublicKey(ctx, c.Target, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writePublicKey publishes the public key, unless it is already published.
+func writePublicKey(ctx context.Context, t target.Target, key Key) error {
+	pub, err := rsa.MarshalPublicKey(rsa.Public(key.Key))
+	if err != nil {
+		return err
+	}
+
+	name := key.Name + ".rsa.pub"
+	if r, err := t.NewReader(ctx, name); err == nil {
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err == nil && bytes.Equal(b, pub) {
+			return nil
+		}
+	}
+
+	w, err := t.NewWriter(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(pub); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func build(ctx context.Context, c *Config, t target.Target, version string) error {
+	repo, err := openRepo(ctx, t)
 	if err != nil {
 		return err
 	}
 	defer os.RemoveAll(repo.dir)
 
-	version := c.Version
+	if c.SignPackages {
+		repo.signKeys = c.Keys
+	}
+
 	if v := getVersionConstraint(repo.repos); v != "" {
 		version += "," + v
 	}
@@ -67,16 +145,11 @@ func Build(ctx context.Context, c *Config) error {
 		return nil
 	}
 
-	if err = repo.Write(c.RSAKey, c.KeyName+".rsa.pub"); err != nil {
+	if err = repo.Write(c.Keys); err != nil {
 		return err
 	}
 
-	err = target.CopyFS(ctx, c.Target, os.DirFS(repo.dir))
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return target.CopyFS(ctx, t, os.DirFS(repo.dir))
 }
 
 func getVersionConstraint(repo map[string]*repository.ApkIndex) string {
@@ -2,10 +2,13 @@ package apk_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"gitlab.alpinelinux.org/alpine/go/repository"
 
@@ -27,10 +30,9 @@ func TestBuild(t *testing.T) {
 	t.Run("RSA", func(t *testing.T) {
 		dir := t.TempDir()
 
-		c := &apk.Config{KeyName: "test@example.com"}
+		c := &apk.Config{Keys: newKeys(t, "test@example.com")}
 		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
 		c.Target, _ = target.New(target.Config{Path: dir})
-		c.RSAKey, _ = rsa.NewPrivateKey()
 
 		if err := apk.Build(t.Context(), c); err != nil {
 			t.Fatal(err)
@@ -53,7 +55,7 @@ func TestBuild(t *testing.T) {
 		}
 
 		pub, _ := rsa.UnmarshalPublicKey(got["test@example.com.rsa.pub"].Data)
-		if diff := cmp.Diff(pub, rsa.Public(c.RSAKey)); diff != "" {
+		if diff := cmp.Diff(pub, rsa.Public(c.Keys[0].Key)); diff != "" {
 			t.Fatal(diff)
 		}
 
@@ -72,4 +74,119 @@ func TestBuild(t *testing.T) {
 			}
 		}
 	})
+	t.Run("Branches", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apk.Config{
+			Keys: newKeys(t, "test@example.com"),
+			Branches: []apk.Branch{
+				{Name: "v3.20", Version: "<2"},
+				{Name: "edge", Repository: "community"},
+			},
+		}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := apk.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		err := fstest.TestFS(got,
+			"test@example.com.rsa.pub",
+			"v3.20/main/x86_64/APKINDEX.tar.gz",
+			"v3.20/main/x86/APKINDEX.tar.gz",
+			"edge/community/x86_64/APKINDEX.tar.gz",
+			"edge/community/x86/APKINDEX.tar.gz",
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := got["v3.20/main/x86_64/kubri-test-2.0.0.apk"]; ok {
+			t.Error("v3.20 should not contain releases outside of the version constraint")
+		}
+		if _, ok := got["edge/community/x86_64/kubri-test-2.0.0.apk"]; !ok {
+			t.Error("edge should contain all releases")
+		}
+	})
+
+	t.Run("SignPackages", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apk.Config{Keys: newKeys(t, "current", "next"), SignPackages: true}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := apk.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		err := fstest.TestFS(got, "current.rsa.pub", "next.rsa.pub")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, arch := range []string{"x86_64", "x86"} {
+			verifySignatures(t, got[arch+"/APKINDEX.tar.gz"].Data, c.Keys)
+
+			for _, name := range []string{"kubri-test-1.0.0.apk", "kubri-test-1.1.0.apk", "kubri-test-2.0.0.apk"} {
+				want, _ := os.ReadFile("testdata/" + arch + "/" + name)
+				if rest := verifySignatures(t, got[arch+"/"+name].Data, c.Keys); !bytes.Equal(rest, want) {
+					t.Fatalf("%s/%s: should not modify control and data streams", arch, name)
+				}
+			}
+		}
+	})
+
+	t.Run("PublicKeys", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apk.Config{Keys: newKeys(t, "current", "next")}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := apk.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		for _, name := range []string{"current.rsa.pub", "next.rsa.pub"} {
+			if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Rotate only the next key.
+		c.Keys[1] = newKeys(t, "next")[0]
+		if err := apk.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		for name, changed := range map[string]bool{"current.rsa.pub": false, "next.rsa.pub": true} {
+			fi, err := os.Stat(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fi.ModTime().Equal(old) == changed {
+				t.Errorf("%s: want changed %t", name, changed)
+			}
+		}
+
+		b, _ := os.ReadFile(filepath.Join(dir, "next.rsa.pub"))
+		pub, _ := rsa.UnmarshalPublicKey(b)
+		if !rsa.Public(c.Keys[1].Key).Equal(pub) {
+			t.Error("should publish the new key")
+		}
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		c := &apk.Config{SignPackages: true}
+		if err := apk.Build(t.Context(), c); !errors.Is(err, apk.ErrMissingKey) {
+			t.Fatalf("want %v got %v", apk.ErrMissingKey, err)
+		}
+	})
 }
@@ -0,0 +1,6 @@
+package apk
+
+var (
+	SignArchive = signArchive
+	SignPackage = signPackage
+)
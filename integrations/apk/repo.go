@@ -12,13 +12,13 @@ import (
 
 	"gitlab.alpinelinux.org/alpine/go/repository"
 
-	"github.com/kubri/kubri/pkg/crypto/rsa"
 	"github.com/kubri/kubri/target"
 )
 
 type repo struct {
-	repos map[string]*repository.ApkIndex
-	dir   string
+	repos    map[string]*repository.ApkIndex
+	dir      string
+	signKeys []Key
 }
 
 func openRepo(ctx context.Context, t target.Target) (*repo, error) {
@@ -55,6 +55,13 @@ func openRepo(ctx context.Context, t target.Target) (*repo, error) {
 }
 
 func (r *repo) Add(b []byte) error {
+	if r.signKeys != nil {
+		var err error
+		if b, err = signPackage(b, r.signKeys); err != nil {
+			return err
+		}
+	}
+
 	p, err := repository.ParsePackage(bytes.NewReader(b))
 	if err != nil {
 		return err
@@ -76,39 +83,27 @@ func (r *repo) Add(b []byte) error {
 	return os.WriteFile(filepath.Join(dirname, filename), b, 0o600)
 }
 
-func (r *repo) Write(rsaKey *rsa.PrivateKey, publicKeyName string) error {
+func (r *repo) Write(keys []Key) error {
 	for arch, index := range r.repos {
 		rd, err := repository.ArchiveFromIndex(index)
 		if err != nil {
 			return err
 		}
+		b, err := io.ReadAll(rd)
+		if err != nil {
+			return err
+		}
 
-		if rsaKey != nil {
-			rd, err = repository.SignArchive(rd, rsaKey, publicKeyName)
-			if err != nil {
+		if len(keys) > 0 {
+			if b, err = signArchive(b, keys); err != nil {
 				return err
 			}
 		}
 
 		path := filepath.Join(r.dir, arch, "APKINDEX.tar.gz")
-		f, err := os.Create(path)
-		if err != nil {
-			return err
-		}
-		if _, err = io.Copy(f, rd); err != nil {
-			return err
-		}
-		if err = f.Close(); err != nil {
-			return err
-		}
-	}
-
-	if rsaKey != nil {
-		pub, err := rsa.MarshalPublicKey(rsa.Public(rsaKey))
-		if err != nil {
+		if err = os.WriteFile(path, b, 0o600); err != nil {
 			return err
 		}
-		return os.WriteFile(filepath.Join(r.dir, publicKeyName), pub, 0o600)
 	}
 
 	return nil
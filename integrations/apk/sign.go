@@ -0,0 +1,146 @@
+package apk
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/kubri/kubri/pkg/crypto/rsa"
+)
+
+var ErrInvalidAPK = errors.New("invalid apk")
+
+// Key is an RSA key used to sign the repository. Users install the public key
+// to /etc/apk/keys/<name>.rsa.pub.
+type Key struct {
+	Name string
+	Key  *rsa.PrivateKey
+}
+
+// signArchive prepends signatures of the gzip stream to it.
+func signArchive(b []byte, keys []Key) ([]byte, error) {
+	sig, err := signatures(b, keys)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig, b...), nil
+}
+
+// signPackage replaces the signatures of a package. Packages consist of a
+// signature, control and data gzip stream, where only the control stream is
+// signed. The control stream holds the hash of the data stream.
+func signPackage(b []byte, keys []Key) ([]byte, error) {
+	streams, err := splitStreams(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(streams) > 0 && isSignature(streams[0]) {
+		streams = streams[1:]
+	}
+	if len(streams) != 2 {
+		return nil, ErrInvalidAPK
+	}
+
+	sig, err := signatures(streams[0], keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(sig, streams[0], streams[1]), nil
+}
+
+// signatures returns a gzip stream with a signature of b for each key.
+//
+// apk-tools uses the first signature made by a trusted key. SHA-256 signatures
+// come first, as they are preferred by newer versions, followed by SHA-1
+// signatures for older versions which don't support them.
+func signatures(b []byte, keys []Key) ([]byte, error) {
+	sha256Sum := sha256.Sum256(b)
+	sha1Sum := sha1.Sum(b)
+
+	algos := []struct {
+		prefix string
+		hash   crypto.Hash
+		sum    []byte
+	}{
+		{".SIGN.RSA256.", crypto.SHA256, sha256Sum[:]},
+		{".SIGN.RSA.", crypto.SHA1, sha1Sum[:]},
+	}
+
+	var buf bytes.Buffer
+	gw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	tw := tar.NewWriter(gw)
+
+	for _, algo := range algos {
+		for _, key := range keys {
+			sig, err := key.Key.Sign(nil, algo.sum, algo.hash)
+			if err != nil {
+				return nil, err
+			}
+			err = tw.WriteHeader(&tar.Header{
+				Name:   algo.prefix + key.Name + ".rsa.pub",
+				Mode:   0o644,
+				Size:   int64(len(sig)),
+				Uname:  "root",
+				Gname:  "root",
+				Format: tar.FormatUSTAR,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if _, err = tw.Write(sig); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The end-of-archive marker is left out, as the stream is concatenated with
+	// the signed one.
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// splitStreams returns the concatenated gzip streams.
+func splitStreams(b []byte) ([][]byte, error) {
+	var streams [][]byte
+
+	// bytes.Reader is an io.ByteReader, so gzip doesn't read past the end of
+	// the stream.
+	r := bytes.NewReader(b)
+	for r.Len() > 0 {
+		start := len(b) - r.Len()
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, ErrInvalidAPK
+		}
+		zr.Multistream(false)
+		if _, err = io.Copy(io.Discard, zr); err != nil {
+			return nil, ErrInvalidAPK
+		}
+		streams = append(streams, b[start:len(b)-r.Len()])
+	}
+
+	return streams, nil
+}
+
+func isSignature(stream []byte) bool {
+	zr, err := gzip.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return false
+	}
+	hdr, err := tar.NewReader(zr).Next()
+	return err == nil && strings.HasPrefix(hdr.Name, ".SIGN.")
+}
@@ -0,0 +1,143 @@
+package apk_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/kubri/kubri/integrations/apk"
+	"github.com/kubri/kubri/pkg/crypto/rsa"
+)
+
+func TestSignPackage(t *testing.T) {
+	b, err := os.ReadFile("testdata/x86_64/kubri-test-1.0.0.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := newKeys(t, "current", "next")
+
+	// Sign twice to ensure existing signatures are replaced.
+	signed, err := apk.SignPackage(b, keys[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err = apk.SignPackage(signed, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rest := verifySignatures(t, signed, keys)
+	if !bytes.Equal(rest, b) {
+		t.Fatal("should not modify control and data streams")
+	}
+}
+
+func TestSignPackageError(t *testing.T) {
+	keys := newKeys(t, "test")
+
+	for _, b := range [][]byte{nil, []byte("not an apk")} {
+		if _, err := apk.SignPackage(b, keys); !errors.Is(err, apk.ErrInvalidAPK) {
+			t.Errorf("want %v got %v", apk.ErrInvalidAPK, err)
+		}
+	}
+}
+
+func TestSignArchive(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	_ = tw.WriteHeader(&tar.Header{Name: "APKINDEX", Mode: 0o644, Size: 4})
+	_, _ = tw.Write([]byte("test"))
+	_ = tw.Close()
+	_ = gw.Close()
+
+	keys := newKeys(t, "current", "next")
+
+	signed, err := apk.SignArchive(buf.Bytes(), keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rest := verifySignatures(t, signed, keys); !bytes.Equal(rest, buf.Bytes()) {
+		t.Fatal("should not modify index")
+	}
+}
+
+func newKeys(t *testing.T, names ...string) []apk.Key {
+	t.Helper()
+
+	keys := make([]apk.Key, len(names))
+	for i, name := range names {
+		key, err := rsa.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = apk.Key{Name: name, Key: key}
+	}
+	return keys
+}
+
+// verifySignatures checks the signature stream holds a SHA-256 and SHA-1
+// signature of the next stream for each key, and returns the signed data.
+func verifySignatures(t *testing.T, b []byte, keys []apk.Key) []byte {
+	t.Helper()
+
+	r := bytes.NewReader(b)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr.Multistream(false)
+
+	sigs := map[string][]byte{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs[hdr.Name], _ = io.ReadAll(tr)
+	}
+	_, _ = io.Copy(io.Discard, zr)
+
+	rest := b[len(b)-r.Len():]
+
+	// Only the first stream after the signatures is signed.
+	if err = zr.Reset(r); err != nil {
+		t.Fatal(err)
+	}
+	zr.Multistream(false)
+	_, _ = io.Copy(io.Discard, zr)
+	signed := rest[:len(rest)-r.Len()]
+
+	if len(sigs) != len(keys)*2 {
+		t.Errorf("want %d signatures got %d", len(keys)*2, len(sigs))
+	}
+
+	for _, key := range keys {
+		pub := rsa.Public(key.Key)
+
+		sum := sha256.Sum256(signed)
+		sig := sigs[".SIGN.RSA256."+key.Name+".rsa.pub"]
+		if err := stdrsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+			t.Errorf("%s: should pass SHA-256 verification: %s", key.Name, err)
+		}
+
+		if !rsa.Verify(pub, signed, sigs[".SIGN.RSA."+key.Name+".rsa.pub"]) {
+			t.Errorf("%s: should pass SHA-1 verification", key.Name)
+		}
+	}
+
+	return rest
+}
@@ -1,8 +1,6 @@
 package appinstaller
 
 import (
-	"archive/zip"
-	"bytes"
 	"context"
 	"encoding/xml"
 	"errors"
@@ -16,7 +14,13 @@ import (
 type Config struct {
 	OnLaunch                  *OnLaunchConfig
 	AutomaticBackgroundTask   bool
+	BackgroundTaskShowPrompt  bool
 	ForceUpdateFromAnyVersion bool
+	UpdateURIs                []string
+	RepairURIs                []string
+	Dependencies              []*Package
+	OptionalPackages          []string
+	RelatedPackages           []string
 
 	Source         *source.Source
 	Target         target.Target
@@ -31,7 +35,10 @@ type OnLaunchConfig struct {
 	UpdateBlocksActivation   bool
 }
 
-var ErrNotValid = errors.New("not a valid bundle")
+var (
+	ErrNotValid          = errors.New("not a valid bundle")
+	ErrInvalidDependency = errors.New("invalid dependency")
+)
 
 func Build(ctx context.Context, c *Config) error {
 	releases, err := c.Source.ListReleases(ctx, &source.ListOptions{
@@ -45,86 +52,121 @@ func Build(ctx context.Context, c *Config) error {
 		return err
 	}
 
-	var r *source.Release
-	for _, r = range releases {
-		var ok bool
+	// Releases are ordered from newest to oldest, so the first package found for
+	// each name & architecture is the latest one. Assets with the same name as a
+	// newer one, apart from the version, are skipped without downloading them.
+	built := map[string]bool{}
+	seen := map[string]bool{}
+	for _, r := range releases {
+		var extra *extraPackages
 		for _, asset := range r.Assets {
-			switch path.Ext(asset.Name) {
-			case ".msix", ".appx", ".msixbundle", ".appxbundle":
-				ok = true
-				if err := build(ctx, c, r.Version, asset); err != nil {
+			if !isPackage(asset.Name) || match(c.OptionalPackages, asset.Name) || match(c.RelatedPackages, asset.Name) {
+				continue
+			}
+
+			key := strings.ReplaceAll(asset.Name, strings.TrimPrefix(r.Version, "v"), "")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			p, deps, b, err := getAsset(ctx, c, r, asset)
+			if err != nil {
+				return err
+			}
+
+			name := fileName(asset, p)
+			if built[name] {
+				continue
+			}
+			built[name] = true
+
+			// Optional and related packages are shared by all packages in the release.
+			if extra == nil {
+				if extra, err = getExtraPackages(ctx, c, r); err != nil {
 					return err
 				}
 			}
-		}
-		if ok {
-			break // Only build for latest release containing supported files.
+
+			if err = setURI(ctx, c, p, asset, b); err != nil {
+				return err
+			}
+			if err = build(ctx, c, asset, p, deps, extra); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-func build(ctx context.Context, c *Config, version string, asset *source.Asset) error {
-	b, err := c.Source.DownloadAsset(ctx, version, asset.Name)
+// extraPackages are the optional and related packages of a release.
+type extraPackages struct {
+	optional *Packages
+	related  *Packages
+}
+
+func getExtraPackages(ctx context.Context, c *Config, r *source.Release) (*extraPackages, error) {
+	optional, err := getPackages(ctx, c, r, c.OptionalPackages)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	p, err := getPackage(b)
+	related, err := getPackages(ctx, c, r, c.RelatedPackages)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return &extraPackages{optional: optional, related: related}, nil
+}
 
-	if c.UploadPackages {
-		p.URI, err = upload(ctx, c.Target, asset.Name, b)
-		if err != nil {
-			return err
-		}
-	} else {
-		p.URI = asset.URL
+// fileName returns the name of the App Installer file for the package.
+func fileName(asset *source.Asset, p *Package) string {
+	if isBundle(asset.Name) {
+		return p.Name + ".appinstaller"
 	}
+	return p.Name + "-" + p.ProcessorArchitecture + ".appinstaller"
+}
+
+func build(ctx context.Context, c *Config, asset *source.Asset, p *Package, deps []*Package, extra *extraPackages) error {
+	var err error
 
 	res := newXML(c)
 	res.Version = p.Version
+	res.OptionalPackages = extra.optional
+	res.RelatedPackages = extra.related
 
-	var name string
-	if strings.HasSuffix(asset.Name, "bundle") {
+	if res.Dependencies, err = getDependencies(c, p, deps); err != nil {
+		return err
+	}
+
+	if isBundle(asset.Name) {
 		res.MainBundle = p
-		name = p.Name + ".appinstaller"
 	} else {
 		res.MainPackage = p
-		name = p.Name + "-" + p.ProcessorArchitecture + ".appinstaller"
 	}
 
+	name := fileName(asset, p)
 	res.URI, err = c.Target.URL(ctx, name)
 	if err != nil {
 		return err
 	}
 
+	if len(c.UpdateURIs) > 0 {
+		res.UpdateURIs = &UpdateURIs{URIs: joinURIs(c.UpdateURIs, name)}
+	}
+	if len(c.RepairURIs) > 0 {
+		res.RepairURIs = &RepairURIs{URIs: joinURIs(c.RepairURIs, name)}
+	}
+
 	return write(ctx, c, res)
 }
 
-func getPackage(b []byte) (*Package, error) {
-	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
-	if err != nil {
-		return nil, err
-	}
-	for _, f := range r.File {
-		if f.Name == "AppxManifest.xml" || f.Name == "AppxBundleManifest.xml" {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, err
-			}
-			defer rc.Close()
-			var manifest struct{ Identity *Package }
-			if err = xml.NewDecoder(rc).Decode(&manifest); err != nil {
-				return nil, err
-			}
-			return manifest.Identity, nil
-		}
+// joinURIs returns the URIs of the App Installer file at each base URL.
+func joinURIs(bases []string, name string) []string {
+	uris := make([]string, len(bases))
+	for i, base := range bases {
+		uris[i] = strings.TrimSuffix(base, "/") + "/" + name
 	}
-	return nil, ErrNotValid
+	return uris
 }
 
 func upload(ctx context.Context, t target.Target, path string, data []byte) (string, error) {
@@ -146,6 +188,10 @@ func newXML(c *Config) *AppInstaller {
 
 	// Get minimum required namespace.
 	switch {
+	case len(c.UpdateURIs) > 0,
+		len(c.RepairURIs) > 0,
+		c.AutomaticBackgroundTask && c.BackgroundTaskShowPrompt:
+		appInstaller.XMLName.Space = "http://schemas.microsoft.com/appx/appinstaller/2021"
 	case c.ForceUpdateFromAnyVersion,
 		c.OnLaunch != nil && c.OnLaunch.ShowPrompt,
 		c.OnLaunch != nil && c.OnLaunch.UpdateBlocksActivation:
@@ -161,7 +207,9 @@ func newXML(c *Config) *AppInstaller {
 	if c.OnLaunch != nil || c.AutomaticBackgroundTask || c.ForceUpdateFromAnyVersion {
 		appInstaller.UpdateSettings = &UpdateSettings{}
 		if c.AutomaticBackgroundTask {
-			appInstaller.UpdateSettings.AutomaticBackgroundTask = Bool(c.AutomaticBackgroundTask)
+			appInstaller.UpdateSettings.AutomaticBackgroundTask = &AutomaticBackgroundTask{
+				ShowPrompt: c.BackgroundTaskShowPrompt,
+			}
 		}
 		if c.ForceUpdateFromAnyVersion {
 			appInstaller.UpdateSettings.ForceUpdateFromAnyVersion = c.ForceUpdateFromAnyVersion
@@ -84,6 +84,31 @@ func TestBuild(t *testing.T) {
 		<AutomaticBackgroundTask />
 		<ForceUpdateFromAnyVersion>true</ForceUpdateFromAnyVersion>
 	</UpdateSettings>
+</AppInstaller>`,
+		},
+		{
+			name: "Test-x64.appinstaller",
+			config: &appinstaller.Config{
+				Source:                   src,
+				Target:                   tgt,
+				AutomaticBackgroundTask:  true,
+				BackgroundTaskShowPrompt: true,
+				UpdateURIs:               []string{"https://mirror.example.com/"},
+				RepairURIs:               []string{"https://example.com", "https://mirror.example.com"},
+			},
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<AppInstaller xmlns="http://schemas.microsoft.com/appx/appinstaller/2021" Version="1.0.0.1" Uri="https://example.com/Test-x64.appinstaller">
+	<MainPackage Name="Test" Publisher="CN=Test" Version="1.0.0.1" ProcessorArchitecture="x64" Uri="https://dl.example.com/v1.0.0/test.msix" />
+	<UpdateSettings>
+		<AutomaticBackgroundTask ShowPrompt="true" />
+	</UpdateSettings>
+	<UpdateUris>
+		<UpdateUri>https://mirror.example.com/Test-x64.appinstaller</UpdateUri>
+	</UpdateUris>
+	<RepairUris>
+		<RepairUri>https://example.com/Test-x64.appinstaller</RepairUri>
+		<RepairUri>https://mirror.example.com/Test-x64.appinstaller</RepairUri>
+	</RepairUris>
 </AppInstaller>`,
 		},
 		{
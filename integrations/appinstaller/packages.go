@@ -0,0 +1,210 @@
+package appinstaller
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kubri/kubri/source"
+)
+
+func isPackage(name string) bool {
+	switch path.Ext(name) {
+	case ".msix", ".appx", ".msixbundle", ".appxbundle":
+		return true
+	}
+	return false
+}
+
+func isBundle(name string) bool {
+	return strings.HasSuffix(name, "bundle")
+}
+
+func match(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getAsset downloads the asset and returns its package identity, dependencies
+// and content.
+func getAsset(ctx context.Context, c *Config, r *source.Release, asset *source.Asset) (*Package, []*Package, []byte, error) {
+	b, err := c.Source.DownloadAsset(ctx, r.Version, asset.Name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	p, deps, err := getPackage(b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return p, deps, b, nil
+}
+
+// setURI sets the package URI to the asset or uploaded package URL.
+func setURI(ctx context.Context, c *Config, p *Package, asset *source.Asset, b []byte) error {
+	if !c.UploadPackages {
+		p.URI = asset.URL
+		return nil
+	}
+	url, err := upload(ctx, c.Target, asset.Name, b)
+	if err != nil {
+		return err
+	}
+	p.URI = url
+	return nil
+}
+
+// getPackages returns the packages in the release matching the patterns.
+func getPackages(ctx context.Context, c *Config, r *source.Release, patterns []string) (*Packages, error) {
+	var res *Packages
+	for _, asset := range r.Assets {
+		if !isPackage(asset.Name) || !match(patterns, asset.Name) {
+			continue
+		}
+		p, _, b, err := getAsset(ctx, c, r, asset)
+		if err != nil {
+			return nil, err
+		}
+		if err = setURI(ctx, c, p, asset, b); err != nil {
+			return nil, err
+		}
+		if res == nil {
+			res = &Packages{}
+		}
+		if isBundle(asset.Name) {
+			res.Bundle = append(res.Bundle, p)
+		} else {
+			res.Package = append(res.Package, p)
+		}
+	}
+	return res, nil
+}
+
+// getDependencies returns the configured dependencies for the architecture of
+// the package. The publisher and version default to those of the matching
+// dependency in the package manifest.
+func getDependencies(c *Config, p *Package, deps []*Package) (*Packages, error) {
+	var res *Packages
+	for _, dep := range c.Dependencies {
+		if p.ProcessorArchitecture != "" && dep.ProcessorArchitecture != "" &&
+			dep.ProcessorArchitecture != "neutral" && dep.ProcessorArchitecture != p.ProcessorArchitecture {
+			continue
+		}
+
+		d := *dep
+		for _, m := range deps {
+			if m.Name == d.Name {
+				d.Publisher = cmp.Or(d.Publisher, m.Publisher)
+				d.Version = cmp.Or(d.Version, m.Version)
+			}
+		}
+		if d.Publisher == "" || d.Version == "" {
+			return nil, fmt.Errorf("%w: missing publisher or version for %s", ErrInvalidDependency, d.Name)
+		}
+
+		if res == nil {
+			res = &Packages{}
+		}
+		res.Package = append(res.Package, &d)
+	}
+
+	for _, m := range deps {
+		if !slices.ContainsFunc(c.Dependencies, func(d *Package) bool { return d.Name == m.Name }) {
+			log.Printf("Skipping dependency %s without URI", m.Name)
+		}
+	}
+
+	return res, nil
+}
+
+// getPackage returns the identity and dependencies from the package manifest.
+// For bundles, the dependencies of all packages in the bundle are returned.
+func getPackage(b []byte) (*Package, []*Package, error) {
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		identity *Package
+		deps     []*Package
+	)
+	for _, f := range r.File {
+		switch {
+		case f.Name == "AppxManifest.xml" || f.Name == "AppxBundleManifest.xml":
+			var p *Package
+			var d []*Package
+			if p, d, err = readManifest(f); err != nil {
+				return nil, nil, err
+			}
+			identity = p
+			deps = append(deps, d...)
+
+		case isPackage(f.Name) && !isBundle(f.Name):
+			var b []byte
+			if b, err = readFile(f); err != nil {
+				return nil, nil, err
+			}
+			var d []*Package
+			if _, d, err = getPackage(b); err != nil {
+				return nil, nil, err
+			}
+			deps = append(deps, d...)
+		}
+	}
+
+	if identity == nil {
+		return nil, nil, ErrNotValid
+	}
+
+	return identity, deps, nil
+}
+
+func readManifest(f *zip.File) (*Package, []*Package, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+
+	var manifest struct {
+		Identity          *Package
+		PackageDependency []struct {
+			Name       string `xml:",attr"`
+			Publisher  string `xml:",attr"`
+			MinVersion string `xml:",attr"`
+		} `xml:"Dependencies>PackageDependency"`
+	}
+	if err = xml.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, nil, err
+	}
+
+	deps := make([]*Package, len(manifest.PackageDependency))
+	for i, d := range manifest.PackageDependency {
+		deps[i] = &Package{Name: d.Name, Publisher: d.Publisher, Version: d.MinVersion}
+	}
+
+	return manifest.Identity, deps, nil
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,198 @@
+package appinstaller_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/appinstaller"
+	"github.com/kubri/kubri/internal/testsource"
+	"github.com/kubri/kubri/source"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestBuildPackages(t *testing.T) {
+	manifest := func(name, arch string, deps ...string) string {
+		s := `<?xml version="1.0" encoding="utf-8"?>
+<Package xmlns="http://schemas.microsoft.com/appx/manifest/foundation/windows10">
+  <Identity Name="` + name + `" ProcessorArchitecture="` + arch + `" Publisher="CN=Test" Version="1.0.0.1" />
+  <Dependencies>
+    <TargetDeviceFamily Name="Windows.Desktop" MinVersion="10.0.17763.0" MaxVersionTested="10.0.19041.0" />`
+		for _, dep := range deps {
+			s += `
+    <PackageDependency Name="` + dep + `" MinVersion="14.0.30704.0" Publisher="CN=Microsoft Corporation, O=Microsoft Corporation, L=Redmond, S=Washington, C=US" />`
+		}
+		return s + `
+  </Dependencies>
+</Package>`
+	}
+
+	newZip := func(files map[string][]byte) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, b := range files {
+			w, _ := zw.Create(name)
+			w.Write(b)
+		}
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	x64 := newZip(map[string][]byte{"AppxManifest.xml": []byte(manifest("Test", "x64", "Microsoft.VCLibs.140.00.UWPDesktop"))})
+	arm64 := newZip(map[string][]byte{"AppxManifest.xml": []byte(manifest("Test", "arm64", "Microsoft.VCLibs.140.00.UWPDesktop"))})
+	bundle := newZip(map[string][]byte{
+		"AppxBundleManifest.xml": []byte(`<Bundle xmlns="http://schemas.microsoft.com/appx/2013/bundle"><Identity Name="Test" Publisher="CN=Test" Version="1.0.0.1" /></Bundle>`),
+		"Test_x64.msix":          x64,
+		"Test_arm64.msix":        arm64,
+	})
+	optional := newZip(map[string][]byte{"AppxManifest.xml": []byte(manifest("Test.Optional", "x64", "Microsoft.WindowsAppRuntime.1.5"))})
+	related := newZip(map[string][]byte{"AppxManifest.xml": []byte(manifest("Test.Related", "neutral"))})
+
+	src, downloads := testsource.NewCounting([]*source.Release{{Version: "v1.0.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "Test_x64.msix", x64)
+	src.UploadAsset(t.Context(), "v1.0.0", "Test.msixbundle", bundle)
+	src.UploadAsset(t.Context(), "v1.0.0", "Test.Optional_x64.msix", optional)
+	src.UploadAsset(t.Context(), "v1.0.0", "Test.Related.msix", related)
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+
+	c := &appinstaller.Config{
+		Source: src,
+		Target: tgt,
+		Dependencies: []*appinstaller.Package{
+			{
+				Name:                  "Microsoft.VCLibs.140.00.UWPDesktop",
+				ProcessorArchitecture: "x64",
+				URI:                   "https://example.com/VCLibs_x64.appx",
+			},
+			{
+				Name:                  "Microsoft.VCLibs.140.00.UWPDesktop",
+				ProcessorArchitecture: "arm64",
+				URI:                   "https://example.com/VCLibs_arm64.appx",
+			},
+		},
+		OptionalPackages: []string{"*.Optional_*"},
+		RelatedPackages:  []string{"*.Related.msix"},
+	}
+
+	if err := appinstaller.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Test-x64.appinstaller": `<?xml version="1.0" encoding="UTF-8"?>
+<AppInstaller xmlns="http://schemas.microsoft.com/appx/appinstaller/2017" Version="1.0.0.1" Uri="https://example.com/Test-x64.appinstaller">
+	<MainPackage Name="Test" Publisher="CN=Test" Version="1.0.0.1" ProcessorArchitecture="x64" Uri="https://example.com/v1.0.0/Test_x64.msix" />
+	<OptionalPackages>
+		<Package Name="Test.Optional" Publisher="CN=Test" Version="1.0.0.1" ProcessorArchitecture="x64" Uri="https://example.com/v1.0.0/Test.Optional_x64.msix" />
+	</OptionalPackages>
+	<RelatedPackages>
+		<Package Name="Test.Related" Publisher="CN=Test" Version="1.0.0.1" ProcessorArchitecture="neutral" Uri="https://example.com/v1.0.0/Test.Related.msix" />
+	</RelatedPackages>
+	<Dependencies>
+		<Package Name="Microsoft.VCLibs.140.00.UWPDesktop" Publisher="CN=Microsoft Corporation, O=Microsoft Corporation, L=Redmond, S=Washington, C=US" Version="14.0.30704.0" ProcessorArchitecture="x64" Uri="https://example.com/VCLibs_x64.appx" />
+	</Dependencies>
+</AppInstaller>`,
+		"Test.appinstaller": `<?xml version="1.0" encoding="UTF-8"?>
+<AppInstaller xmlns="http://schemas.microsoft.com/appx/appinstaller/2017" Version="1.0.0.1" Uri="https://example.com/Test.appinstaller">
+	<MainBundle Name="Test" Publisher="CN=Test" Version="1.0.0.1" Uri="https://example.com/v1.0.0/Test.msixbundle" />
+	<OptionalPackages>
+		<Package Name="Test.Optional" Publisher="CN=Test" Version="1.0.0.1" ProcessorArchitecture="x64" Uri="https://example.com/v1.0.0/Test.Optional_x64.msix" />
+	</OptionalPackages>
+	<RelatedPackages>
+		<Package Name="Test.Related" Publisher="CN=Test" Version="1.0.0.1" ProcessorArchitecture="neutral" Uri="https://example.com/v1.0.0/Test.Related.msix" />
+	</RelatedPackages>
+	<Dependencies>
+		<Package Name="Microsoft.VCLibs.140.00.UWPDesktop" Publisher="CN=Microsoft Corporation, O=Microsoft Corporation, L=Redmond, S=Washington, C=US" Version="14.0.30704.0" ProcessorArchitecture="x64" Uri="https://example.com/VCLibs_x64.appx" />
+		<Package Name="Microsoft.VCLibs.140.00.UWPDesktop" Publisher="CN=Microsoft Corporation, O=Microsoft Corporation, L=Redmond, S=Washington, C=US" Version="14.0.30704.0" ProcessorArchitecture="arm64" Uri="https://example.com/VCLibs_arm64.appx" />
+	</Dependencies>
+</AppInstaller>`,
+	}
+
+	for name, want := range want {
+		r, err := tgt.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(r)
+		r.Close()
+
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s:\n%s", name, diff)
+		}
+	}
+
+	for _, name := range []string{"Test.Optional.appinstaller", "Test.Optional-x64.appinstaller", "Test.Related-neutral.appinstaller"} {
+		if _, err := tgt.NewReader(t.Context(), name); err == nil {
+			t.Errorf("should not create %s", name)
+		}
+	}
+
+	for name, n := range downloads {
+		if n > 1 {
+			t.Errorf("%s: downloaded %d times", name, n)
+		}
+	}
+
+	c.Dependencies = []*appinstaller.Package{{Name: "Unknown", URI: "https://example.com/Unknown.appx"}}
+	if err := appinstaller.Build(t.Context(), c); !errors.Is(err, appinstaller.ErrInvalidDependency) {
+		t.Errorf("want %v got %v", appinstaller.ErrInvalidDependency, err)
+	}
+}
+
+func TestBuildLatest(t *testing.T) {
+	newPackage := func(arch, version string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create("AppxManifest.xml")
+		w.Write([]byte(`<Package><Identity Name="Test" ProcessorArchitecture="` + arch + `" Publisher="CN=Test" Version="` + version + `" /></Package>`))
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	src, downloads := testsource.NewCounting([]*source.Release{{Version: "v1.0.0"}, {Version: "v1.1.0"}, {Version: "v2.0.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "Test_1.0.0_x64.msix", newPackage("x64", "1.0.0.0"))
+	src.UploadAsset(t.Context(), "v1.0.0", "Test_1.0.0_arm64.msix", newPackage("arm64", "1.0.0.0"))
+	src.UploadAsset(t.Context(), "v1.1.0", "Test_1.1.0_arm64.msix", newPackage("arm64", "1.1.0.0"))
+	src.UploadAsset(t.Context(), "v2.0.0", "Test_2.0.0_x64.msix", newPackage("x64", "2.0.0.0"))
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+
+	if err := appinstaller.Build(t.Context(), &appinstaller.Config{Source: src, Target: tgt}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Test-x64.appinstaller": `<?xml version="1.0" encoding="UTF-8"?>
+<AppInstaller xmlns="http://schemas.microsoft.com/appx/appinstaller/2017" Version="2.0.0.0" Uri="https://example.com/Test-x64.appinstaller">
+	<MainPackage Name="Test" Publisher="CN=Test" Version="2.0.0.0" ProcessorArchitecture="x64" Uri="https://example.com/v2.0.0/Test_2.0.0_x64.msix" />
+</AppInstaller>`,
+		"Test-arm64.appinstaller": `<?xml version="1.0" encoding="UTF-8"?>
+<AppInstaller xmlns="http://schemas.microsoft.com/appx/appinstaller/2017" Version="1.1.0.0" Uri="https://example.com/Test-arm64.appinstaller">
+	<MainPackage Name="Test" Publisher="CN=Test" Version="1.1.0.0" ProcessorArchitecture="arm64" Uri="https://example.com/v1.1.0/Test_1.1.0_arm64.msix" />
+</AppInstaller>`,
+	}
+
+	for name, want := range want {
+		r, err := tgt.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(r)
+		r.Close()
+
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s:\n%s", name, diff)
+		}
+	}
+
+	// Older packages with the same file name, apart from the version, are not downloaded.
+	wantDownloads := map[string]int{"v2.0.0/Test_2.0.0_x64.msix": 1, "v1.1.0/Test_1.1.0_arm64.msix": 1}
+	if diff := cmp.Diff(wantDownloads, downloads); diff != "" {
+		t.Error(diff)
+	}
+}
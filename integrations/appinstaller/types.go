@@ -8,10 +8,12 @@ type AppInstaller struct {
 	URI              string `xml:"Uri,attr"`
 	MainBundle       *Package
 	MainPackage      *Package
-	Dependencies     *Packages
 	OptionalPackages *Packages
 	RelatedPackages  *Packages
+	Dependencies     *Packages
 	UpdateSettings   *UpdateSettings
+	UpdateURIs       *UpdateURIs `xml:"UpdateUris"`
+	RepairURIs       *RepairURIs `xml:"RepairUris"`
 }
 
 func (ai *AppInstaller) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
@@ -35,7 +37,7 @@ type Package struct {
 
 type UpdateSettings struct {
 	OnLaunch                  *OnLaunch
-	AutomaticBackgroundTask   Bool `xml:",omitempty"`
+	AutomaticBackgroundTask   *AutomaticBackgroundTask
 	ForceUpdateFromAnyVersion bool `xml:",omitempty"`
 }
 
@@ -45,13 +47,14 @@ type OnLaunch struct {
 	UpdateBlocksActivation   bool `xml:",attr,omitempty"`
 }
 
-type Bool bool
+type AutomaticBackgroundTask struct {
+	ShowPrompt bool `xml:",attr,omitempty"`
+}
 
-func (xb *Bool) MarshalText() ([]byte, error) {
-	return nil, nil
+type UpdateURIs struct {
+	URIs []string `xml:"UpdateUri"`
 }
 
-func (xb *Bool) UnmarshalText([]byte) error {
-	*xb = true
-	return nil
+type RepairURIs struct {
+	URIs []string `xml:"RepairUri"`
 }
@@ -47,7 +47,7 @@ func TestAcceptance(t *testing.T) {
 
 			for i, test := range tests {
 				t.Run(test.name, func(t *testing.T) {
-					config := &apt.Config{Source: src, Target: tgt, Version: test.version, PGPKey: pgpKey}
+					config := &apt.Config{Source: src, Target: tgt, Version: test.version, PGPKeys: []*pgp.PrivateKey{pgpKey}}
 					if err := apt.Build(t.Context(), config); err != nil {
 						t.Fatal(err)
 					}
@@ -5,10 +5,15 @@ import (
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/kubri/kubri/integrations/apt/deb"
@@ -17,16 +22,33 @@ import (
 	"github.com/kubri/kubri/target"
 )
 
+var ErrNoRepository = errors.New("no repository found")
+
 type Config struct {
-	Source     *source.Source
-	Version    string
-	Prerelease bool
-	Target     target.Target
-	PGPKey     *pgp.PrivateKey
-	Compress   CompressionAlgo
+	Source               *source.Source
+	Version              string
+	Prerelease           bool
+	Target               target.Target
+	PGPKeys              []*pgp.PrivateKey
+	Compress             CompressionAlgo
+	Name                 string
+	InstallScript        bool
+	Origin               string
+	Label                string
+	Description          string
+	ValidFor             time.Duration
+	NotAutomatic         bool
+	ButAutomaticUpgrades bool
+
+	// Deprecated: Use PGPKeys. PGPKey is only used if PGPKeys is empty.
+	PGPKey *pgp.PrivateKey
 }
 
 func Build(ctx context.Context, c *Config) error {
+	cfg := *c
+	cfg.PGPKeys = pgp.Keys(c.PGPKeys, c.PGPKey)
+	c = &cfg
+
 	pkgs := read(ctx, c)
 
 	version := c.Version
@@ -51,15 +73,99 @@ func Build(ctx context.Context, c *Config) error {
 	}
 	pkgs = append(p, pkgs...)
 
-	dir, err := release(c.PGPKey, c.Compress, pkgs)
+	dir, err := release(c, pkgs)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if c.Name != "" {
+		if err = writeSources(ctx, c, dir); err != nil {
+			return err
+		}
+	}
+
+	return target.CopyFS(ctx, c.Target, os.DirFS(dir))
+}
+
+// Refresh updates the date and validity of the Release files in an existing
+// repository and signs them again, without adding any new packages.
+func Refresh(ctx context.Context, c *Config) error {
+	cfg := *c
+	cfg.PGPKeys = pgp.Keys(c.PGPKeys, c.PGPKey)
+	c = &cfg
+
+	dir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return err
 	}
 	defer os.RemoveAll(dir)
 
+	suites, err := listSuites(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var n int
+	for _, suite := range suites {
+		rd, err := c.Target.NewReader(ctx, "dists/"+suite+"/Release")
+		if err != nil {
+			continue
+		}
+		var old Releases
+		err = deb.NewDecoder(rd).Decode(&old)
+		rd.Close()
+		if err != nil {
+			return err
+		}
+
+		r := newReleases(c, suite)
+		r.Architectures = old.Architectures
+		r.MD5Sum = old.MD5Sum
+		r.SHA1 = old.SHA1
+		r.SHA256 = old.SHA256
+		r.SHA512 = old.SHA512
+
+		path := filepath.Join(dir, "dists", suite)
+		if err = os.MkdirAll(path, 0o750); err != nil {
+			return err
+		}
+		if err = writeRelease(c, path, r); err != nil {
+			return err
+		}
+		n++
+	}
+
+	if n == 0 {
+		return ErrNoRepository
+	}
+
 	return target.CopyFS(ctx, c.Target, os.DirFS(dir))
 }
 
+// listSuites returns the suites in the dists directory of the repository. If
+// the target can't list directories, the suites published by Build are used.
+func listSuites(ctx context.Context, c *Config) ([]string, error) {
+	entries, err := target.ReadDir(ctx, c.Target, "dists")
+	if errors.Is(err, errors.ErrUnsupported) {
+		return []string{"stable", "edge"}, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoRepository
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var suites []string
+	for _, e := range entries {
+		if e.IsDir() {
+			suites = append(suites, e.Name())
+		}
+	}
+	return suites, nil
+}
+
 func read(ctx context.Context, c *Config) []*Package {
 	dist := "edge"
 	rd, err := c.Target.NewReader(ctx, "dists/edge/Release")
@@ -128,28 +234,56 @@ func getPackages(ctx context.Context, c *Config, releases []*source.Release) ([]
 	return pkgs, nil
 }
 
+// getPackage streams a package from the source to a temporary file, reading
+// its control archive and checksums on the way, then uploads it to the pool.
 func getPackage(ctx context.Context, c *Config, version, name string) (*Package, error) {
-	b, err := c.Source.DownloadAsset(ctx, version, name)
+	rd, err := c.Source.OpenAsset(ctx, version, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+
+	f, err := os.CreateTemp("", "*.deb")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	md5sum, sha1sum, sha256sum := md5.New(), sha1.New(), sha256.New()
+	tr := io.TeeReader(rd, io.MultiWriter(f, md5sum, sha1sum, sha256sum))
+
+	p, err := getControl(tr)
 	if err != nil {
+		return nil, &PackageError{Asset: name, Err: err}
+	}
+
+	// Read the rest of the package, which getControl stops short of.
+	if _, err = io.Copy(io.Discard, tr); err != nil {
 		return nil, err
 	}
 
-	p, err := getControl(b)
+	size, err := f.Seek(0, io.SeekCurrent)
 	if err != nil {
 		return nil, err
 	}
-	p.Size = len(b)
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	p.Size = int(size)
 	p.Filename = "pool/main/" + p.Package[0:1] + "/" + p.Package + "/" +
 		p.Package + "_" + p.Version + "_" + p.Architecture + ".deb"
-	p.MD5sum = md5.Sum(b)
-	p.SHA1 = sha1.Sum(b)
-	p.SHA256 = sha256.Sum256(b)
+	copy(p.MD5sum[:], md5sum.Sum(nil))
+	copy(p.SHA1[:], sha1sum.Sum(nil))
+	copy(p.SHA256[:], sha256sum.Sum(nil))
 
 	w, err := c.Target.NewWriter(ctx, p.Filename)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = w.Write(b); err != nil {
+	if _, err = io.Copy(w, f); err != nil {
+		w.Close()
 		return nil, err
 	}
 	if err = w.Close(); err != nil {
@@ -1,16 +1,21 @@
 package apt_test
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/kubri/kubri/integrations/apt"
+	"github.com/kubri/kubri/integrations/apt/deb"
 	"github.com/kubri/kubri/internal/test"
 	"github.com/kubri/kubri/pkg/crypto/pgp"
+	src "github.com/kubri/kubri/source"
 	source "github.com/kubri/kubri/source/file"
 	target "github.com/kubri/kubri/target/file"
 )
@@ -29,7 +34,9 @@ func TestBuild(t *testing.T) {
 		c := &apt.Config{}
 		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
 		c.Target, _ = target.New(target.Config{Path: dir})
-		c.PGPKey, _ = pgp.NewPrivateKey("test", "test@example.com")
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		next, _ := pgp.NewPrivateKey("next", "next@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key, next}
 
 		if err := apt.Build(t.Context(), c); err != nil {
 			t.Fatal(err)
@@ -51,13 +58,10 @@ func TestBuild(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		pub, _ := pgp.UnmarshalPublicKey(got["key.asc"].Data)
-		if diff := cmp.Diff(pub, c.PGPKey, test.ComparePGPKeys()); diff != "" {
+		wantKeys, _ := pgp.MarshalPublicKeys(pgp.PublicKeys(c.PGPKeys)...)
+		if diff := cmp.Diff(string(wantKeys), string(got["key.asc"].Data)); diff != "" {
 			t.Fatal(diff)
 		}
-		if !pgp.VerifyText(pub, got["dists/stable/Release"].Data, got["dists/stable/Release.gpg"].Data) {
-			t.Error("Release should pass pgp verification")
-		}
 
 		data, sig, err := pgp.Split(got["dists/stable/InRelease"].Data)
 		if err != nil {
@@ -66,8 +70,207 @@ func TestBuild(t *testing.T) {
 		if diff := cmp.Diff(want["dists/stable/Release"].Data, data); diff != "" {
 			t.Error(diff)
 		}
-		if !pgp.VerifyText(pub, data, sig) {
-			t.Error("InRelease should pass pgp verification")
+
+		// Each key must be able to verify the signatures on its own.
+		for _, pub := range pgp.PublicKeys(c.PGPKeys) {
+			if !pgp.VerifyText(pub, got["dists/stable/Release"].Data, got["dists/stable/Release.gpg"].Data) {
+				t.Error("Release should pass pgp verification")
+			}
+			if !pgp.VerifyText(pub, data, sig) {
+				t.Error("InRelease should pass pgp verification")
+			}
+		}
+	})
+
+	t.Run("DeprecatedPGPKey", func(t *testing.T) {
+		dir := t.TempDir()
+
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		c := &apt.Config{PGPKey: key}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := apt.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+		if !pgp.VerifyText(pgp.Public(key), got["dists/stable/Release"].Data, got["dists/stable/Release.gpg"].Data) {
+			t.Error("Release should pass pgp verification")
+		}
+	})
+
+	t.Run("Sources", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apt.Config{Name: "kubri-test", InstallScript: true}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir, URL: "https://example.com/apt"})
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key}
+
+		if err := apt.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		wantSources := `Types: deb
+URIs: https://example.com/apt/
+Suites: stable
+Components: main
+Signed-By: /usr/share/keyrings/kubri-test-archive-keyring.gpg
+`
+		if diff := cmp.Diff(wantSources, string(got["kubri-test.sources"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		wantList := "deb [signed-by=/usr/share/keyrings/kubri-test-archive-keyring.gpg] https://example.com/apt/ stable main\n"
+		if diff := cmp.Diff(wantList, string(got["kubri-test.list"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		wantScript := `#!/bin/sh
+set -e
+
+SUDO=
+if [ "$(id -u)" -ne 0 ]; then
+  SUDO=sudo
+fi
+
+SOURCES=kubri-test.sources
+
+$SUDO curl -fsSL https://example.com/apt/kubri-test-archive-keyring.gpg -o /usr/share/keyrings/kubri-test-archive-keyring.gpg
+$SUDO curl -fsSL https://example.com/apt/$SOURCES -o /etc/apt/sources.list.d/kubri-test.sources
+$SUDO apt-get update
+`
+		if diff := cmp.Diff(wantScript, string(got["install.sh"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		wantKeyring, _ := pgp.MarshalKeyring(pgp.Public(key))
+		if diff := cmp.Diff(wantKeyring, got["kubri-test-archive-keyring.gpg"].Data); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("SourcesEdge", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apt.Config{Name: "kubri-test", InstallScript: true, Prerelease: true}
+		c.Source = prereleaseSource(t)
+		c.Target, _ = target.New(target.Config{Path: dir, URL: "https://example.com/apt"})
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key}
+
+		if err := apt.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		wantSources := `Types: deb
+URIs: https://example.com/apt/
+Suites: edge
+Components: main
+Signed-By: /usr/share/keyrings/kubri-test-archive-keyring.gpg
+`
+		if diff := cmp.Diff(wantSources, string(got["kubri-test-edge.sources"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		wantList := "deb [signed-by=/usr/share/keyrings/kubri-test-archive-keyring.gpg] https://example.com/apt/ edge main\n"
+		if diff := cmp.Diff(wantList, string(got["kubri-test-edge.list"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		wantScript := `#!/bin/sh
+set -e
+
+SUDO=
+if [ "$(id -u)" -ne 0 ]; then
+  SUDO=sudo
+fi
+
+SOURCES=kubri-test.sources
+if [ "$1" = edge ]; then
+  SOURCES=kubri-test-edge.sources
+fi
+
+$SUDO curl -fsSL https://example.com/apt/kubri-test-archive-keyring.gpg -o /usr/share/keyrings/kubri-test-archive-keyring.gpg
+$SUDO curl -fsSL https://example.com/apt/$SOURCES -o /etc/apt/sources.list.d/kubri-test.sources
+$SUDO apt-get update
+`
+		if diff := cmp.Diff(wantScript, string(got["install.sh"].Data)); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("SourcesUnsigned", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apt.Config{Name: "kubri-test", InstallScript: true}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir, URL: "https://example.com/apt"})
+
+		if err := apt.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+		for _, name := range []string{"kubri-test.sources", "kubri-test.list", "install.sh"} {
+			if _, ok := got[name]; ok {
+				t.Errorf("%s should not exist", name)
+			}
+		}
+	})
+
+	t.Run("ReleaseOptions", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &apt.Config{
+			Prerelease:           true,
+			Origin:               "Kubri",
+			Label:                "Kubri Test",
+			Description:          "Test repository",
+			ValidFor:             7 * 24 * time.Hour,
+			NotAutomatic:         true,
+			ButAutomaticUpgrades: true,
+		}
+		c.Source = prereleaseSource(t)
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := apt.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		for suite, pinned := range map[string]bool{"stable": false, "edge": true} {
+			var r apt.Releases
+			if err := deb.Unmarshal(got["dists/"+suite+"/Release"].Data, &r); err != nil {
+				t.Fatal(err)
+			}
+
+			want := apt.Releases{
+				Origin:      "Kubri",
+				Label:       "Kubri Test",
+				Suite:       suite,
+				Codename:    suite,
+				Date:        time.Date(2023, 11, 19, 23, 37, 12, 0, time.UTC),
+				ValidUntil:  time.Date(2023, 11, 26, 23, 37, 12, 0, time.UTC),
+				Components:  "main",
+				Description: "Test repository",
+			}
+			if pinned {
+				want.NotAutomatic = "yes"
+				want.ButAutomaticUpgrades = "yes"
+			}
+
+			opt := cmpopts.IgnoreFields(apt.Releases{}, "Architectures", "MD5Sum", "SHA1", "SHA256")
+			if diff := cmp.Diff(want, r, opt); diff != "" {
+				t.Errorf("%s:\n%s", suite, diff)
+			}
 		}
 	})
 
@@ -103,3 +306,135 @@ func TestBuild(t *testing.T) {
 		}
 	})
 }
+
+// prereleaseSource returns a source with a stable package and a prerelease
+// package, so an edge suite is published.
+func prereleaseSource(t *testing.T) *src.Source {
+	t.Helper()
+
+	dir := t.TempDir()
+	b, err := os.ReadFile("../../testdata/v1.0.0/kubri-test_1.0.0_amd64.deb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	control := map[string]string{"./control": "Package: kubri-test\nVersion: 1.1.0-beta\nArchitecture: amd64\n"}
+	files := map[string][]byte{
+		"v1.0.0/kubri-test_1.0.0_amd64.deb":           b,
+		"v1.1.0-beta/kubri-test_1.1.0-beta_amd64.deb": makeDeb(t, debianBinary, arMember{"control.tar", makeTar(t, "", control)}),
+	}
+	for name, data := range files {
+		if err = os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := source.New(source.Config{Path: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRefresh(t *testing.T) {
+	dir := t.TempDir()
+
+	c := &apt.Config{ValidFor: 24 * time.Hour}
+	c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+	c.Target, _ = target.New(target.Config{Path: dir})
+	key, _ := pgp.NewPrivateKey("test", "test@example.com")
+	c.PGPKeys = []*pgp.PrivateKey{key}
+
+	if err := apt.Refresh(t.Context(), c); !errors.Is(err, apt.ErrNoRepository) {
+		t.Fatalf("should return %q got %q", apt.ErrNoRepository, err)
+	}
+
+	apt.SetTime(time.Date(2023, 11, 19, 23, 37, 12, 0, time.UTC))
+	if err := apt.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+	before := test.ReadFS(os.DirFS(dir))
+
+	apt.SetTime(time.Date(2023, 11, 20, 12, 0, 0, 0, time.UTC))
+	defer apt.SetTime(time.Date(2023, 11, 19, 23, 37, 12, 0, time.UTC))
+	if err := apt.Refresh(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+	after := test.ReadFS(os.DirFS(dir))
+
+	opt := cmp.Options{
+		test.IgnoreFSMeta(),
+		test.IgnoreKeys("dists/stable/Release", "dists/stable/Release.gpg", "dists/stable/InRelease"),
+	}
+	if diff := cmp.Diff(before, after, opt); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var want, got apt.Releases
+	_ = deb.Unmarshal(before["dists/stable/Release"].Data, &want)
+	if err := deb.Unmarshal(after["dists/stable/Release"].Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want.Date = time.Date(2023, 11, 20, 12, 0, 0, 0, time.UTC)
+	want.ValidUntil = time.Date(2023, 11, 21, 12, 0, 0, 0, time.UTC)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+
+	pub := pgp.Public(key)
+	release := after["dists/stable/Release"].Data
+	if !pgp.VerifyText(pub, release, after["dists/stable/Release.gpg"].Data) {
+		t.Error("Release should pass pgp verification")
+	}
+	data, sig, err := pgp.Split(after["dists/stable/InRelease"].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(release, data); diff != "" {
+		t.Error(diff)
+	}
+	if !pgp.VerifyText(pub, data, sig) {
+		t.Error("InRelease should pass pgp verification")
+	}
+}
+
+func TestRefreshSuites(t *testing.T) {
+	apt.SetTime(time.Date(2023, 11, 19, 23, 37, 12, 0, time.UTC))
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "dists", "custom"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	release := []byte("Suite: custom\nArchitectures: amd64\n")
+	if err := os.WriteFile(filepath.Join(dir, "dists", "custom", "Release"), release, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &apt.Config{}
+	c.Target, _ = target.New(target.Config{Path: dir})
+
+	if err := apt.Refresh(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "dists", "custom", "InRelease"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got apt.Releases
+	if err = deb.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := apt.Releases{
+		Suite:         "custom",
+		Codename:      "custom",
+		Date:          time.Date(2023, 11, 19, 23, 37, 12, 0, time.UTC),
+		Architectures: "amd64",
+		Components:    "main",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -2,7 +2,10 @@ package apt
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
+	"encoding/hex"
+	"errors"
 	"io"
 	"path"
 	"strings"
@@ -12,45 +15,150 @@ import (
 	"github.com/kubri/kubri/integrations/apt/deb"
 )
 
-func getControl(b []byte) (*Package, error) {
-	r := ar.NewReader(bytes.NewReader(b))
+var (
+	ErrInvalidPackage = errors.New("not a debian package")
+	ErrMissingControl = errors.New("missing control file")
+)
+
+// PackageError records an error reading a package along with the asset it came from.
+type PackageError struct {
+	Asset string
+	Err   error
+}
+
+func (e *PackageError) Error() string {
+	return "invalid package " + e.Asset + ": " + e.Err.Error()
+}
+
+func (e *PackageError) Unwrap() error {
+	return e.Err
+}
+
+// getControl reads the control archive from a package. The package is streamed
+// and reading stops as soon as the control archive has been processed, so the
+// data archive is never read.
+func getControl(r io.Reader) (*Package, error) {
+	ra := ar.NewReader(r)
+
+	h, err := ra.Next()
+	if err != nil {
+		return nil, ErrInvalidPackage
+	}
+	if memberName(h) != "debian-binary" {
+		return nil, ErrInvalidPackage
+	}
+	if b, err := io.ReadAll(ra); err != nil || !bytes.HasPrefix(b, []byte("2.")) {
+		return nil, ErrInvalidPackage
+	}
 
 	for {
-		h, err := r.Next()
+		h, err := ra.Next()
+		if err == io.EOF {
+			return nil, ErrMissingControl
+		}
 		if err != nil {
 			return nil, err
 		}
-		if !strings.HasPrefix(h.Name, "control.tar") {
+
+		name := memberName(h)
+		if name != "control.tar" && !strings.HasPrefix(name, "control.tar.") {
 			continue
 		}
 
-		r, err := decompress(path.Ext(h.Name))(r)
+		rd, err := decompress(path.Ext(name))(ra)
 		if err != nil {
 			return nil, err
 		}
-		defer r.Close()
+		defer rd.Close()
 
-		tr := tar.NewReader(r)
+		return readControl(tar.NewReader(rd))
+	}
+}
 
-		for {
-			h, err := tr.Next()
-			if err != nil {
-				return nil, err
-			}
-			if path.Base(h.Name) != "control" {
-				continue
-			}
+func readControl(tr *tar.Reader) (*Package, error) {
+	var (
+		p         *Package
+		sums      map[string][16]byte
+		conffiles []string
+	)
 
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch path.Clean(h.Name) {
+		case "control":
 			b, err := io.ReadAll(tr)
 			if err != nil {
 				return nil, err
 			}
-			p := &Package{}
+			p = &Package{}
 			if err = deb.Unmarshal(b, p); err != nil {
 				return nil, err
 			}
+		case "md5sums":
+			if sums, err = readMD5Sums(tr); err != nil {
+				return nil, err
+			}
+		case "conffiles":
+			if conffiles, err = readLines(tr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if p == nil {
+		return nil, ErrMissingControl
+	}
+
+	p.MD5Sums = sums
+	p.Conffiles = conffiles
+
+	return p, nil
+}
 
-			return p, nil
+// readMD5Sums reads the checksums of the files in a package. Malformed lines
+// are skipped, as dpkg only uses them for verification.
+func readMD5Sums(r io.Reader) (map[string][16]byte, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string][16]byte, len(lines))
+	for _, line := range lines {
+		sum, name, ok := strings.Cut(line, " ")
+		var b [16]byte
+		if !ok || len(sum) != hex.EncodedLen(len(b)) {
+			continue
+		}
+		if _, err = hex.Decode(b[:], []byte(sum)); err != nil {
+			continue
+		}
+		sums[strings.TrimLeft(name, " ")] = b
+	}
+
+	return sums, nil
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			lines = append(lines, line)
 		}
 	}
+	return lines, s.Err()
+}
+
+// memberName returns the name of an ar member, without the trailing slash
+// added by GNU ar.
+func memberName(h *ar.Header) string {
+	return strings.TrimSuffix(strings.TrimSpace(h.Name), "/")
 }
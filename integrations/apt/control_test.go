@@ -0,0 +1,160 @@
+package apt_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blakesmith/ar"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/apt"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestGetControl(t *testing.T) {
+	files := map[string]string{
+		"./control":   "Package: test\nVersion: 1.0.0\nArchitecture: amd64\nMulti-Arch: foreign\n",
+		"./md5sums":   "d41d8cd98f00b204e9800998ecf8427e  usr/bin/test\ninvalid\nzz  usr/bin/invalid\n",
+		"./conffiles": "/etc/test.conf\n/etc/test.d/default.conf\n",
+	}
+
+	want := &apt.Package{
+		Package:      "test",
+		Version:      "1.0.0",
+		Architecture: "amd64",
+		MultiArch:    "foreign",
+		MD5Sums:      map[string][16]byte{"usr/bin/test": md5.Sum(nil)},
+		Conffiles:    []string{"/etc/test.conf", "/etc/test.d/default.conf"},
+	}
+
+	for _, ext := range []string{"", ".gz", ".xz", ".zst"} {
+		b := makeDeb(t, debianBinary, arMember{"control.tar" + ext, makeTar(t, ext, files)})
+
+		got, err := apt.GetControl(bytes.NewReader(b))
+		if err != nil {
+			t.Errorf("control.tar%s: %s", ext, err)
+			continue
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("control.tar%s:\n%s", ext, diff)
+		}
+	}
+}
+
+func TestGetControlErrors(t *testing.T) {
+	files := map[string]string{"./control": "Package: test\n"}
+
+	tests := []struct {
+		msg  string
+		in   []byte
+		want error
+	}{
+		{
+			msg:  "not an ar archive",
+			in:   []byte("foo"),
+			want: apt.ErrInvalidPackage,
+		},
+		{
+			msg:  "missing debian-binary",
+			in:   makeDeb(t, arMember{"control.tar", makeTar(t, "", files)}),
+			want: apt.ErrInvalidPackage,
+		},
+		{
+			msg:  "missing control archive",
+			in:   makeDeb(t, debianBinary, arMember{"data.tar", nil}),
+			want: apt.ErrMissingControl,
+		},
+		{
+			msg:  "missing control file",
+			in:   makeDeb(t, debianBinary, arMember{"control.tar", makeTar(t, "", map[string]string{"./md5sums": ""})}),
+			want: apt.ErrMissingControl,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := apt.GetControl(bytes.NewReader(test.in))
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s should return %q got %q", test.msg, test.want, err)
+		}
+	}
+}
+
+func TestBuildInvalidPackage(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "v1.0.0"), 0o750)
+	os.WriteFile(filepath.Join(src, "v1.0.0", "broken.deb"), []byte("foo"), 0o600)
+
+	c := &apt.Config{}
+	c.Source, _ = source.New(source.Config{Path: src})
+	c.Target, _ = target.New(target.Config{Path: t.TempDir()})
+
+	err := apt.Build(t.Context(), c)
+
+	var pkgErr *apt.PackageError
+	if !errors.As(err, &pkgErr) || pkgErr.Asset != "broken.deb" || !errors.Is(err, apt.ErrInvalidPackage) {
+		t.Fatalf("should return package error for broken.deb got %q", err)
+	}
+	if want := "invalid package broken.deb: not a debian package"; err.Error() != want {
+		t.Fatalf("should return %q got %q", want, err)
+	}
+}
+
+type arMember struct {
+	name string
+	data []byte
+}
+
+var debianBinary = arMember{"debian-binary", []byte("2.0\n")} //nolint:gochecknoglobals
+
+func makeDeb(t *testing.T, members ...arMember) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := ar.NewWriter(&buf)
+	if err := w.WriteGlobalHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		if err := w.WriteHeader(&ar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func makeTar(t *testing.T, ext string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	cw, err := apt.Compress(ext)(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(cw)
+	for name, data := range files {
+		if err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
@@ -162,10 +162,19 @@ func newSliceDecoder(typ reflect.Type) (decoder, error) {
 
 func newStructDecoder(typ reflect.Type) (decoder, error) {
 	decoders := map[string]decoder{}
+	inline := -1
 
 	for i := range typ.NumField() {
 		field := typ.Field(i)
 
+		if isInline(field) {
+			if field.Type != fieldsType {
+				return nil, fmt.Errorf("unsupported inline type: %s", field.Type)
+			}
+			inline = i
+			continue
+		}
+
 		name := getFieldName(field)
 		if name == "" {
 			continue
@@ -197,10 +206,19 @@ func newStructDecoder(typ reflect.Type) (decoder, error) {
 			if i := bytes.IndexByte(b, '\n'); i != -1 { //nolint:modernize // more performant
 				return fmt.Errorf("invalid line: %q", b[:i])
 			}
-			if dec := decoders[btoa(trim(b)[:len(b)-1])]; dec != nil {
+			key := trim(b[:len(b)-1])
+			if dec := decoders[btoa(key)]; dec != nil {
 				if err := dec(r, v); err != nil {
 					return err
 				}
+			} else if inline != -1 {
+				name := string(key) // Copy as reading the value may overwrite the buffer.
+				b, err := readlines(r)
+				if err != nil {
+					return err
+				}
+				f := v.Field(inline)
+				f.Set(reflect.Append(f, reflect.ValueOf(Field{Name: name, Value: string(b)})))
 			} else {
 				_, _ = readlines(r) // Discard value for unknown key.
 			}
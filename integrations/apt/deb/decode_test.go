@@ -142,6 +142,22 @@ String: test
 				String: "test",
 			},
 		},
+		{
+			msg: "inline fields",
+			in: `Foo: bar
+String: test
+X-Baz:
+ foo
+ bar
+`,
+			want: struct {
+				String string
+				Extra  []deb.Field `deb:",inline"`
+			}{
+				String: "test",
+				Extra:  []deb.Field{{Name: "Foo", Value: "bar"}, {Name: "X-Baz", Value: "\nfoo\nbar"}},
+			},
+		},
 		{
 			msg: "non-pointer unmarshaler",
 			in: `Marshaler: test
@@ -216,6 +232,13 @@ func TestDecodeErrors(t *testing.T) {
 			value: &[]struct{ V complex128 }{},
 			err:   "unsupported type: complex128",
 		},
+		{
+			msg: "unsupported inline type",
+			value: &struct {
+				V map[string]string `deb:",inline"`
+			}{},
+			err: "unsupported inline type: map[string]string",
+		},
 		{
 			msg:    "invalid date",
 			reader: strings.NewReader("Date: test\n"),
@@ -167,6 +167,25 @@ func newStructEncoder(typ reflect.Type) (encoder, error) {
 	for i := range typ.NumField() {
 		field := typ.Field(i)
 
+		if isInline(field) {
+			if field.Type != fieldsType {
+				return nil, fmt.Errorf("unsupported inline type: %s", field.Type)
+			}
+			encoders = append(encoders, func(buf *bytes.Buffer, w io.Writer, v reflect.Value) error {
+				for _, f := range v.Field(i).Interface().([]Field) { //nolint:forcetypeassert
+					buf.Reset()
+					if err := encodeText(buf, atob(f.Value)); err != nil {
+						return err
+					}
+					if err := writeField(w, atob(f.Name), buf); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			continue
+		}
+
 		n := getFieldName(field)
 		if n == "" {
 			continue
@@ -181,29 +200,7 @@ func newStructEncoder(typ reflect.Type) (encoder, error) {
 			if err := enc(buf, v.Field(i)); err != nil {
 				return err
 			}
-			if buf.Len() == 0 {
-				return nil
-			}
-			if _, err = w.Write(name); err != nil {
-				return err
-			}
-			if _, err = w.Write(colon); err != nil {
-				return err
-			}
-
-			// Only write space after colon if content doesn't start with line break.
-			if c, _ := buf.ReadByte(); c != '\n' {
-				if _, err = w.Write(space); err != nil {
-					return err
-				}
-			}
-			_ = buf.UnreadByte()
-
-			if _, err = io.Copy(w, buf); err != nil {
-				return err
-			}
-			_, err = w.Write(nl)
-			return err
+			return writeField(w, name, buf)
 		})
 	}
 
@@ -220,6 +217,32 @@ func newStructEncoder(typ reflect.Type) (encoder, error) {
 	}, nil
 }
 
+func writeField(w io.Writer, name []byte, buf *bytes.Buffer) error {
+	if buf.Len() == 0 {
+		return nil
+	}
+	if _, err := w.Write(name); err != nil {
+		return err
+	}
+	if _, err := w.Write(colon); err != nil {
+		return err
+	}
+
+	// Only write space after colon if content doesn't start with line break.
+	if c, _ := buf.ReadByte(); c != '\n' {
+		if _, err := w.Write(space); err != nil {
+			return err
+		}
+	}
+	_ = buf.UnreadByte()
+
+	if _, err := io.Copy(w, buf); err != nil {
+		return err
+	}
+	_, err := w.Write(nl)
+	return err
+}
+
 func newDateEncoder(reflect.Type) (encoder, error) {
 	return func(w io.Writer, v reflect.Value) error {
 		t := v.Interface().(time.Time) //nolint:forcetypeassert
@@ -150,6 +150,17 @@ Marshaler:
 			}{unexported: "foo", Ignored: "bar", Test: "baz"},
 			want: "Test: baz\n",
 		},
+		{
+			msg: "inline fields",
+			in: struct {
+				String string
+				Extra  []deb.Field `deb:",inline"`
+			}{
+				String: "test",
+				Extra:  []deb.Field{{Name: "Foo", Value: "bar"}, {Name: "Empty"}, {Name: "X-Baz", Value: "\nfoo\nbar"}},
+			},
+			want: "String: test\nFoo: bar\nX-Baz:\n foo\n bar\n",
+		},
 		{
 			msg: "named fields",
 			in: struct {
@@ -187,6 +198,13 @@ func TestEncodeErrors(t *testing.T) {
 			value: &[]struct{ V complex128 }{},
 			err:   "unsupported type: complex128",
 		},
+		{
+			msg: "unsupported inline type",
+			value: struct {
+				V map[string]string `deb:",inline"`
+			}{},
+			err: "unsupported inline type: map[string]string",
+		},
 		{
 			msg:   "marshaler error",
 			value: &[]struct{ V *errMarshaler }{{V: &errMarshaler{errors.New("marshal error")}}},
@@ -17,9 +17,18 @@ var (
 	space = []byte(" ")
 	nl    = []byte("\n")
 
-	dateType = reflect.TypeFor[time.Time]()
+	dateType   = reflect.TypeFor[time.Time]()
+	fieldsType = reflect.TypeFor[[]Field]()
 )
 
+// Field is a single control field. A struct field of type []Field tagged with
+// `deb:",inline"` collects all fields which have no matching struct field, in
+// the order they appear.
+type Field struct {
+	Name  string
+	Value string
+}
+
 func getFieldName(field reflect.StructField) string {
 	if !field.IsExported() {
 		return ""
@@ -36,6 +45,19 @@ func getFieldName(field reflect.StructField) string {
 	return name
 }
 
+func isInline(field reflect.StructField) bool {
+	if !field.IsExported() {
+		return false
+	}
+	_, opts, _ := strings.Cut(field.Tag.Get("deb"), ",")
+	for opt := range strings.SplitSeq(opts, ",") {
+		if opt == "inline" {
+			return true
+		}
+	}
+	return false
+}
+
 func trim(s []byte) []byte {
 	i := 0
 	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
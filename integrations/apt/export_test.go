@@ -12,3 +12,5 @@ var (
 	Decompress            = decompress
 	CompressionExtensions = compressionExtensions
 )
+
+var GetControl = getControl
@@ -18,7 +18,10 @@ import (
 	"github.com/kubri/kubri/pkg/crypto/pgp"
 )
 
-func release(key *pgp.PrivateKey, algos CompressionAlgo, p []*Package) (string, error) {
+// component is the only component published in the repository.
+const component = "main"
+
+func release(c *Config, p []*Package) (string, error) {
 	dir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return "", err
@@ -30,19 +33,19 @@ func release(key *pgp.PrivateKey, algos CompressionAlgo, p []*Package) (string,
 			stable = append(stable, pkg)
 		}
 	}
-	if err = releaseSuite(key, algos, stable, "stable", dir); err != nil {
+	if err = releaseSuite(c, stable, "stable", dir); err != nil {
 		return "", err
 	}
 
 	// If not all packages are stable publish a separate `edge` dist.
 	if len(p) > len(stable) {
-		if err = releaseSuite(key, algos, p, "edge", dir); err != nil {
+		if err = releaseSuite(c, p, "edge", dir); err != nil {
 			return "", err
 		}
 	}
 
-	if key != nil {
-		b, err := pgp.MarshalPublicKey(pgp.Public(key))
+	if len(c.PGPKeys) > 0 {
+		b, err := pgp.MarshalPublicKeys(pgp.PublicKeys(c.PGPKeys)...)
 		if err != nil {
 			return "", err
 		}
@@ -54,14 +57,10 @@ func release(key *pgp.PrivateKey, algos CompressionAlgo, p []*Package) (string,
 	return dir, nil
 }
 
-func releaseSuite(key *pgp.PrivateKey, algos CompressionAlgo, p []*Package, suite, root string) error {
+func releaseSuite(c *Config, p []*Package, suite, root string) error {
 	dir := filepath.Join(root, "dists", suite)
 
-	r := Releases{
-		Suite:      suite,
-		Codename:   suite,
-		Components: "main",
-	}
+	r := newReleases(c, suite)
 
 	byArch := map[string][]*Package{}
 	for _, pkg := range p {
@@ -71,7 +70,7 @@ func releaseSuite(key *pgp.PrivateKey, algos CompressionAlgo, p []*Package, suit
 	{
 		var as []string
 		for a, pkgs := range byArch {
-			if err := releaseArch(algos, pkgs, suite, a, dir); err != nil {
+			if err := releaseArch(c, pkgs, suite, a, dir); err != nil {
 				return err
 			}
 			as = append(as, a)
@@ -101,25 +100,51 @@ func releaseSuite(key *pgp.PrivateKey, algos CompressionAlgo, p []*Package, suit
 		return err
 	}
 
-	return writeRelease(dir, r, key)
+	return writeRelease(c, dir, r)
 }
 
-func releaseArch(algos CompressionAlgo, p []*Package, suite, arch, root string) error {
+func newReleases(c *Config, suite string) Releases {
+	r := Releases{
+		Origin:      c.Origin,
+		Label:       c.Label,
+		Suite:       suite,
+		Codename:    suite,
+		Components:  component,
+		Description: c.Description,
+	}
+
+	// Only pin the edge suite, so prereleases are not installed unless requested.
+	if suite == "edge" {
+		if c.NotAutomatic {
+			r.NotAutomatic = "yes"
+		}
+		if c.ButAutomaticUpgrades {
+			r.ButAutomaticUpgrades = "yes"
+		}
+	}
+
+	return r
+}
+
+func releaseArch(c *Config, p []*Package, suite, arch, root string) error {
 	r := Release{
+		Origin:       c.Origin,
+		Label:        c.Label,
 		Archive:      suite,
 		Suite:        suite,
-		Component:    "main",
+		Component:    component,
 		Architecture: arch,
+		Description:  c.Description,
 	}
 
-	dir := filepath.Join(root, "main", "binary-"+arch)
+	dir := filepath.Join(root, component, "binary-"+arch)
 	if err := os.MkdirAll(dir, 0o750); err != nil {
 		return err
 	}
 	if err := writeFile(filepath.Join(dir, "Release"), r); err != nil {
 		return err
 	}
-	return writePackages(filepath.Join(dir, "Packages"), p, algos)
+	return writePackages(filepath.Join(dir, "Packages"), p, c.Compress)
 }
 
 func writeFile(path string, v any) error {
@@ -171,8 +196,12 @@ func writePackages(path string, v []*Package, algos CompressionAlgo) error {
 
 var timeNow = time.Now //nolint:gochecknoglobals
 
-func writeRelease(dir string, r Releases, key *pgp.PrivateKey) error {
+func writeRelease(c *Config, dir string, r Releases) error {
 	r.Date = timeNow().UTC()
+	r.ValidUntil = time.Time{}
+	if c.ValidFor > 0 {
+		r.ValidUntil = r.Date.Add(c.ValidFor)
+	}
 
 	b, err := deb.Marshal(r)
 	if err != nil {
@@ -183,15 +212,15 @@ func writeRelease(dir string, r Releases, key *pgp.PrivateKey) error {
 		return err
 	}
 
-	if key != nil {
-		sig, err := pgp.SignText(key, b)
+	if len(c.PGPKeys) > 0 {
+		sig, err := pgp.MultiSignText(c.PGPKeys, b)
 		if err != nil {
 			return err
 		}
 		if err = os.WriteFile(filepath.Join(dir, "Release.gpg"), sig, 0o600); err != nil {
 			return err
 		}
-		b, err = pgp.ClearSign(key, b)
+		b, err = pgp.MultiClearSign(c.PGPKeys, b)
 		if err != nil {
 			return err
 		}
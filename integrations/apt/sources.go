@@ -0,0 +1,85 @@
+package apt
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubri/kubri/pkg/crypto/pgp"
+)
+
+// writeSources writes a keyring along with sources files which reference it, so
+// users can add the repository without having to dearmor the key themselves.
+//
+// A sources file is written for each suite, named <name>.sources for stable and
+// <name>-<suite>.sources for others. Unsigned repositories get no sources files,
+// as they would have to be marked as trusted.
+func writeSources(ctx context.Context, c *Config, dir string) error {
+	if len(c.PGPKeys) == 0 {
+		log.Print("Skipping APT sources files for unsigned repository")
+		return nil
+	}
+
+	url, err := c.Target.URL(ctx, "")
+	if err != nil {
+		return err
+	}
+	url = strings.TrimSuffix(url, "/") + "/"
+
+	keyring := c.Name + "-archive-keyring.gpg"
+	keyringPath := "/usr/share/keyrings/" + keyring
+
+	b, err := pgp.MarshalKeyring(pgp.PublicKeys(c.PGPKeys)...)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(dir, keyring), b, 0o600); err != nil {
+		return err
+	}
+
+	suites := []string{"stable"}
+	if _, err = os.Stat(filepath.Join(dir, "dists", "edge")); err == nil {
+		suites = append(suites, "edge")
+	}
+
+	for _, suite := range suites {
+		name := sourcesName(c.Name, suite)
+
+		sources := "Types: deb\nURIs: " + url + "\nSuites: " + suite + "\nComponents: " + component +
+			"\nSigned-By: " + keyringPath + "\n"
+		if err = os.WriteFile(filepath.Join(dir, name+".sources"), []byte(sources), 0o600); err != nil {
+			return err
+		}
+
+		list := "deb [signed-by=" + keyringPath + "] " + url + " " + suite + " " + component + "\n"
+		if err = os.WriteFile(filepath.Join(dir, name+".list"), []byte(list), 0o600); err != nil {
+			return err
+		}
+	}
+
+	if !c.InstallScript {
+		return nil
+	}
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\nset -e\n\n")
+	script.WriteString("SUDO=\nif [ \"$(id -u)\" -ne 0 ]; then\n  SUDO=sudo\nfi\n\n")
+	script.WriteString("SOURCES=" + c.Name + ".sources\n")
+	for _, suite := range suites[1:] {
+		script.WriteString("if [ \"$1\" = " + suite + " ]; then\n  SOURCES=" + sourcesName(c.Name, suite) + ".sources\nfi\n")
+	}
+	script.WriteString("\n$SUDO curl -fsSL " + url + keyring + " -o " + keyringPath + "\n")
+	script.WriteString("$SUDO curl -fsSL " + url + "$SOURCES -o /etc/apt/sources.list.d/" + c.Name + ".sources\n")
+	script.WriteString("$SUDO apt-get update\n")
+
+	return os.WriteFile(filepath.Join(dir, "install.sh"), []byte(script.String()), 0o600)
+}
+
+func sourcesName(name, suite string) string {
+	if suite == "stable" {
+		return name
+	}
+	return name + "-" + suite
+}
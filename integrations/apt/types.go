@@ -1,6 +1,10 @@
 package apt
 
-import "time"
+import (
+	"time"
+
+	"github.com/kubri/kubri/integrations/apt/deb"
+)
 
 type Release struct {
 	Origin       string
@@ -13,32 +17,45 @@ type Release struct {
 }
 
 type Releases struct {
-	Origin        string
-	Label         string
-	Suite         string
-	Codename      string
-	Date          time.Time
-	Architectures string
-	Components    string
-	Description   string
-	MD5Sum        string
-	SHA1          string
-	SHA256        string
-	SHA512        string
+	Origin               string
+	Label                string
+	Suite                string
+	Codename             string
+	Date                 time.Time
+	ValidUntil           time.Time `deb:"Valid-Until"`
+	NotAutomatic         string
+	ButAutomaticUpgrades string
+	Architectures        string
+	Components           string
+	Description          string
+	MD5Sum               string
+	SHA1                 string
+	SHA256               string
+	SHA512               string
 }
 
+// Package is a binary package paragraph in a Packages index. Any other fields
+// found in the package's control file are preserved in Extra, which is written
+// after the known fields.
 type Package struct {
 	Package       string
+	Source        string
 	Version       string
 	Architecture  string
+	Essential     string
+	MultiArch     string `deb:"Multi-Arch"`
 	Maintainer    string
 	InstalledSize int    `deb:"Installed-Size"`
 	PreDepends    string `deb:"Pre-Depends"`
 	Depends       string
 	Recommends    string
+	Suggests      string
+	Enhances      string
+	Breaks        string
 	Conflicts     string
 	Replaces      string
 	Provides      string
+	BuiltUsing    string `deb:"Built-Using"`
 	Priority      string
 	Section       string
 	Filename      string
@@ -48,4 +65,12 @@ type Package struct {
 	SHA256        [32]byte
 	Homepage      string
 	Description   string
+	Tag           string
+	Extra         []deb.Field `deb:",inline"`
+
+	// MD5Sums and Conffiles are read from the control archive of a .deb file.
+	// They aren't part of the Packages index, so are not set for packages read
+	// from an existing repository.
+	MD5Sums   map[string][16]byte `deb:"-"`
+	Conffiles []string            `deb:"-"`
 }
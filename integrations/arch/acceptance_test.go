@@ -59,7 +59,7 @@ func TestAcceptance(t *testing.T) {
 						Source:   src,
 						Version:  test.version,
 						Target:   tgt,
-						PGPKey:   pgpKey,
+						PGPKeys:  []*pgp.PrivateKey{pgpKey},
 					}
 					if err := arch.Build(t.Context(), config); err != nil {
 						t.Fatal(err)
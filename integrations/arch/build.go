@@ -2,7 +2,11 @@ package arch
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/kubri/kubri/pkg/crypto/pgp"
@@ -11,24 +15,37 @@ import (
 )
 
 type Config struct {
-	RepoName   string
-	Source     *source.Source
-	Version    string
-	Prerelease bool
-	Target     target.Target
-	PGPKey     *pgp.PrivateKey
+	RepoName     string
+	Source       *source.Source
+	Version      string
+	Prerelease   bool
+	Target       target.Target
+	PGPKeys      []*pgp.PrivateKey
+	KeepVersions int
+	DebugRepo    bool
+
+	// Deprecated: Use PGPKeys. PGPKey is only used if PGPKeys is empty.
+	PGPKey *pgp.PrivateKey
 }
 
+var ErrVersionMismatch = errors.New("split packages have different versions")
+
 func Build(ctx context.Context, c *Config) error {
-	r, err := openRepo(ctx, c.Target, c.RepoName, c.PGPKey)
+	cfg := *c
+	cfg.PGPKeys = pgp.Keys(c.PGPKeys, c.PGPKey)
+	c = &cfg
+
+	r, err := openRepo(ctx, c.Target, c.RepoName, c.PGPKeys)
 	if err != nil {
 		return err
 	}
 	defer os.RemoveAll(r.dir)
 
+	r.debugRepo = c.DebugRepo
+
 	version := c.Version
-	if v := getLatest(r.packages); v != "" {
-		version += ",>v" + v
+	if v := getVersionConstraint(r.packages, c.KeepVersions); v != "" {
+		version += "," + v
 	}
 
 	releases, err := c.Source.ListReleases(ctx, &source.ListOptions{
@@ -45,6 +62,7 @@ func Build(ctx context.Context, c *Config) error {
 	var hasNew bool
 
 	for _, rel := range releases {
+		var pkgs []*Package
 		for _, asset := range rel.Assets {
 			if !isValidPackage(asset.Name) {
 				continue
@@ -53,17 +71,24 @@ func Build(ctx context.Context, c *Config) error {
 			if err != nil {
 				return err
 			}
-			if err := r.Add(asset.Name, data); err != nil {
+			p, err := r.Add(asset.Name, data)
+			if err != nil {
 				return err
 			}
+			pkgs = append(pkgs, p)
 			hasNew = true
 		}
+		if err := checkSplitVersions(pkgs); err != nil {
+			return fmt.Errorf("invalid release %s: %w", rel.Version, err)
+		}
 	}
 
 	if !hasNew {
 		return nil
 	}
 
+	removed := r.Prune(c.KeepVersions)
+
 	if err := r.Write(); err != nil {
 		return err
 	}
@@ -72,6 +97,12 @@ func Build(ctx context.Context, c *Config) error {
 		return err
 	}
 
+	for _, path := range removed {
+		if err := c.Target.Remove(ctx, path); err != nil {
+			log.Printf("Failed to delete %s: %s", path, err)
+		}
+	}
+
 	return nil
 }
 
@@ -85,18 +116,48 @@ func isValidPackage(filename string) bool {
 	return false
 }
 
-func getLatest(repo map[string]map[string]map[string]*Package) string {
-	var latest string
+// getVersionConstraint returns a constraint excluding all published versions.
+// When keeping a limited number of versions, versions older than the oldest
+// retained version are excluded too, so pruned versions aren't published again.
+func getVersionConstraint(repo map[string]map[string]map[string]*Package, keep int) string {
+	versions := getVersions(repo)
+	if len(versions) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, v := range versions {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString("!=" + v)
+	}
+	if keep > 0 && len(versions) >= keep {
+		b.WriteString(",>=" + versions[len(versions)-1])
+	}
+
+	return b.String()
+}
+
+// getVersions returns the unique release versions in the repository, newest first.
+func getVersions(repo map[string]map[string]map[string]*Package) []string {
+	var versions []string
 	for _, pkgMap := range repo {
-		for _, versions := range pkgMap {
-			for _, pkg := range versions {
-				if latest == "" || compareVersions(pkg.Version, latest) > 0 {
-					latest = pkg.Version
-				}
+		for _, pkgVersions := range pkgMap {
+			for version := range pkgVersions {
+				versions = append(versions, version)
 			}
 		}
 	}
-	return stripVersion(latest)
+	slices.SortFunc(versions, func(a, b string) int { return compareVersions(b, a) })
+
+	res := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if v = stripVersion(v); !slices.Contains(res, v) {
+			res = append(res, v)
+		}
+	}
+	return res
 }
 
 func stripVersion(version string) string {
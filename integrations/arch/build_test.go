@@ -1,9 +1,13 @@
 package arch_test
 
 import (
+	"errors"
 	"io/fs"
+	"maps"
 	"os"
 	"path"
+	"path/filepath"
+	"slices"
 	"testing"
 	"testing/fstest"
 
@@ -28,7 +32,8 @@ func TestBuild(t *testing.T) {
 		c := &arch.Config{RepoName: "kubri-test"}
 		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
 		c.Target, _ = target.New(target.Config{Path: dir})
-		c.PGPKey, _ = pgp.NewPrivateKey("test", "test@example.com")
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key}
 
 		if err := arch.Build(t.Context(), c); err != nil {
 			t.Fatal(err)
@@ -48,8 +53,14 @@ func TestBuild(t *testing.T) {
 		err := fstest.TestFS(os.DirFS(dir),
 			"key.asc",
 			"i686/kubri-test.db.sig",
+			"i686/kubri-test.db.tar.zst.sig",
+			"i686/kubri-test.files.sig",
+			"i686/kubri-test.files.tar.zst.sig",
 			"i686/kubri-test-2.0.0-1-i686.pkg.tar.zst.sig",
 			"x86_64/kubri-test.db.sig",
+			"x86_64/kubri-test.db.tar.zst.sig",
+			"x86_64/kubri-test.files.sig",
+			"x86_64/kubri-test.files.tar.zst.sig",
 			"x86_64/kubri-test-2.0.0-1-x86_64.pkg.tar.zst.sig",
 		)
 		if err != nil {
@@ -57,7 +68,7 @@ func TestBuild(t *testing.T) {
 		}
 
 		pub, _ := pgp.UnmarshalPublicKey(got["key.asc"].Data)
-		if diff := cmp.Diff(pub, c.PGPKey, test.ComparePGPKeys()); diff != "" {
+		if diff := cmp.Diff(pub, key, test.ComparePGPKeys()); diff != "" {
 			t.Fatal(diff)
 		}
 
@@ -71,4 +82,116 @@ func TestBuild(t *testing.T) {
 			return nil
 		})
 	})
+
+	t.Run("MultiplePGPKeys", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &arch.Config{RepoName: "kubri-test"}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		next, _ := pgp.NewPrivateKey("next", "next@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key, next}
+
+		if err := arch.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		wantKeys, _ := pgp.MarshalPublicKeys(pgp.PublicKeys(c.PGPKeys)...)
+		if diff := cmp.Diff(string(wantKeys), string(got["key.asc"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		data, sig := got["x86_64/kubri-test.db"].Data, got["x86_64/kubri-test.db.sig"].Data
+		if !pgp.Verify(pgp.Public(key), data, sig) {
+			t.Error("should pass pgp verification with the first key")
+		}
+		if pgp.Verify(pgp.Public(next), data, sig) {
+			t.Error("should only be signed with the first key")
+		}
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		tgt, _ := target.New(target.Config{Path: dir})
+
+		repo, err := arch.ReadRepo(t.Context(), tgt, "kubri-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"usr/", "usr/bin/", "usr/bin/kubri-test"}
+		for _, a := range []string{"i686", "x86_64"} {
+			pkg := repo[a]["kubri-test"]["2.0.0-1"]
+			if pkg == nil {
+				t.Fatalf("%s: missing package", a)
+			}
+			if diff := cmp.Diff(want, pkg.Files); diff != "" {
+				t.Error(a, diff)
+			}
+		}
+	})
+	t.Run("Backfill", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &arch.Config{RepoName: "kubri-test", Version: "2.0.0"}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := arch.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		c.Version = ""
+		if err := arch.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(os.DirFS("testdata"), os.DirFS(dir), test.CompareFS()); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("KeepVersions", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &arch.Config{RepoName: "kubri-test", Version: "<2", KeepVersions: 1}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+
+		if err := arch.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		c.Version = ""
+		if err := arch.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		repo, err := arch.ReadRepo(t.Context(), c.Target, "kubri-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range []string{"i686", "x86_64"} {
+			if diff := cmp.Diff([]string{"2.0.0-1"}, slices.Collect(maps.Keys(repo[a]["kubri-test"]))); diff != "" {
+				t.Error(a, diff)
+			}
+			for _, v := range []string{"1.0.0", "1.1.0"} {
+				p := a + "/kubri-test-" + v + "-1-" + a + ".pkg.tar.zst"
+				if _, err := os.Stat(filepath.Join(dir, p)); !errors.Is(err, fs.ErrNotExist) {
+					t.Error(p, "should be deleted")
+				}
+			}
+		}
+
+		// Pruned versions must not be published again.
+		want := test.ReadFS(os.DirFS(dir))
+		if err := arch.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, test.ReadFS(os.DirFS(dir))); diff != "" {
+			t.Error(diff)
+		}
+	})
 }
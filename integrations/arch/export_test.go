@@ -0,0 +1,17 @@
+package arch
+
+import (
+	"context"
+	"os"
+
+	"github.com/kubri/kubri/target"
+)
+
+func ReadRepo(ctx context.Context, t target.Target, repoName string) (map[string]map[string]map[string]*Package, error) {
+	r, err := openRepo(ctx, t, repoName, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(r.dir)
+	return r.packages, nil
+}
@@ -3,6 +3,7 @@ package arch
 import (
 	"archive/tar"
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"errors"
@@ -10,7 +11,9 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,13 +26,18 @@ import (
 )
 
 type repo struct {
-	dir      string
-	name     string
-	pgpKey   *pgp.PrivateKey
-	packages map[string]map[string]map[string]*Package // arch -> pkgName -> versions
+	dir       string
+	name      string
+	pgpKeys   []*pgp.PrivateKey
+	debugRepo bool
+	packages  map[string]map[string]map[string]*Package // arch -> pkgName -> versions
+
+	// unlisted holds older versions only found as package files on the target,
+	// which have no metadata to list them in a database.
+	unlisted map[*Package]bool
 }
 
-func openRepo(ctx context.Context, t target.Target, repoName string, pgpKey *pgp.PrivateKey) (*repo, error) {
+func openRepo(ctx context.Context, t target.Target, repoName string, pgpKeys []*pgp.PrivateKey) (*repo, error) {
 	dir, err := os.MkdirTemp("", "archrepo-")
 	if err != nil {
 		return nil, err
@@ -38,43 +46,102 @@ func openRepo(ctx context.Context, t target.Target, repoName string, pgpKey *pgp
 	r := &repo{
 		dir:      dir,
 		name:     repoName,
-		pgpKey:   pgpKey,
+		pgpKeys:  pgpKeys,
 		packages: map[string]map[string]map[string]*Package{},
+		unlisted: map[*Package]bool{},
 	}
 
-	// TODO: Implement `Walk` on targets so we can iterate over all folders and
-	// avoid hard-coding the archs.
-	archs := []string{
-		"x86_64", "any",
-		"aarch64", "armv7h", // https://archlinuxarm.org/packages
-		"powerpc64le", "powerpc64", "powerpc", "riscv64", // https://archlinuxpower.org/
-		"i686", "pentium4", // https://archlinux32.org/architecture/
+	entries, err := target.ReadDir(ctx, t, "")
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	for _, arch := range archs {
-		dbPath := filepath.Join(arch, repoName+".db")
-		db, err := t.NewReader(ctx, dbPath)
-		if err != nil {
-			if errors.Is(err, fs.ErrNotExist) {
-				continue
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		arch := e.Name()
+
+		for _, name := range []string{repoName, repoName + "-debug"} {
+			db, dbPath, err := openDB(ctx, t, arch, name)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return nil, err
+			}
+			defer db.Close()
+
+			if err := r.readDB(db, arch); err != nil {
+				return r, fmt.Errorf("failed to parse %s: %w", dbPath, err)
 			}
-			return nil, err
 		}
-		defer db.Close()
 
-		if err := r.readDB(db, arch); err != nil {
-			return r, fmt.Errorf("failed to parse %s: %w", dbPath, err)
+		// Databases only list the latest versions, as pacman doesn't support
+		// multiple versions of a package, so older versions are found from the
+		// package files.
+		files, err := target.ReadDir(ctx, t, arch)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if p := parseFilename(f.Name()); p != nil && p.Arch == arch && !r.hasPackage(p) {
+				r.addPackage(p)
+				r.unlisted[p] = true
+			}
 		}
 	}
 
 	return r, nil
 }
 
+// openDB opens the files database for the arch, which also holds the file lists
+// of the packages. It falls back to the package database for repositories
+// created by older versions.
+func openDB(ctx context.Context, t target.Target, arch, repoName string) (io.ReadCloser, string, error) {
+	var (
+		db     io.ReadCloser
+		dbPath string
+		err    error
+	)
+	for _, ext := range []string{".files", ".db"} {
+		dbPath = filepath.Join(arch, repoName+ext)
+		db, err = t.NewReader(ctx, dbPath)
+		if !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	return db, dbPath, err
+}
+
+// parseFilename returns the name, version and arch of a package file named
+// <name>-<pkgver>-<pkgrel>-<arch>.pkg.tar.<ext>, or nil if it isn't a package.
+func parseFilename(filename string) *Package {
+	if !isValidPackage(filename) {
+		return nil
+	}
+	base, _, _ := strings.Cut(filename, ".pkg.tar.")
+	parts := strings.Split(base, "-")
+	n := len(parts)
+	if n < 4 {
+		return nil
+	}
+	return &Package{
+		Filename: filename,
+		Name:     strings.Join(parts[:n-3], "-"),
+		Version:  parts[n-3] + "-" + parts[n-2],
+		Arch:     parts[n-1],
+	}
+}
+
 // Add adds a package to the repository.
-func (r *repo) Add(filename string, data []byte) error {
+func (r *repo) Add(filename string, data []byte) (*Package, error) {
 	p, err := parsePkgInfo(filename, bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("failed to parse .PKGINFO for %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to parse .PKGINFO for %s: %w", filename, err)
 	}
 
 	p.SHA256Sum = sha256.Sum256(data)
@@ -83,39 +150,82 @@ func (r *repo) Add(filename string, data []byte) error {
 
 	pkgPath := filepath.Join(r.dir, p.Arch, filename)
 	if err := os.MkdirAll(filepath.Dir(pkgPath), 0o750); err != nil {
-		return fmt.Errorf("failed to create arch folder: %w", err)
+		return nil, fmt.Errorf("failed to create arch folder: %w", err)
 	}
 	if err := os.WriteFile(pkgPath, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write package file: %w", err)
+		return nil, fmt.Errorf("failed to write package file: %w", err)
 	}
 
-	if r.pgpKey != nil {
-		sig, err := pgp.Sign(r.pgpKey, data)
-		if err != nil {
-			return fmt.Errorf("signing package file: %w", err)
-		}
-		sigPath := pkgPath + ".sig"
-		if err := os.WriteFile(sigPath, sig, 0o600); err != nil {
-			return fmt.Errorf("failed to write signature: %w", err)
-		}
+	if err := r.sign(pkgPath, data); err != nil {
+		return nil, err
 	}
 
 	r.addPackage(p)
 
-	return nil
+	return p, nil
+}
+
+// Prune removes all but the newest keep versions from the repository, returning
+// the paths of the removed package files. If keep is zero all versions are kept.
+func (r *repo) Prune(keep int) []string {
+	versions := getVersions(r.packages)
+	if keep <= 0 || len(versions) <= keep {
+		return nil
+	}
+	retain := make(map[string]bool, keep)
+	for _, v := range versions[:keep] {
+		retain[v] = true
+	}
+
+	var removed []string
+	for arch, pkgs := range r.packages {
+		for name, pkgVersions := range pkgs {
+			for version, p := range pkgVersions {
+				if retain[stripVersion(version)] {
+					continue
+				}
+				delete(pkgVersions, version)
+				pkgPath := path.Join(arch, p.Filename)
+				_ = os.Remove(filepath.Join(r.dir, pkgPath))
+				_ = os.Remove(filepath.Join(r.dir, pkgPath+".sig"))
+				removed = append(removed, pkgPath)
+				if len(r.pgpKeys) > 0 {
+					removed = append(removed, pkgPath+".sig")
+				}
+			}
+			if len(pkgVersions) == 0 {
+				delete(pkgs, name)
+			}
+		}
+	}
+
+	return removed
 }
 
 // Write writes the repository to disk. It creates a separate .db file for each arch.
-// It also writes the public key to key.asc if it exists.
+// It also writes the public keys to key.asc if any are set.
+// If debugRepo is set, debug packages are written to a separate <name>-debug database.
 func (r *repo) Write() error {
 	for arch, pkgs := range r.packages {
-		if err := r.writeDB(arch, pkgs); err != nil {
-			return fmt.Errorf("failed to write db for %s: %w", arch, err)
+		dbs := map[string][]*Package{r.name: nil}
+		for _, versions := range pkgs {
+			for _, pkg := range versions {
+				name := r.name
+				if r.debugRepo && isDebug(pkg) {
+					name += "-debug"
+				}
+				dbs[name] = append(dbs[name], pkg)
+			}
+		}
+		for name, pkgs := range dbs {
+			if err := r.writeDB(arch, name, pkgs); err != nil {
+				return fmt.Errorf("failed to write %s db for %s: %w", name, arch, err)
+			}
 		}
 	}
 
-	if r.pgpKey != nil {
-		key, err := pgp.MarshalPublicKey(pgp.Public(r.pgpKey))
+	if len(r.pgpKeys) > 0 {
+		key, err := pgp.MarshalPublicKeys(pgp.PublicKeys(r.pgpKeys)...)
 		if err != nil {
 			return fmt.Errorf("failed to marshal key: %w", err)
 		}
@@ -137,6 +247,9 @@ func (r *repo) readDB(dbData io.ReadCloser, arch string) error {
 
 	tarReader := tar.NewReader(zr)
 
+	pkgs := map[string]*Package{}
+	files := map[string][]string{}
+
 	for {
 		hdr, err := tarReader.Next()
 		if errors.Is(err, io.EOF) {
@@ -145,25 +258,42 @@ func (r *repo) readDB(dbData io.ReadCloser, arch string) error {
 		if err != nil {
 			return err
 		}
-		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(hdr.Name, "/desc") {
+		if hdr.Typeflag != tar.TypeReg {
 			continue
 		}
 
-		var pkg Package
-		if err = desc.NewDecoder(tarReader).Decode(&pkg); err != nil {
-			return fmt.Errorf("failed to decode %s: %w", hdr.Name, err)
-		}
-
-		if pkg.Arch != arch && pkg.Arch != "any" {
-			return fmt.Errorf("%s arch mismatch: %s", pkg.Name, pkg.Arch)
+		dir, name := path.Split(hdr.Name)
+		switch name {
+		case "desc":
+			var pkg Package
+			if err = desc.NewDecoder(tarReader).Decode(&pkg); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", hdr.Name, err)
+			}
+			if pkg.Arch != arch && pkg.Arch != "any" {
+				return fmt.Errorf("%s arch mismatch: %s", pkg.Name, pkg.Arch)
+			}
+			pkgs[dir] = &pkg
+		case "files":
+			var f Files
+			if err = desc.NewDecoder(tarReader).Decode(&f); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", hdr.Name, err)
+			}
+			files[dir] = f.Files
 		}
+	}
 
-		r.addPackage(&pkg)
+	for dir, pkg := range pkgs {
+		pkg.Files = files[dir]
+		r.addPackage(pkg)
 	}
 
 	return nil
 }
 
+func (r *repo) hasPackage(p *Package) bool {
+	return r.packages[p.Arch][p.Name][p.Version] != nil
+}
+
 func (r *repo) addPackage(p *Package) {
 	arch, ok := r.packages[p.Arch]
 	if !ok {
@@ -178,87 +308,180 @@ func (r *repo) addPackage(p *Package) {
 	pkg[p.Version] = p
 }
 
-//nolint:funlen
-func (r *repo) writeDB(arch string, pkgs map[string]map[string]*Package) error {
-	dbPath := filepath.Join(r.dir, arch, r.name+".db")
+// writeDB writes the package database along with the files database, which
+// also holds the list of files in each package for `pacman -F`. Both are written
+// under the names used by repo-add, as targets don't support symlinks.
+func (r *repo) writeDB(arch, name string, pkgs []*Package) error {
+	latest := slices.DeleteFunc(latestPackages(pkgs), func(p *Package) bool { return r.unlisted[p] })
+
+	db, err := marshalDB(latest, false)
+	if err != nil {
+		return err
+	}
+	files, err := marshalDB(latest, true)
+	if err != nil {
+		return err
+	}
 
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0o750); err != nil {
+	dir := filepath.Join(r.dir, arch)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
 		return fmt.Errorf("failed to create folder: %w", err)
 	}
 
-	dbFile, err := os.Create(dbPath)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{name + ".db", db},
+		{name + ".db.tar.zst", db},
+		{name + ".files", files},
+		{name + ".files.tar.zst", files},
+	} {
+		p := filepath.Join(dir, f.name)
+		if err := os.WriteFile(p, f.data, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		if err := r.sign(p, f.data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// latestPackages returns the packages to list in a database. Packages split from
+// the same pkgbase are published together, so only packages from the latest
+// version of each pkgbase are listed. This drops packages which are no longer
+// built from it.
+func latestPackages(pkgs []*Package) []*Package {
+	latest := map[string]string{}
+	for _, pkg := range pkgs {
+		base := pkgBase(pkg)
+		if v, ok := latest[base]; !ok || compareVersions(pkg.Version, v) > 0 {
+			latest[base] = pkg.Version
+		}
+	}
+
+	res := make([]*Package, 0, len(latest))
+	for _, pkg := range pkgs {
+		if pkg.Version == latest[pkgBase(pkg)] {
+			res = append(res, pkg)
+		}
+	}
+	slices.SortFunc(res, func(a, b *Package) int { return strings.Compare(a.Name, b.Name) })
+
+	return res
+}
+
+// pkgBase returns the name of the package the package was split from.
+func pkgBase(p *Package) string {
+	return cmp.Or(p.Base, p.Name)
+}
+
+// isDebug reports whether the package holds detached debug symbols, as built by
+// makepkg with the debug option.
+func isDebug(p *Package) bool {
+	return p.Name == pkgBase(p)+"-debug" || (p.Base == "" && strings.HasSuffix(p.Name, "-debug"))
+}
+
+// checkSplitVersions returns an error if packages split from the same pkgbase
+// don't share the same version.
+func checkSplitVersions(pkgs []*Package) error {
+	versions := map[string]*Package{}
+	for _, pkg := range pkgs {
+		base := pkgBase(pkg)
+		if p, ok := versions[base]; ok && p.Version != pkg.Version {
+			return fmt.Errorf("%w: %s has version %s but %s has version %s",
+				ErrVersionMismatch, p.Name, p.Version, pkg.Name, pkg.Version)
+		}
+		versions[base] = pkg
+	}
+	return nil
+}
+
+// sign writes a detached signature for the file with the first key if any keys
+// are set.
+func (r *repo) sign(filename string, data []byte) error {
+	if len(r.pgpKeys) == 0 {
+		return nil
+	}
+	sig, err := pgp.Sign(r.pgpKeys[0], data)
 	if err != nil {
-		return fmt.Errorf("failed to create db file: %w", err)
+		return fmt.Errorf("failed to sign %s: %w", filepath.Base(filename), err)
 	}
-	defer dbFile.Close()
+	if err := os.WriteFile(filename+".sig", sig, 0o600); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
 
-	zstdWriter, err := zstd.NewWriter(dbFile,
+// marshalDB returns a zstd compressed database containing a desc entry for each
+// package and, if withFiles is set, a files entry.
+func marshalDB(pkgs []*Package, withFiles bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw, err := zstd.NewWriter(&buf,
 		zstd.WithEncoderLevel(zstd.SpeedBestCompression),
 		zstd.WithZeroFrames(true),
 		zstd.WithEncoderCRC(false),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create zstd writer: %w", err)
+		return nil, fmt.Errorf("failed to create zstd writer: %w", err)
 	}
-	tw := tar.NewWriter(zstdWriter)
+	tw := tar.NewWriter(zw)
 
-	for pkgName, versions := range pkgs {
-		var latestPkg *Package
-		for _, pkg := range versions {
-			if latestPkg == nil || compareVersions(pkg.Version, latestPkg.Version) > 0 {
-				latestPkg = pkg
-			}
-		}
+	for _, pkg := range pkgs {
+		dir := pkg.Name + "-" + pkg.Version + "/"
 
-		b, err := desc.Marshal(latestPkg)
+		b, err := desc.Marshal(pkg)
 		if err != nil {
-			return fmt.Errorf("failed to marshal desc for %s: %w", pkgName, err)
+			return nil, fmt.Errorf("failed to marshal desc for %s: %w", pkg.Name, err)
 		}
-		h := &tar.Header{
-			Name:    latestPkg.Name + "-" + latestPkg.Version + "/desc",
-			Mode:    0o644,
-			Size:    int64(len(b)),
-			ModTime: time.Unix(0, 0),
-			Uid:     0,
-			Gid:     0,
+		if err = writeTarFile(tw, dir+"desc", b); err != nil {
+			return nil, err
+		}
+
+		if !withFiles {
+			continue
 		}
-		if err := tw.WriteHeader(h); err != nil {
-			return fmt.Errorf("failed to write tar header: %w", err)
+
+		b, err = desc.Marshal(&Files{Files: pkg.Files})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal files for %s: %w", pkg.Name, err)
 		}
-		if _, err := tw.Write(b); err != nil {
-			return fmt.Errorf("failed to write desc: %w", err)
+		if err = writeTarFile(tw, dir+"files", b); err != nil {
+			return nil, err
 		}
 	}
 
 	if err := tw.Close(); err != nil {
-		return fmt.Errorf("failed to close tar writer: %w", err)
-	}
-	if err := zstdWriter.Close(); err != nil {
-		return fmt.Errorf("failed to close zstd writer: %w", err)
+		return nil, fmt.Errorf("failed to close tar writer: %w", err)
 	}
-	if err := dbFile.Close(); err != nil {
-		return fmt.Errorf("failed to close db file: %w", err)
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close zstd writer: %w", err)
 	}
 
-	if r.pgpKey != nil {
-		dbData, err := os.ReadFile(dbPath)
-		if err != nil {
-			return fmt.Errorf("failed to read db file: %w", err)
-		}
-		sig, err := pgp.Sign(r.pgpKey, dbData)
-		if err != nil {
-			return fmt.Errorf("failed to sign db file: %w", err)
-		}
-		sigPath := dbPath + ".sig"
-		if err := os.WriteFile(sigPath, sig, 0o600); err != nil { //nolint:gosec // false positive
-			return fmt.Errorf("failed to write sig: %w", err)
-		}
-	}
+	return buf.Bytes(), nil
+}
 
+func writeTarFile(tw *tar.Writer, name string, b []byte) error {
+	h := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(b)),
+		ModTime: time.Unix(0, 0),
+	}
+	if err := tw.WriteHeader(h); err != nil {
+		return fmt.Errorf("failed to write tar header: %w", err)
+	}
+	if _, err := tw.Write(b); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
 	return nil
 }
 
-// parsePkgInfo extracts .PKGINFO from the .pkg.tar.* file.
+// parsePkgInfo extracts .PKGINFO from the .pkg.tar.* file, along with the list
+// of files the package installs.
 func parsePkgInfo(filename string, f io.Reader) (*Package, error) {
 	r, err := decompress(filepath.Ext(filename))(f)
 	if err != nil {
@@ -277,22 +500,34 @@ func parsePkgInfo(filename string, f io.Reader) (*Package, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to read tar: %w", err)
 		}
-		if hdr.Typeflag != tar.TypeReg || hdr.Name != ".PKGINFO" {
+
+		// Skip package metadata such as .PKGINFO, .MTREE & .INSTALL.
+		if strings.HasPrefix(hdr.Name, ".") {
+			if hdr.Typeflag == tar.TypeReg && hdr.Name == ".PKGINFO" {
+				infoData, err := io.ReadAll(tarReader)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read .PKGINFO: %w", err)
+				}
+				err = pkginfo.Unmarshal(infoData, pkg)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse .PKGINFO: %w", err)
+				}
+			}
 			continue
 		}
-		infoData, err := io.ReadAll(tarReader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read .PKGINFO: %w", err)
-		}
-		err = pkginfo.Unmarshal(infoData, pkg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse .PKGINFO: %w", err)
+
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if hdr.Typeflag == tar.TypeDir {
+			name += "/"
 		}
+		pkg.Files = append(pkg.Files, name)
 	}
 
 	if pkg.Name == "" || pkg.Version == "" || pkg.Arch == "" {
 		return nil, errors.New("missing required fields in .PKGINFO")
 	}
 
+	slices.Sort(pkg.Files)
+
 	return pkg, nil
 }
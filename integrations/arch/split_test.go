@@ -0,0 +1,158 @@
+package arch_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/kubri/kubri/integrations/arch"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestBuildSplitPackages(t *testing.T) {
+	src := t.TempDir()
+	writePkg(t, src, "v1.0.0", "foo", "foo", "1.0.0-1")
+	writePkg(t, src, "v1.0.0", "foo-docs", "foo", "1.0.0-1")
+	writePkg(t, src, "v1.0.0", "foo-debug", "foo", "1.0.0-1")
+	writePkg(t, src, "v2.0.0", "foo", "foo", "2.0.0-1")
+	writePkg(t, src, "v2.0.0", "foo-debug", "foo", "2.0.0-1")
+
+	dir := t.TempDir()
+	c := &arch.Config{RepoName: "test", DebugRepo: true}
+	c.Source, _ = source.New(source.Config{Path: src})
+	c.Target, _ = target.New(target.Config{Path: dir})
+
+	if err := arch.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		db   string
+		want []string
+	}{
+		{"test.db", []string{"foo-2.0.0-1/desc"}},
+		{"test.files", []string{"foo-2.0.0-1/desc", "foo-2.0.0-1/files"}},
+		{"test-debug.db", []string{"foo-debug-2.0.0-1/desc"}},
+	}
+
+	for _, test := range tests {
+		got := readDB(t, filepath.Join(dir, "x86_64", test.db))
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Error(test.db, diff)
+		}
+	}
+
+	// Existing debug packages must be picked up from the debug database.
+	repo, err := arch.ReadRepo(t.Context(), c.Target, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo["x86_64"]["foo-debug"]["2.0.0-1"] == nil {
+		t.Error("should read debug packages")
+	}
+
+	// Older versions must be picked up from the package files.
+	for _, name := range []string{"foo", "foo-docs", "foo-debug"} {
+		if p := repo["x86_64"][name]["1.0.0-1"]; p == nil || p.Filename != name+"-1.0.0-1-x86_64.pkg.tar.zst" {
+			t.Errorf("should read %s 1.0.0-1 from package file", name)
+		}
+	}
+
+	// Older versions must be kept without being listed in the databases.
+	writePkg(t, src, "v3.0.0", "foo", "foo", "3.0.0-1")
+	if err := arch.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"foo-3.0.0-1/desc"}, readDB(t, filepath.Join(dir, "x86_64", "test.db"))); diff != "" {
+		t.Error(diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x86_64", "foo-docs-1.0.0-1-x86_64.pkg.tar.zst")); err != nil {
+		t.Error("foo-docs 1.0.0-1 should be kept")
+	}
+
+	// Older versions must be pruned from the package files.
+	c.KeepVersions = 1
+	writePkg(t, src, "v4.0.0", "foo", "foo", "4.0.0-1")
+	if err := arch.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"foo-1.0.0-1", "foo-docs-1.0.0-1", "foo-debug-1.0.0-1", "foo-2.0.0-1", "foo-3.0.0-1"} {
+		if _, err := os.Stat(filepath.Join(dir, "x86_64", name+"-x86_64.pkg.tar.zst")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s should be deleted", name)
+		}
+	}
+}
+
+func TestBuildSplitVersionMismatch(t *testing.T) {
+	src := t.TempDir()
+	writePkg(t, src, "v1.0.0", "foo", "foo", "1.0.0-1")
+	writePkg(t, src, "v1.0.0", "foo-docs", "foo", "1.0.0-2")
+
+	c := &arch.Config{RepoName: "test"}
+	c.Source, _ = source.New(source.Config{Path: src})
+	c.Target, _ = target.New(target.Config{Path: t.TempDir()})
+
+	if err := arch.Build(t.Context(), c); !errors.Is(err, arch.ErrVersionMismatch) {
+		t.Fatalf("should return %q got %q", arch.ErrVersionMismatch, err)
+	}
+}
+
+func writePkg(t *testing.T, dir, version, name, base, pkgver string) {
+	t.Helper()
+
+	info := "pkgname = " + name + "\npkgbase = " + base + "\npkgver = " + pkgver + "\narch = x86_64\n"
+
+	var buf bytes.Buffer
+	zw, _ := zstd.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	tw.WriteHeader(&tar.Header{Name: ".PKGINFO", Mode: 0o644, Size: int64(len(info))})
+	tw.Write([]byte(info))
+	tw.WriteHeader(&tar.Header{Name: "usr/share/" + name, Mode: 0o644})
+	tw.Close()
+	zw.Close()
+
+	path := filepath.Join(dir, version, name+"-"+pkgver+"-x86_64.pkg.tar.zst")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readDB(t *testing.T, path string) []string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	var names []string
+	tr := tar.NewReader(zr)
+	for {
+		h, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, h.Name)
+	}
+	slices.Sort(names)
+
+	return names
+}
@@ -21,9 +21,10 @@ type Package struct {
 	Depends        []string `desc:"DEPENDS"     pkginfo:"depend"`
 	OptDepends     []string `desc:"OPTDEPENDS"  pkginfo:"optdepend"`
 	MakeDepends    []string `desc:"MAKEDEPENDS" pkginfo:"makedepend"`
+	Files          []string `desc:"-"           pkginfo:"-"`
 }
 
 // Files holds the list of files in a package.
 type Files struct {
-	Files string `desc:"FILES" pkginfo:"-"`
+	Files []string `desc:"FILES" pkginfo:"-"`
 }
@@ -0,0 +1,145 @@
+package homebrew
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/kubri/kubri/internal/assets"
+	"github.com/kubri/kubri/source"
+	"github.com/kubri/kubri/target"
+)
+
+type Config struct {
+	Formulae []*Formula
+	Casks    []*Cask
+
+	Source         *source.Source
+	Target         target.Target
+	Version        string
+	Prerelease     bool
+	UploadPackages bool
+}
+
+type Formula struct {
+	Name         string
+	Description  string
+	Homepage     string
+	License      string
+	Assets       map[Platform]string
+	Dependencies []string
+	Binaries     []string
+	Install      string
+	Test         string
+}
+
+type Cask struct {
+	Name        string
+	DisplayName string
+	Description string
+	Homepage    string
+	Assets      map[Platform]string
+	App         string
+	Binaries    []string
+}
+
+type Platform string
+
+const (
+	MacOS      Platform = "macos"
+	MacOSARM64 Platform = "macos-arm64"
+	MacOSX64   Platform = "macos-x64"
+	LinuxARM64 Platform = "linux-arm64"
+	LinuxX64   Platform = "linux-x64"
+)
+
+var (
+	ErrInvalidPlatform   = errors.New("invalid platform")
+	ErrConflictingAssets = errors.New("conflicting assets")
+	ErrNoAssets          = assets.ErrNoAssets
+	ErrDuplicateName     = assets.ErrDuplicateName
+)
+
+// asset is a release asset downloaded for a platform.
+type asset = assets.Asset
+
+// Build writes Homebrew formulae to Formula/<name>.rb and casks to
+// Casks/<name>.rb on the target.
+func Build(ctx context.Context, c *Config) error {
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	releases, err := c.Source.ListReleases(ctx, &source.ListOptions{
+		Version:    c.Version,
+		Prerelease: c.Prerelease,
+	})
+	if err == source.ErrNoReleaseFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ac := &assets.Config{Source: c.Source, Target: c.Target, UploadPackages: c.UploadPackages}
+
+	for _, f := range c.Formulae {
+		r, a, err := assets.Latest(ctx, ac, releases, f.Assets)
+		if errors.Is(err, assets.ErrNoMatchingReleases) {
+			log.Printf("Skipping formula %s without matching assets", f.Name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		b := formula(f, strings.TrimPrefix(r.Version, "v"), a)
+		if err = assets.Write(ctx, c.Target, "Formula/"+f.Name+".rb", b); err != nil {
+			return err
+		}
+	}
+
+	for _, cask := range c.Casks {
+		r, a, err := assets.Latest(ctx, ac, releases, cask.Assets)
+		if errors.Is(err, assets.ErrNoMatchingReleases) {
+			log.Printf("Skipping cask %s without matching assets", cask.Name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		b := caskFile(cask, strings.TrimPrefix(r.Version, "v"), a)
+		if err = assets.Write(ctx, c.Target, "Casks/"+cask.Name+".rb", b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validate(c *Config) error {
+	seen := map[string]bool{}
+	check := func(kind, name string, patterns map[Platform]string, platforms []Platform) error {
+		if err := assets.Validate(seen, kind+" "+name, patterns, platforms, ErrInvalidPlatform); err != nil {
+			return err
+		}
+		if patterns[MacOS] != "" && (patterns[MacOSARM64] != "" || patterns[MacOSX64] != "") {
+			return fmt.Errorf("%w: %s for %s %s", ErrConflictingAssets, MacOS, kind, name)
+		}
+		return nil
+	}
+
+	for _, f := range c.Formulae {
+		if err := check("formula", f.Name, f.Assets, formulaPlatforms); err != nil {
+			return err
+		}
+	}
+	for _, cask := range c.Casks {
+		if err := check("cask", cask.Name, cask.Assets, caskPlatforms); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
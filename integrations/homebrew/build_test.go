@@ -0,0 +1,290 @@
+package homebrew_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/homebrew"
+	"github.com/kubri/kubri/internal/testsource"
+	"github.com/kubri/kubri/source"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestBuild(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}, {Version: "v1.1.0"}})
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		for _, name := range []string{"app_darwin_arm64.tar.gz", "app_darwin_amd64.tar.gz", "app_linux_amd64.tar.gz", "App.dmg"} {
+			src.UploadAsset(t.Context(), version, name, []byte(version+name))
+		}
+	}
+	src.UploadAsset(t.Context(), "v1.0.0", "app_linux_arm64.tar.gz", []byte("v1.0.0app_linux_arm64.tar.gz"))
+
+	sum := func(s string) string {
+		b := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(b[:])
+	}
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir()})
+
+	c := &homebrew.Config{
+		Source: src,
+		Target: tgt,
+		Formulae: []*homebrew.Formula{
+			{
+				Name:        "app",
+				Description: `My "app"`,
+				Homepage:    "https://example.com",
+				License:     "MIT",
+				Assets: map[homebrew.Platform]string{
+					homebrew.MacOSARM64: "*_darwin_arm64.tar.gz",
+					homebrew.MacOSX64:   "*_darwin_amd64.tar.gz",
+					homebrew.LinuxARM64: "*_linux_arm64.tar.gz",
+					homebrew.LinuxX64:   "*_linux_amd64.tar.gz",
+				},
+				Dependencies: []string{"git"},
+				Test:         "system \"#{bin}/app\", \"--version\"",
+			},
+			{
+				Name:    "app-cli",
+				Assets:  map[homebrew.Platform]string{homebrew.MacOSARM64: "*_darwin_arm64.tar.gz"},
+				Install: "bin.install \"app\" => \"app-cli\"\nman1.install \"app.1\"",
+			},
+			{
+				Name:   "missing",
+				Assets: map[homebrew.Platform]string{homebrew.LinuxX64: "*.deb"},
+			},
+		},
+		Casks: []*homebrew.Cask{
+			{
+				Name:        "app",
+				DisplayName: "My App",
+				Description: "My app",
+				Homepage:    "https://example.com",
+				Assets:      map[homebrew.Platform]string{homebrew.MacOS: "*.dmg"},
+				App:         "My App.app",
+				Binaries:    []string{"My App.app/Contents/MacOS/app"},
+			},
+		},
+	}
+
+	if err := homebrew.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Formula/app.rb": `class App < Formula
+  desc "My \"app\""
+  homepage "https://example.com"
+  version "1.1.0"
+  license "MIT"
+
+  on_macos do
+    on_arm do
+      url "https://example.com/v1.1.0/app_darwin_arm64.tar.gz"
+      sha256 "` + sum("v1.1.0app_darwin_arm64.tar.gz") + `"
+    end
+    on_intel do
+      url "https://example.com/v1.1.0/app_darwin_amd64.tar.gz"
+      sha256 "` + sum("v1.1.0app_darwin_amd64.tar.gz") + `"
+    end
+  end
+
+  on_linux do
+    on_intel do
+      url "https://example.com/v1.1.0/app_linux_amd64.tar.gz"
+      sha256 "` + sum("v1.1.0app_linux_amd64.tar.gz") + `"
+    end
+  end
+
+  depends_on "git"
+
+  def install
+    bin.install "app"
+  end
+
+  test do
+    system "#{bin}/app", "--version"
+  end
+end
+`,
+		"Formula/app-cli.rb": `class AppCli < Formula
+  version "1.1.0"
+
+  on_macos do
+    on_arm do
+      url "https://example.com/v1.1.0/app_darwin_arm64.tar.gz"
+      sha256 "` + sum("v1.1.0app_darwin_arm64.tar.gz") + `"
+    end
+  end
+
+  def install
+    bin.install "app" => "app-cli"
+    man1.install "app.1"
+  end
+end
+`,
+		"Casks/app.rb": `cask "app" do
+  version "1.1.0"
+
+  sha256 "` + sum("v1.1.0App.dmg") + `"
+  url "https://example.com/v1.1.0/App.dmg"
+
+  name "My App"
+  desc "My app"
+  homepage "https://example.com"
+
+  app "My App.app"
+  binary "My App.app/Contents/MacOS/app"
+end
+`,
+	}
+
+	for name, want := range want {
+		r, err := tgt.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(r)
+		r.Close()
+
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s:\n%s", name, diff)
+		}
+	}
+
+	if _, err := tgt.NewReader(t.Context(), "Formula/missing.rb"); err == nil {
+		t.Error("should not create formula without matching assets")
+	}
+}
+
+func TestBuildUploadPackages(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "app_darwin_arm64.tar.gz", []byte("v1.0.0app_darwin_arm64.tar.gz"))
+	src.UploadAsset(t.Context(), "v1.0.0", "App.dmg", []byte("v1.0.0App.dmg"))
+
+	sum := func(s string) string {
+		b := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(b[:])
+	}
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir(), URL: "https://dl.example.com"})
+
+	c := &homebrew.Config{
+		Source:         src,
+		Target:         tgt,
+		UploadPackages: true,
+		Formulae: []*homebrew.Formula{{
+			Name:   "app",
+			Assets: map[homebrew.Platform]string{homebrew.MacOSARM64: "*_darwin_arm64.tar.gz"},
+		}},
+		Casks: []*homebrew.Cask{{
+			Name:   "app",
+			Assets: map[homebrew.Platform]string{homebrew.MacOS: "*.dmg"},
+			App:    "App.app",
+		}},
+	}
+
+	if err := homebrew.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Formula/app.rb": `class App < Formula
+  version "1.0.0"
+
+  on_macos do
+    on_arm do
+      url "https://dl.example.com/v1.0.0/app_darwin_arm64.tar.gz"
+      sha256 "` + sum("v1.0.0app_darwin_arm64.tar.gz") + `"
+    end
+  end
+
+  def install
+    bin.install "app"
+  end
+end
+`,
+		"Casks/app.rb": `cask "app" do
+  version "1.0.0"
+
+  sha256 "` + sum("v1.0.0App.dmg") + `"
+  url "https://dl.example.com/v1.0.0/App.dmg"
+
+  name "app"
+
+  app "App.app"
+end
+`,
+		"v1.0.0/app_darwin_arm64.tar.gz": "v1.0.0app_darwin_arm64.tar.gz",
+		"v1.0.0/App.dmg":                 "v1.0.0App.dmg",
+	}
+
+	for name, want := range want {
+		r, err := tgt.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(r)
+		r.Close()
+
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s:\n%s", name, diff)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+	tgt, _ := target.New(target.Config{Path: t.TempDir()})
+
+	tests := []struct {
+		name   string
+		config *homebrew.Config
+		err    error
+	}{
+		{
+			name:   "no assets",
+			config: &homebrew.Config{Formulae: []*homebrew.Formula{{Name: "app"}}},
+			err:    homebrew.ErrNoAssets,
+		},
+		{
+			name: "invalid platform",
+			config: &homebrew.Config{Casks: []*homebrew.Cask{{
+				Name:   "app",
+				Assets: map[homebrew.Platform]string{homebrew.LinuxX64: "*"},
+			}}},
+			err: homebrew.ErrInvalidPlatform,
+		},
+		{
+			name: "conflicting assets",
+			config: &homebrew.Config{Formulae: []*homebrew.Formula{{
+				Name:   "app",
+				Assets: map[homebrew.Platform]string{homebrew.MacOS: "*", homebrew.MacOSX64: "*"},
+			}}},
+			err: homebrew.ErrConflictingAssets,
+		},
+		{
+			name: "duplicate name",
+			config: &homebrew.Config{Formulae: []*homebrew.Formula{
+				{Name: "app", Assets: map[homebrew.Platform]string{homebrew.MacOS: "*"}},
+				{Name: "app", Assets: map[homebrew.Platform]string{homebrew.LinuxX64: "*"}},
+			}},
+			err: homebrew.ErrDuplicateName,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.config.Source = src
+			test.config.Target = tgt
+			if err := homebrew.Build(t.Context(), test.config); !errors.Is(err, test.err) {
+				t.Errorf("want %v got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+package homebrew
+
+var ClassName = className
@@ -0,0 +1,109 @@
+package homebrew
+
+//nolint:gochecknoglobals
+var (
+	formulaPlatforms = []Platform{MacOS, MacOSARM64, MacOSX64, LinuxARM64, LinuxX64}
+	caskPlatforms    = []Platform{MacOS, MacOSARM64, MacOSX64}
+)
+
+func formula(f *Formula, version string, assets map[Platform]*asset) []byte {
+	w := &rubyWriter{}
+	w.block("class "+className(f.Name)+" < Formula", func() {
+		w.stanza("desc", f.Description)
+		w.stanza("homepage", f.Homepage)
+		w.stanza("version", version)
+		w.stanza("license", f.License)
+
+		for _, os := range []struct {
+			name                string
+			universal, arm, x64 Platform
+		}{
+			{"on_macos", MacOS, MacOSARM64, MacOSX64},
+			{"on_linux", "", LinuxARM64, LinuxX64},
+		} {
+			if assets[os.universal] == nil && assets[os.arm] == nil && assets[os.x64] == nil {
+				continue
+			}
+			w.blank()
+			w.block(os.name+" do", func() {
+				download(w, assets[os.universal], assets[os.arm], assets[os.x64], func(a *asset) {
+					w.stanza("url", a.URL)
+					w.stanza("sha256", a.SHA256)
+				})
+			})
+		}
+
+		if len(f.Dependencies) > 0 {
+			w.blank()
+			for _, dep := range f.Dependencies {
+				w.stanza("depends_on", dep)
+			}
+		}
+
+		w.blank()
+		w.block("def install", func() {
+			if f.Install != "" {
+				w.lines(f.Install)
+				return
+			}
+			binaries := f.Binaries
+			if len(binaries) == 0 {
+				binaries = []string{f.Name}
+			}
+			for _, bin := range binaries {
+				w.stanza("bin.install", bin)
+			}
+		})
+
+		if f.Test != "" {
+			w.blank()
+			w.block("test do", func() { w.lines(f.Test) })
+		}
+	})
+	return w.Bytes()
+}
+
+func caskFile(c *Cask, version string, assets map[Platform]*asset) []byte {
+	w := &rubyWriter{}
+	w.block("cask "+quote(c.Name)+" do", func() {
+		w.stanza("version", version)
+		w.blank()
+
+		download(w, assets[MacOS], assets[MacOSARM64], assets[MacOSX64], func(a *asset) {
+			w.stanza("sha256", a.SHA256)
+			w.stanza("url", a.URL)
+		})
+
+		w.blank()
+		if c.DisplayName != "" {
+			w.stanza("name", c.DisplayName)
+		} else {
+			w.stanza("name", c.Name)
+		}
+		w.stanza("desc", c.Description)
+		w.stanza("homepage", c.Homepage)
+
+		if c.App != "" || len(c.Binaries) > 0 {
+			w.blank()
+			w.stanza("app", c.App)
+			for _, bin := range c.Binaries {
+				w.stanza("binary", bin)
+			}
+		}
+	})
+	return w.Bytes()
+}
+
+// download writes the stanzas for the universal asset, followed by on_arm and
+// on_intel blocks for the architecture-specific assets.
+func download(w *rubyWriter, universal, arm, x64 *asset, fn func(a *asset)) {
+	if universal != nil {
+		fn(universal)
+	}
+	if arm != nil {
+		w.block("on_arm do", func() { fn(arm) })
+	}
+	if x64 != nil {
+		w.block("on_intel do", func() { fn(x64) })
+	}
+}
@@ -0,0 +1,81 @@
+package homebrew
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rubyWriter writes indented Ruby code.
+type rubyWriter struct {
+	buf    bytes.Buffer
+	indent int
+	empty  bool // Whether a block was just opened, or a blank line written.
+}
+
+func (w *rubyWriter) line(s string) {
+	w.buf.WriteString(strings.Repeat("  ", w.indent) + s + "\n")
+	w.empty = false
+}
+
+// lines writes each line of a user-provided code snippet.
+func (w *rubyWriter) lines(s string) {
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			w.buf.WriteString("\n")
+		} else {
+			w.line(line)
+		}
+	}
+}
+
+// stanza writes a method call with a string argument, if not empty.
+func (w *rubyWriter) stanza(name, value string) {
+	if value != "" {
+		w.line(name + " " + quote(value))
+	}
+}
+
+// blank writes a blank line, unless at the start of a block or following
+// another blank line.
+func (w *rubyWriter) blank() {
+	if !w.empty {
+		w.buf.WriteString("\n")
+		w.empty = true
+	}
+}
+
+func (w *rubyWriter) block(start string, fn func()) {
+	w.line(start)
+	w.indent++
+	w.empty = true
+	fn()
+	w.indent--
+	w.line("end")
+}
+
+func (w *rubyWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// quote returns a double-quoted Ruby string literal, with interpolation
+// escaped.
+func quote(s string) string {
+	return strings.ReplaceAll(strconv.Quote(s), "#", `\#`)
+}
+
+//nolint:gochecknoglobals
+var (
+	reClassSeparator = regexp.MustCompile(`[-_.\s]([a-zA-Z0-9])`)
+	reClassVersion   = regexp.MustCompile(`(.)@(\d)`)
+)
+
+// className returns the Ruby class name for a formula name, the same way as
+// Homebrew does, e.g. 'MyApp' for 'my-app' or 'FooAT12' for 'foo@1.2'.
+func className(name string) string {
+	s := strings.ToUpper(name[:1]) + strings.ToLower(name[1:])
+	s = reClassSeparator.ReplaceAllStringFunc(s, func(m string) string { return strings.ToUpper(m[1:]) })
+	s = strings.ReplaceAll(s, "+", "x")
+	return reClassVersion.ReplaceAllString(s, "${1}AT${2}")
+}
@@ -0,0 +1,24 @@
+package homebrew_test
+
+import (
+	"testing"
+
+	"github.com/kubri/kubri/integrations/homebrew"
+)
+
+func TestClassName(t *testing.T) {
+	tests := map[string]string{
+		"foo":          "Foo",
+		"my-app":       "MyApp",
+		"my_app.cli":   "MyAppCli",
+		"foo@1.2":      "FooAT12",
+		"libfoo++":     "Libfooxx",
+		"Mixed-Case-1": "MixedCase1",
+	}
+
+	for in, want := range tests {
+		if got := homebrew.ClassName(in); got != want {
+			t.Errorf("%s: want %q got %q", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package scoop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubri/kubri/internal/assets"
+	"github.com/kubri/kubri/source"
+	"github.com/kubri/kubri/target"
+)
+
+type Config struct {
+	Apps []*App
+
+	Source         *source.Source
+	Target         target.Target
+	Version        string
+	Prerelease     bool
+	UploadPackages bool
+}
+
+type App struct {
+	Name        string
+	Description string
+	Homepage    string
+	License     string
+	Assets      map[Arch]string
+	Bin         []string
+	CheckVer    *CheckVer
+}
+
+type Arch string
+
+const (
+	X64   Arch = "64bit"
+	X86   Arch = "32bit"
+	ARM64 Arch = "arm64"
+)
+
+var (
+	ErrInvalidArch   = errors.New("invalid architecture")
+	ErrNoAssets      = assets.ErrNoAssets
+	ErrDuplicateName = assets.ErrDuplicateName
+)
+
+//nolint:gochecknoglobals
+var reGitHub = regexp.MustCompile(`^https://github\.com/[^/]+/[^/]+/`)
+
+// Build writes a Scoop manifest to <name>.json on the target for each app.
+func Build(ctx context.Context, c *Config) error {
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	releases, err := c.Source.ListReleases(ctx, &source.ListOptions{
+		Version:    c.Version,
+		Prerelease: c.Prerelease,
+	})
+	if err == source.ErrNoReleaseFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ac := &assets.Config{Source: c.Source, Target: c.Target, UploadPackages: c.UploadPackages}
+
+	for _, app := range c.Apps {
+		r, a, err := assets.Latest(ctx, ac, releases, app.Assets)
+		if errors.Is(err, assets.ErrNoMatchingReleases) {
+			log.Printf("Skipping %s without matching assets", app.Name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = write(ctx, c.Target, app.Name+".json", manifest(app, r, a)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validate(c *Config) error {
+	seen := map[string]bool{}
+	for _, app := range c.Apps {
+		if err := assets.Validate(seen, app.Name, app.Assets, []Arch{X64, X86, ARM64}, ErrInvalidArch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manifest returns the manifest for an app from the matching assets of a release.
+func manifest(app *App, r *source.Release, matches map[Arch]*assets.Asset) *Manifest {
+	m := &Manifest{
+		Version:      strings.TrimPrefix(r.Version, "v"),
+		Description:  app.Description,
+		Homepage:     app.Homepage,
+		License:      app.License,
+		Architecture: map[Arch]*Resource{},
+		Bin:          app.Bin,
+		CheckVer:     app.CheckVer,
+		AutoUpdate:   &AutoUpdate{Architecture: map[Arch]*Resource{}},
+	}
+
+	for _, arch := range slices.Sorted(maps.Keys(matches)) {
+		a := matches[arch]
+		m.Architecture[arch] = &Resource{URL: a.URL, Hash: a.SHA256}
+		m.AutoUpdate.Architecture[arch] = &Resource{URL: autoUpdateURL(a.URL, r.Version, m.Version)}
+
+		if m.CheckVer == nil {
+			if repo := reGitHub.FindString(a.URL); repo != "" {
+				m.CheckVer = &CheckVer{GitHub: strings.TrimSuffix(repo, "/")}
+			}
+		}
+	}
+
+	return m
+}
+
+// autoUpdateURL replaces the version with $version in the path segments of the
+// URL for the release tag and the file name, leaving the host and any other
+// segments such as the repository name unchanged.
+func autoUpdateURL(rawURL, tag, version string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, s := range segments {
+		if s == tag || i == len(segments)-1 {
+			segments[i] = strings.ReplaceAll(s, version, "$version")
+		}
+	}
+	u.Path = strings.Join(segments, "/")
+	return u.String()
+}
+
+func write(ctx context.Context, t target.Target, name string, m *Manifest) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	return assets.Write(ctx, t, name, buf.Bytes())
+}
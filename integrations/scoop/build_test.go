@@ -0,0 +1,296 @@
+package scoop_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/scoop"
+	"github.com/kubri/kubri/internal/testsource"
+	"github.com/kubri/kubri/source"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestBuild(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}, {Version: "v1.1.0"}})
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		for _, name := range []string{"app_" + version[1:] + "_windows_amd64.zip", "app_" + version[1:] + "_windows_arm64.zip"} {
+			src.UploadAsset(t.Context(), version, name, []byte(name))
+		}
+	}
+	src.UploadAsset(t.Context(), "v1.0.0", "app_1.0.0_windows_386.zip", []byte("app_1.0.0_windows_386.zip"))
+
+	sum := func(s string) string {
+		b := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(b[:])
+	}
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir()})
+
+	c := &scoop.Config{
+		Source: src,
+		Target: tgt,
+		Apps: []*scoop.App{
+			{
+				Name:        "app",
+				Description: "My app",
+				Homepage:    "https://example.com",
+				License:     "MIT",
+				Assets: map[scoop.Arch]string{
+					scoop.X64:   "*_windows_amd64.zip",
+					scoop.X86:   "*_windows_386.zip",
+					scoop.ARM64: "*_windows_arm64.zip",
+				},
+				Bin:      []string{"app.exe"},
+				CheckVer: &scoop.CheckVer{URL: "https://example.com/version.txt", Regex: `([\d.]+)`},
+			},
+			{
+				Name:   "app-cli",
+				Assets: map[scoop.Arch]string{scoop.X64: "*_windows_amd64.zip"},
+				Bin:    []string{"app.exe", "app-cli.exe"},
+			},
+			{
+				Name:   "missing",
+				Assets: map[scoop.Arch]string{scoop.X64: "*.msi"},
+			},
+		},
+	}
+
+	if err := scoop.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"app.json": `{
+    "version": "1.1.0",
+    "description": "My app",
+    "homepage": "https://example.com",
+    "license": "MIT",
+    "architecture": {
+        "64bit": {
+            "url": "https://example.com/v1.1.0/app_1.1.0_windows_amd64.zip",
+            "hash": "` + sum("app_1.1.0_windows_amd64.zip") + `"
+        },
+        "arm64": {
+            "url": "https://example.com/v1.1.0/app_1.1.0_windows_arm64.zip",
+            "hash": "` + sum("app_1.1.0_windows_arm64.zip") + `"
+        }
+    },
+    "bin": "app.exe",
+    "checkver": {
+        "url": "https://example.com/version.txt",
+        "regex": "([\\d.]+)"
+    },
+    "autoupdate": {
+        "architecture": {
+            "64bit": {
+                "url": "https://example.com/v$version/app_$version_windows_amd64.zip"
+            },
+            "arm64": {
+                "url": "https://example.com/v$version/app_$version_windows_arm64.zip"
+            }
+        }
+    }
+}
+`,
+		"app-cli.json": `{
+    "version": "1.1.0",
+    "architecture": {
+        "64bit": {
+            "url": "https://example.com/v1.1.0/app_1.1.0_windows_amd64.zip",
+            "hash": "` + sum("app_1.1.0_windows_amd64.zip") + `"
+        }
+    },
+    "bin": [
+        "app.exe",
+        "app-cli.exe"
+    ],
+    "autoupdate": {
+        "architecture": {
+            "64bit": {
+                "url": "https://example.com/v$version/app_$version_windows_amd64.zip"
+            }
+        }
+    }
+}
+`,
+	}
+
+	for name, want := range want {
+		r, err := c.Target.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(r)
+		r.Close()
+
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("%s:\n%s", name, diff)
+		}
+	}
+
+	if _, err := tgt.NewReader(t.Context(), "missing.json"); err == nil {
+		t.Error("should not create manifest without matching assets")
+	}
+}
+
+func TestBuildGitHub(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "app.zip", []byte("test"))
+	r, _ := src.GetRelease(t.Context(), "v1.0.0")
+	r.Assets[0].URL = "https://github.com/owner/repo/releases/download/v1.0.0/app.zip"
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir()})
+
+	c := &scoop.Config{
+		Source: src,
+		Target: tgt,
+		Apps:   []*scoop.App{{Name: "app", Assets: map[scoop.Arch]string{scoop.X64: "*.zip"}}},
+	}
+
+	if err := scoop.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readManifest(t, c, "app.json")
+
+	want := &scoop.CheckVer{GitHub: "https://github.com/owner/repo"}
+	if diff := cmp.Diff(want, got.CheckVer); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBuildAutoUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "version in host and repo",
+			url:  "https://1.0.0.example.com/app-1.0.0/releases/download/v1.0.0/app-1.0.0.zip",
+			want: "https://1.0.0.example.com/app-1.0.0/releases/download/v$version/app-$version.zip",
+		},
+		{
+			name: "escaped file name",
+			url:  "https://example.com/v1.0.0/My%20App%201.0.0.zip",
+			want: "https://example.com/v$version/My%20App%20$version.zip",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+			src.UploadAsset(t.Context(), "v1.0.0", "app.zip", []byte("test"))
+			r, _ := src.GetRelease(t.Context(), "v1.0.0")
+			r.Assets[0].URL = test.url
+
+			tgt, _ := target.New(target.Config{Path: t.TempDir()})
+
+			c := &scoop.Config{
+				Source: src,
+				Target: tgt,
+				Apps:   []*scoop.App{{Name: "app", Assets: map[scoop.Arch]string{scoop.X64: "*.zip"}}},
+			}
+
+			if err := scoop.Build(t.Context(), c); err != nil {
+				t.Fatal(err)
+			}
+
+			got := readManifest(t, c, "app.json")
+			if diff := cmp.Diff(test.want, got.AutoUpdate.Architecture[scoop.X64].URL); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestBuildUploadPackages(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "app_1.0.0.zip", []byte("test"))
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir(), URL: "https://dl.example.com"})
+
+	c := &scoop.Config{
+		Source:         src,
+		Target:         tgt,
+		UploadPackages: true,
+		Apps:           []*scoop.App{{Name: "app", Assets: map[scoop.Arch]string{scoop.X64: "*.zip"}}},
+	}
+
+	if err := scoop.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readManifest(t, c, "app.json")
+
+	if want := "https://dl.example.com/v1.0.0/app_1.0.0.zip"; got.Architecture[scoop.X64].URL != want {
+		t.Errorf("want url %s got %s", want, got.Architecture[scoop.X64].URL)
+	}
+	if want := "https://dl.example.com/v$version/app_$version.zip"; got.AutoUpdate.Architecture[scoop.X64].URL != want {
+		t.Errorf("want autoupdate url %s got %s", want, got.AutoUpdate.Architecture[scoop.X64].URL)
+	}
+	if _, err := tgt.NewReader(t.Context(), "v1.0.0/app_1.0.0.zip"); err != nil {
+		t.Errorf("should upload the package: %s", err)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+	tgt, _ := target.New(target.Config{Path: t.TempDir()})
+
+	tests := []struct {
+		name string
+		apps []*scoop.App
+		err  error
+	}{
+		{
+			name: "no assets",
+			apps: []*scoop.App{{Name: "app"}},
+			err:  scoop.ErrNoAssets,
+		},
+		{
+			name: "invalid architecture",
+			apps: []*scoop.App{{Name: "app", Assets: map[scoop.Arch]string{"x64": "*"}}},
+			err:  scoop.ErrInvalidArch,
+		},
+		{
+			name: "duplicate name",
+			apps: []*scoop.App{
+				{Name: "app", Assets: map[scoop.Arch]string{scoop.X64: "*"}},
+				{Name: "app", Assets: map[scoop.Arch]string{scoop.X86: "*"}},
+			},
+			err: scoop.ErrDuplicateName,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &scoop.Config{Source: src, Target: tgt, Apps: test.apps}
+			if err := scoop.Build(t.Context(), c); !errors.Is(err, test.err) {
+				t.Errorf("want %v got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func readManifest(t *testing.T, c *scoop.Config, name string) *scoop.Manifest {
+	t.Helper()
+
+	r, err := c.Target.NewReader(t.Context(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var m scoop.Manifest
+	if err = json.NewDecoder(r).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	return &m
+}
@@ -0,0 +1,51 @@
+package scoop
+
+import "encoding/json"
+
+// Manifest is a Scoop app manifest.
+// See https://github.com/ScoopInstaller/Scoop/wiki/App-Manifests
+type Manifest struct {
+	Version      string             `json:"version"`
+	Description  string             `json:"description,omitempty"`
+	Homepage     string             `json:"homepage,omitempty"`
+	License      string             `json:"license,omitempty"`
+	Architecture map[Arch]*Resource `json:"architecture,omitempty"`
+	Bin          Bin                `json:"bin,omitempty"`
+	CheckVer     *CheckVer          `json:"checkver,omitempty"`
+	AutoUpdate   *AutoUpdate        `json:"autoupdate,omitempty"`
+}
+
+type Resource struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+}
+
+type CheckVer struct {
+	GitHub   string `json:"github,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	JSONPath string `json:"jsonpath,omitempty"`
+}
+
+type AutoUpdate struct {
+	Architecture map[Arch]*Resource `json:"architecture"`
+}
+
+// Bin is a list of executables, marshalled as a string if there is only one.
+type Bin []string
+
+func (b Bin) MarshalJSON() ([]byte, error) {
+	if len(b) == 1 {
+		return json.Marshal(b[0])
+	}
+	return json.Marshal([]string(b))
+}
+
+func (b *Bin) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Bin{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(b))
+}
@@ -18,18 +18,54 @@ import (
 	"github.com/kubri/kubri/source"
 )
 
-// Build creates or updates a Sparkle RSS feed.
+// Build creates or updates the Sparkle RSS feeds.
 func Build(ctx context.Context, c *Config) error {
-	items := read(ctx, c)
+	feeds := c.Feeds
+	if len(feeds) == 0 {
+		feeds = []*Feed{{FileName: c.FileName}}
+	}
 
-	version := c.Version
-	if v := getVersionConstraint(items); v != "" {
-		version += "," + v
+	cache := newCache()
+	for _, feed := range feeds {
+		if err := buildFeed(ctx, c, cache, feed); err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// cache holds the work shared between feeds, so an asset or release matching
+// several feeds is only downloaded, signed and uploaded once.
+type cache struct {
+	assets map[string]*signedAsset
+	notes  map[string][]*ReleaseNotesLink
+}
+
+type signedAsset struct {
+	url    string
+	edSig  string
+	dsaSig string
+}
+
+func newCache() *cache {
+	return &cache{assets: map[string]*signedAsset{}, notes: map[string][]*ReleaseNotesLink{}}
+}
+
+func buildFeed(ctx context.Context, c *Config, cache *cache, feed *Feed) error {
+	items := read(ctx, c, feed.FileName)
+
+	var constraints []string
+	for _, v := range []string{c.Version, feed.Version, getVersionConstraint(items)} {
+		if v != "" {
+			constraints = append(constraints, v)
+		}
+	}
+	version := strings.Join(constraints, ",")
+
 	releases, err := c.Source.ListReleases(ctx, &source.ListOptions{
 		Version:    version,
-		Prerelease: c.Prerelease,
+		Prerelease: c.Prerelease || c.PrereleaseChannel != "",
 	})
 	if err == source.ErrNoReleaseFound {
 		return nil
@@ -38,13 +74,19 @@ func Build(ctx context.Context, c *Config) error {
 		return err
 	}
 
-	i, err := getItems(ctx, c, releases)
+	i, err := getItems(ctx, c, cache, feed, releases)
 	if err != nil {
 		return err
 	}
 	items = append(i, items...)
 
-	link, err := c.Target.URL(ctx, c.FileName)
+	if c.Deltas > 0 {
+		if err = addDeltas(ctx, c, releases, i, items); err != nil {
+			return err
+		}
+	}
+
+	link, err := c.Target.URL(ctx, feed.FileName)
 	if err != nil {
 		return err
 	}
@@ -56,20 +98,30 @@ func Build(ctx context.Context, c *Config) error {
 		Items:       items,
 	}}}
 
-	return write(ctx, c, rss)
+	return write(ctx, c, feed.FileName, rss)
 }
 
-func read(ctx context.Context, c *Config) []*Item {
-	r, err := c.Target.NewReader(ctx, c.FileName)
-	if err != nil {
+func read(ctx context.Context, c *Config, name string) []*Item {
+	rss, err := readRSS(ctx, c, name)
+	if err != nil || len(rss.Channels) == 0 {
 		return nil
 	}
+	return rss.Channels[0].Items
+}
+
+func readRSS(ctx context.Context, c *Config, name string) (*RSS, error) {
+	r, err := c.Target.NewReader(ctx, name)
+	if err != nil {
+		return nil, err
+	}
 	defer r.Close()
+
 	var rss RSS
-	if err = xml.NewDecoder(r).Decode(&rss); err != nil || len(rss.Channels) == 0 {
-		return nil
+	if err = xml.NewDecoder(r).Decode(&rss); err != nil {
+		return nil, err
 	}
-	return rss.Channels[0].Items
+
+	return &rss, nil
 }
 
 func getVersionConstraint(items []*Item) string {
@@ -87,10 +139,16 @@ func getVersionConstraint(items []*Item) string {
 	return unsafe.String(unsafe.SliceData(v), len(v)-1)
 }
 
-func getItems(ctx context.Context, c *Config, releases []*source.Release) ([]*Item, error) {
+func getItems(
+	ctx context.Context,
+	c *Config,
+	cache *cache,
+	feed *Feed,
+	releases []*source.Release,
+) ([]*Item, error) {
 	var items []*Item
 	for _, release := range releases {
-		item, err := getReleaseItems(ctx, c, release)
+		item, err := getReleaseItems(ctx, c, cache, feed, release)
 		if err != nil {
 			return nil, err
 		}
@@ -100,9 +158,9 @@ func getItems(ctx context.Context, c *Config, releases []*source.Release) ([]*It
 }
 
 //nolint:funlen
-func getReleaseItems(ctx context.Context, c *Config, release *source.Release) ([]*Item, error) {
+func getReleaseItems(ctx context.Context, c *Config, cache *cache, feed *Feed, release *source.Release) ([]*Item, error) {
 	var description *CdataString
-	if release.Description != "" {
+	if release.Description != "" && c.ReleaseNotes == nil {
 		desc := string(blackfriday.Run([]byte(release.Description)))
 		desc = strings.TrimSpace(xmlfmt.FormatXML(desc, "\t\t\t\t", "\t"))
 		description = &CdataString{Value: "\n\t\t\t\t" + desc + "\n\t\t\t"}
@@ -115,7 +173,7 @@ func getReleaseItems(ctx context.Context, c *Config, release *source.Release) ([
 			detect = detectOS
 		}
 		os := detect(asset.Name)
-		if os == Unknown {
+		if os == Unknown || !feed.match(os, asset.Name) {
 			continue
 		}
 
@@ -124,29 +182,18 @@ func getReleaseItems(ctx context.Context, c *Config, release *source.Release) ([
 			return nil, err
 		}
 
-		var b []byte
-		if c.Ed25519Key != nil || c.DSAKey != nil || c.UploadPackages {
-			b, err = c.Source.DownloadAsset(ctx, release.Version, asset.Name)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		edSig, dsaSig, err := signAsset(c, os, b)
+		a, err := getSignedAsset(ctx, c, cache, release, asset, os)
 		if err != nil {
 			return nil, err
 		}
 
-		url := asset.URL
-		if c.UploadPackages {
-			url, err = uploadAsset(ctx, c, release.Version+"/"+asset.Name, b)
-			if err != nil {
-				return nil, err
-			}
-		}
-
 		version := strings.TrimPrefix(release.Version, "v")
 
+		channel := opt.Channel
+		if channel == "" && release.Prerelease && !c.Prerelease {
+			channel = c.PrereleaseChannel
+		}
+
 		items = append(items, &Item{
 			Title:                             release.Name,
 			PubDate:                           release.Date.UTC().Format(time.RFC1123),
@@ -156,23 +203,83 @@ func getReleaseItems(ctx context.Context, c *Config, release *source.Release) ([
 			Tags:                              getTags(opt.CriticalUpdate),
 			IgnoreSkippedUpgradesBelowVersion: opt.IgnoreSkippedUpgradesBelowVersion,
 			MinimumAutoupdateVersion:          opt.MinimumAutoupdateVersion,
+			Channel:                           channel,
+			PhasedRolloutInterval:             opt.PhasedRolloutInterval,
 			Enclosure: &Enclosure{
 				Version:              version,
-				URL:                  url,
+				URL:                  a.url,
 				InstallerArguments:   opt.InstallerArguments,
 				MinimumSystemVersion: opt.MinimumSystemVersion,
 				Type:                 getFileType(asset.Name),
 				OS:                   os.String(),
 				Length:               asset.Size,
-				DSASignature:         dsaSig,
-				EDSignature:          edSig,
+				DSASignature:         a.dsaSig,
+				EDSignature:          a.edSig,
 			},
 		})
 	}
 
+	if c.ReleaseNotes != nil && len(items) > 0 {
+		links, ok := cache.notes[release.Version]
+		if !ok {
+			var err error
+			links, err = getReleaseNotes(ctx, c, release)
+			if err != nil {
+				return nil, err
+			}
+			cache.notes[release.Version] = links
+		}
+		for _, item := range items {
+			item.ReleaseNotesLink = links
+			item.FullReleaseNotesLink = c.ReleaseNotes.FullURL
+		}
+	}
+
 	return items, nil
 }
 
+// getSignedAsset returns the URL and signatures of an asset, downloading,
+// signing and uploading it if it hasn't already been done for another feed.
+func getSignedAsset(
+	ctx context.Context,
+	c *Config,
+	cache *cache,
+	release *source.Release,
+	asset *source.Asset,
+	os OS,
+) (*signedAsset, error) {
+	key := release.Version + "/" + asset.Name
+	if a, ok := cache.assets[key]; ok {
+		return a, nil
+	}
+
+	var b []byte
+	if c.Ed25519Key != nil || c.DSAKey != nil || c.UploadPackages {
+		var err error
+		b, err = c.Source.DownloadAsset(ctx, release.Version, asset.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	edSig, dsaSig, err := signAsset(c, os, b)
+	if err != nil {
+		return nil, err
+	}
+
+	url := asset.URL
+	if c.UploadPackages {
+		url, err = uploadAsset(ctx, c, key, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a := &signedAsset{url: url, edSig: edSig, dsaSig: dsaSig}
+	cache.assets[key] = a
+	return a, nil
+}
+
 //nolint:nonamedreturns
 func signAsset(c *Config, os OS, b []byte) (edSig, dsaSig string, err error) {
 	if c.Ed25519Key != nil {
@@ -234,6 +341,12 @@ func getFileType(s string) string {
 		return "application/x-msi"
 	case ".exe":
 		return "application/vnd.microsoft.portable-executable"
+	case ".AppImage", ".appimage":
+		return "application/vnd.appimage"
+	case ".deb":
+		return "application/vnd.debian.binary-package"
+	case ".gz", ".tgz":
+		return "application/gzip"
 
 	// See https://learn.microsoft.com/en-us/windows/msix/app-installer/web-install-iis#step-7---configure-the-web-app-for-app-package-mime-types
 	case ".msix", ".msixbundle", ".appx", ".appxbundle", ".appinstaller":
@@ -244,8 +357,8 @@ func getFileType(s string) string {
 //nolint:gochecknoglobals
 var replacer = strings.NewReplacer("></sparkle:criticalUpdate>", " />", "></enclosure>", " />")
 
-func write(ctx context.Context, c *Config, rss *RSS) error {
-	w, err := c.Target.NewWriter(ctx, c.FileName)
+func write(ctx context.Context, c *Config, name string, rss *RSS) error {
+	w, err := c.Target.NewWriter(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -1,10 +1,16 @@
 package sparkle_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/xml"
 	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -442,3 +448,479 @@ func TestBuildUpload(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildDeltas(t *testing.T) {
+	ts := time.Now().UTC()
+	src := testsource.New([]*source.Release{
+		{Version: "v1.0.0", Date: ts},
+		{Version: "v1.1.0", Date: ts},
+		{Version: "v1.2.0", Date: ts},
+	})
+	src.UploadAsset(t.Context(), "v1.0.0", "Test-1.0.0.zip", newZip(t, "1.0.0"))
+	src.UploadAsset(t.Context(), "v1.1.0", "Test-1.1.0.zip", newZip(t, "1.1.0"))
+	src.UploadAsset(t.Context(), "v1.2.0", "Test-1.2.0.zip", newZip(t, "1.2.0"))
+	src.UploadAsset(t.Context(), "v1.2.0", "Test-1.2.0.msi", []byte("test"))
+
+	tgt, err := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edKey, err := ed25519.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &sparkle.Config{
+		Title:       "Test",
+		Description: "Test",
+		URL:         "https://example.com/appcast.xml",
+		Source:      src,
+		Target:      tgt,
+		FileName:    "appcast.xml",
+		Ed25519Key:  edKey,
+		Deltas:      2,
+		DetectOS: func(name string) sparkle.OS {
+			if path.Ext(name) == ".zip" {
+				return sparkle.MacOS
+			}
+			return sparkle.DetectOS(name)
+		},
+	}
+
+	if err = sparkle.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := tgt.NewReader(t.Context(), "appcast.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var rss sparkle.RSS
+	if err = xml.NewDecoder(r).Decode(&rss); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"https://example.com/v1.2.0/Test-1.2.0.zip": {"1.1.0", "1.0.0"},
+		"https://example.com/v1.2.0/Test-1.2.0.msi": nil,
+		"https://example.com/v1.1.0/Test-1.1.0.zip": {"1.0.0"},
+		"https://example.com/v1.0.0/Test-1.0.0.zip": nil,
+	}
+
+	got := map[string][]string{}
+	for _, item := range rss.Channels[0].Items {
+		got[item.Enclosure.URL] = nil
+		if item.Deltas == nil {
+			continue
+		}
+		for _, d := range item.Deltas.Enclosures {
+			got[item.Enclosure.URL] = append(got[item.Enclosure.URL], d.DeltaFrom)
+
+			name := "v" + item.Version + "/Test-" + item.Version + "-" + d.DeltaFrom + ".delta"
+			if d.URL != "https://example.com/"+name {
+				t.Errorf("unexpected url: %s", d.URL)
+			}
+
+			rd, err := tgt.NewReader(t.Context(), name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(rd)
+			rd.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d.Length != len(b) || !bytes.HasPrefix(b, []byte("xar!")) {
+				t.Errorf("invalid delta %s", name)
+			}
+			sig, _ := base64.StdEncoding.DecodeString(d.EDSignature)
+			if !ed25519.Verify(ed25519.Public(edKey), b, sig) {
+				t.Errorf("invalid signature for %s", name)
+			}
+		}
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// TestBuildDeltasExistingFeed tests deltas are created from previous versions
+// only found in the existing feed.
+func TestBuildDeltasExistingFeed(t *testing.T) {
+	ts := time.Now().UTC()
+	src := testsource.New([]*source.Release{
+		{Version: "v1.0.0", Date: ts},
+		{Version: "v1.1.0", Date: ts},
+	})
+	src.UploadAsset(t.Context(), "v1.0.0", "Test-1.0.0.zip", newZip(t, "1.0.0"))
+	src.UploadAsset(t.Context(), "v1.1.0", "Test-1.1.0.zip", newZip(t, "1.1.0"))
+
+	tgt, err := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &sparkle.Config{
+		Title:       "Test",
+		Description: "Test",
+		URL:         "https://example.com/appcast.xml",
+		Source:      src,
+		Target:      tgt,
+		FileName:    "appcast.xml",
+		Deltas:      1,
+		DetectOS:    func(string) sparkle.OS { return sparkle.MacOS },
+	}
+
+	c.Version = "<1.1"
+	if err = sparkle.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+	c.Version = ""
+	if err = sparkle.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := tgt.NewReader(t.Context(), "appcast.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var rss sparkle.RSS
+	if err = xml.NewDecoder(r).Decode(&rss); err != nil {
+		t.Fatal(err)
+	}
+
+	item := rss.Channels[0].Items[0]
+	if item.Deltas == nil || len(item.Deltas.Enclosures) != 1 {
+		t.Fatalf("want 1 delta for %s", item.Version)
+	}
+	if url := item.Deltas.Enclosures[0].URL; url != "https://example.com/v1.1.0/Test-1.1.0-1.0.0.delta" {
+		t.Errorf("unexpected url: %s", url)
+	}
+}
+
+func newZip(t *testing.T, version string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"Test.app/Contents/Info.plist": "<string>" + version + "</string>",
+		"Test.app/Contents/MacOS/Test": strings.Repeat("binary", 100) + version,
+	}
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBuildChannels(t *testing.T) {
+	data := []byte("test")
+	ts := time.Now().UTC()
+	src := testsource.New([]*source.Release{
+		{Version: "v1.0.0", Date: ts},
+		{Version: "v1.1.0-beta.1", Date: ts},
+	})
+	src.UploadAsset(t.Context(), "v1.0.0", "test.dmg", data)
+	src.UploadAsset(t.Context(), "v1.1.0-beta.1", "test.dmg", data)
+
+	tgt, err := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &sparkle.Config{
+		Title:             "Test",
+		Description:       "Test",
+		URL:               "https://example.com/appcast.xml",
+		Source:            src,
+		Target:            tgt,
+		FileName:          "appcast.xml",
+		PrereleaseChannel: "beta",
+		Settings: []sparkle.Rule{
+			{
+				Version: "v1.0.0",
+				Settings: &sparkle.Settings{
+					PhasedRolloutInterval: 86400,
+				},
+			},
+		},
+	}
+
+	if err = sparkle.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := tgt.NewReader(t.Context(), "appcast.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var got sparkle.RSS
+	if err = xml.NewDecoder(r).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	pubDate := ts.Format(time.RFC1123)
+
+	want := []*sparkle.Item{
+		{
+			Title:   "v1.1.0-beta.1",
+			PubDate: pubDate,
+			Version: "1.1.0-beta.1",
+			Channel: "beta",
+			Enclosure: &sparkle.Enclosure{
+				URL:     "https://example.com/v1.1.0-beta.1/test.dmg",
+				OS:      "macos",
+				Version: "1.1.0-beta.1",
+				Length:  4,
+				Type:    "application/x-apple-diskimage",
+			},
+		},
+		{
+			Title:                 "v1.0.0",
+			PubDate:               pubDate,
+			Version:               "1.0.0",
+			PhasedRolloutInterval: 86400,
+			Enclosure: &sparkle.Enclosure{
+				URL:     "https://example.com/v1.0.0/test.dmg",
+				OS:      "macos",
+				Version: "1.0.0",
+				Length:  4,
+				Type:    "application/x-apple-diskimage",
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got.Channels[0].Items); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBuildFeeds(t *testing.T) {
+	data := []byte("test")
+	ts := time.Now().UTC()
+	src, downloads := testsource.NewCounting([]*source.Release{
+		{Version: "v1.0.0", Date: ts},
+		{Version: "v1.1.0", Date: ts},
+	})
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		src.UploadAsset(t.Context(), version, "test.dmg", data)
+		src.UploadAsset(t.Context(), version, "test_arm64.dmg", data)
+		src.UploadAsset(t.Context(), version, "test_32-bit.exe", data)
+		src.UploadAsset(t.Context(), version, "test_64-bit.msi", data)
+		src.UploadAsset(t.Context(), version, "test.AppImage", data)
+		src.UploadAsset(t.Context(), version, "test.deb", data)
+		src.UploadAsset(t.Context(), version, "release-notes.de.md", []byte("# Test DE"))
+	}
+
+	tgt, err := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing items should only be kept in their own feed.
+	w, err := tgt.NewWriter(t.Context(), "windows-x64.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:sparkle="http://www.andymatuschak.org/xml-namespaces/sparkle" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel>
+		<item>
+			<title>v1.0.0</title>
+			<pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate>
+			<sparkle:version>1.0.0</sparkle:version>
+			<enclosure url="https://example.com/v1.0.0/test_64-bit.msi" sparkle:os="windows-x64" sparkle:version="1.0.0" length="4" type="application/x-msi" />
+		</item>
+	</channel>
+</rss>`))
+	w.Close()
+
+	c := &sparkle.Config{
+		Title:       "Test",
+		Description: "Test",
+		Source:      src,
+		Target:      tgt,
+		FileName:    "appcast.xml",
+		Feeds: []*sparkle.Feed{
+			{FileName: "macos.xml", OS: sparkle.MacOS},
+			{FileName: "macos-arm64.xml", OS: sparkle.MacOS, Assets: "*_arm64.dmg"},
+			{FileName: "windows-x64.xml", OS: sparkle.Windows64},
+			{FileName: "windows-x86.xml", OS: sparkle.Windows32, Version: ">= v1.1"},
+			{FileName: "linux.xml", OS: sparkle.Linux, Assets: "*.AppImage", Version: ">= v1.1"},
+		},
+		ReleaseNotes:   &sparkle.ReleaseNotes{Folder: "notes"},
+		UploadPackages: true,
+	}
+
+	if err = sparkle.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"macos.xml": {
+			"https://example.com/v1.1.0/test.dmg",
+			"https://example.com/v1.1.0/test_arm64.dmg",
+			"https://example.com/v1.0.0/test.dmg",
+			"https://example.com/v1.0.0/test_arm64.dmg",
+		},
+		"macos-arm64.xml": {
+			"https://example.com/v1.1.0/test_arm64.dmg",
+			"https://example.com/v1.0.0/test_arm64.dmg",
+		},
+		"windows-x64.xml": {
+			"https://example.com/v1.1.0/test_64-bit.msi",
+			"https://example.com/v1.0.0/test_64-bit.msi",
+		},
+		"windows-x86.xml": {
+			"https://example.com/v1.1.0/test_32-bit.exe",
+		},
+		"linux.xml": {
+			"https://example.com/v1.1.0/test.AppImage",
+		},
+	}
+
+	for name, urls := range want {
+		r, err := tgt.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var rss sparkle.RSS
+		err = xml.NewDecoder(r).Decode(&rss)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if link := "https://example.com/" + name; rss.Channels[0].Link != link {
+			t.Errorf("%s: want link %q got %q", name, link, rss.Channels[0].Link)
+		}
+
+		var got []string
+		for _, item := range rss.Channels[0].Items {
+			got = append(got, item.Enclosure.URL)
+		}
+		if diff := cmp.Diff(urls, got); diff != "" {
+			t.Errorf("%s:\n%s", name, diff)
+		}
+	}
+
+	if _, err = tgt.NewReader(t.Context(), "appcast.xml"); err == nil {
+		t.Error("should not write default feed")
+	}
+
+	// Assets and release notes shared by several feeds are only processed once.
+	for name, n := range downloads {
+		if n > 1 {
+			t.Errorf("%s: downloaded %d times", name, n)
+		}
+	}
+	if n := downloads["v1.0.0/release-notes.de.md"]; n != 1 {
+		t.Errorf("release notes: want 1 download got %d", n)
+	}
+}
+
+func TestBuildReleaseNotes(t *testing.T) {
+	data := []byte("test")
+	ts := time.Now().UTC()
+	src := testsource.New([]*source.Release{{Version: "v1.0.0", Date: ts, Description: "# Test"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "test.dmg", data)
+	src.UploadAsset(t.Context(), "v1.0.0", "release-notes.de.md", []byte("# Test DE"))
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "1.0.0.fr.html"), []byte("<h1>Test FR</h1>"), 0o600)
+	os.WriteFile(filepath.Join(dir, "1.1.0.html"), []byte("<h1>Ignored</h1>"), 0o600)
+
+	tgt, err := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &sparkle.Config{
+		Title:       "Test",
+		Description: "Test",
+		Source:      src,
+		Target:      tgt,
+		FileName:    "appcast.xml",
+		ReleaseNotes: &sparkle.ReleaseNotes{
+			Folder:  "notes",
+			Dir:     dir,
+			FullURL: "https://example.com/changelog.html",
+		},
+	}
+
+	if err = sparkle.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := tgt.NewReader(t.Context(), "appcast.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var rss sparkle.RSS
+	if err = xml.NewDecoder(r).Decode(&rss); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &sparkle.Item{
+		Title:   "v1.0.0",
+		PubDate: ts.Format(time.RFC1123),
+		Version: "1.0.0",
+		ReleaseNotesLink: []*sparkle.ReleaseNotesLink{
+			{URL: "https://example.com/notes/1.0.0.html"},
+			{Lang: "de", URL: "https://example.com/notes/1.0.0.de.html"},
+			{Lang: "fr", URL: "https://example.com/notes/1.0.0.fr.html"},
+		},
+		FullReleaseNotesLink: "https://example.com/changelog.html",
+		Enclosure: &sparkle.Enclosure{
+			URL:     "https://example.com/v1.0.0/test.dmg",
+			OS:      "macos",
+			Version: "1.0.0",
+			Length:  4,
+			Type:    "application/x-apple-diskimage",
+		},
+	}
+
+	if diff := cmp.Diff([]*sparkle.Item{want}, rss.Channels[0].Items); diff != "" {
+		t.Error(diff)
+	}
+
+	notes := map[string]string{
+		"notes/1.0.0.html":    "<h1>Test</h1>",
+		"notes/1.0.0.de.html": "<h1>Test DE</h1>",
+		"notes/1.0.0.fr.html": "<h1>Test FR</h1>",
+	}
+
+	for name, want := range notes {
+		r, err := tgt.NewReader(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%s should contain %q:\n%s", name, want, b)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package sparkle
 
 import (
+	"path"
+
 	"dario.cat/mergo"
 
 	"github.com/kubri/kubri/pkg/crypto/dsa"
@@ -11,14 +13,23 @@ import (
 )
 
 type Config struct {
-	Title       string
-	Description string
-	URL         string
-	FileName    string
-	DetectOS    func(string) OS
-	DSAKey      *dsa.PrivateKey
-	Ed25519Key  ed25519.PrivateKey
-	Settings    []Rule
+	Title             string
+	Description       string
+	URL               string
+	FileName          string
+	DetectOS          func(string) OS
+	DSAKey            *dsa.PrivateKey
+	Ed25519Key        ed25519.PrivateKey
+	Settings          []Rule
+	Deltas            int
+	PrereleaseChannel string
+	Feeds             []*Feed
+	ReleaseNotes      *ReleaseNotes
+
+	// DSAPublicKey and Ed25519PublicKey are used by Verify. If unset, they're
+	// derived from the private keys.
+	DSAPublicKey     *dsa.PublicKey
+	Ed25519PublicKey ed25519.PublicKey
 
 	Source         *source.Source
 	Target         target.Target
@@ -27,6 +38,36 @@ type Config struct {
 	UploadPackages bool
 }
 
+// Feed is an appcast containing the assets matching OS and Assets, a glob of
+// the asset file name. If unset, all assets are included.
+type Feed struct {
+	FileName string
+	OS       OS
+	Assets   string
+	Version  string
+}
+
+func (f *Feed) match(os OS, name string) bool {
+	if !isOS(os, f.OS) {
+		return false
+	}
+	if f.Assets != "" {
+		ok, _ := path.Match(f.Assets, name)
+		return ok
+	}
+	return true
+}
+
+// ReleaseNotes writes release notes to separate HTML files in Folder instead of
+// embedding them in the feed. Localised notes are read from release assets
+// named release-notes.<lang>.md or .html, and from files named <version>.md,
+// <version>.<lang>.md or .html in Dir.
+type ReleaseNotes struct {
+	Folder  string
+	Dir     string
+	FullURL string
+}
+
 type Rule struct {
 	*Settings
 
@@ -41,6 +82,8 @@ type Settings struct {
 	IgnoreSkippedUpgradesBelowVersion string
 	CriticalUpdate                    bool
 	CriticalUpdateBelowVersion        string
+	Channel                           string
+	PhasedRolloutInterval             int
 }
 
 func getSettings(settings []Rule, v string, os OS) (*Settings, error) {
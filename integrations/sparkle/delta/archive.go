@@ -0,0 +1,176 @@
+package delta
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/ulikunitz/xz"
+)
+
+var (
+	ErrUnsupportedArchive = errors.New("unsupported archive")
+	ErrNoBundle           = errors.New("no app bundle found")
+)
+
+// IsArchive reports whether an app bundle can be extracted from the file.
+func IsArchive(name string) bool {
+	return archiveType(name) != ""
+}
+
+func archiveType(name string) string {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz"} {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// Extract returns the first app bundle in a zip or tar archive.
+func Extract(name string, b []byte) (Tree, error) {
+	var (
+		files []*archiveFile
+		err   error
+	)
+
+	switch ext := archiveType(name); ext {
+	case "":
+		return nil, ErrUnsupportedArchive
+	case ".zip":
+		files, err = readZip(b)
+	default:
+		var r io.Reader = bytes.NewReader(b)
+		switch ext {
+		case ".tar.gz", ".tgz":
+			r, err = gzip.NewReader(r)
+		case ".tar.bz2", ".tbz2":
+			r, err = bzip2.NewReader(r, nil)
+		case ".tar.xz", ".txz":
+			r, err = xz.NewReader(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+		files, err = readTar(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle(files)
+}
+
+type archiveFile struct {
+	name string
+	File
+}
+
+func readZip(b []byte) ([]*archiveFile, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*archiveFile, 0, len(zr.File))
+	for _, zf := range zr.File {
+		f := &archiveFile{name: zf.Name, File: File{Mode: zf.Mode()}}
+		if !f.Mode.IsDir() {
+			rc, err := zf.Open()
+			if err != nil {
+				return nil, err
+			}
+			f.Data, err = io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+func readTar(r io.Reader) ([]*archiveFile, error) {
+	var files []*archiveFile
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		f := &archiveFile{name: hdr.Name, File: File{Mode: hdr.FileInfo().Mode()}}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+		case tar.TypeSymlink:
+			f.Data = []byte(hdr.Linkname)
+		case tar.TypeReg:
+			if f.Data, err = io.ReadAll(tr); err != nil {
+				return nil, err
+			}
+		case tar.TypeLink:
+			// Hard links are stored as copies.
+			for _, t := range files {
+				if path.Clean(t.name) == path.Clean(hdr.Linkname) {
+					f.Mode, f.Data = t.Mode, t.Data
+				}
+			}
+		default:
+			continue
+		}
+		files = append(files, f)
+	}
+}
+
+// bundle returns the files in the first .app directory, adding any missing
+// parent directories.
+func bundle(files []*archiveFile) (Tree, error) {
+	var root string
+	tree := Tree{}
+	for _, f := range files {
+		name := path.Clean(strings.TrimPrefix(f.name, "./"))
+		if root == "" {
+			for dir := name; dir != "."; dir = path.Dir(dir) {
+				if strings.HasSuffix(dir, ".app") {
+					root = dir
+				}
+			}
+			if root == "" {
+				continue
+			}
+		}
+
+		name, ok := strings.CutPrefix(name, root+"/")
+		if !ok {
+			continue
+		}
+
+		file := f.File
+		tree[name] = &file
+
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if _, ok := tree[dir]; !ok {
+				tree[dir] = &File{Mode: fs.ModeDir | 0o755}
+			}
+		}
+	}
+
+	if root == "" {
+		return nil, ErrNoBundle
+	}
+
+	return tree, nil
+}
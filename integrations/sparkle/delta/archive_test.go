@@ -0,0 +1,105 @@
+package delta_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/sparkle/delta"
+)
+
+func TestExtract(t *testing.T) {
+	want := delta.Tree{
+		"Contents":            {Mode: fs.ModeDir | 0o755},
+		"Contents/Info.plist": {Mode: 0o644, Data: []byte("plist")},
+		"Contents/MacOS":      {Mode: fs.ModeDir | 0o755},
+		"Contents/MacOS/Test": {Mode: 0o755, Data: []byte("binary")},
+		"Contents/Current":    {Mode: fs.ModeSymlink | 0o777, Data: []byte("MacOS")},
+	}
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for _, f := range []struct {
+		name string
+		mode fs.FileMode
+		data string
+	}{
+		{"README.md", 0o644, "readme"},
+		{"Test.app/", fs.ModeDir | 0o755, ""},
+		{"Test.app/Contents/Info.plist", 0o644, "plist"},
+		{"Test.app/Contents/MacOS/Test", 0o755, "binary"},
+		{"Test.app/Contents/Current", fs.ModeSymlink | 0o777, "MacOS"},
+	} {
+		hdr := &zip.FileHeader{Name: f.name}
+		hdr.SetMode(f.mode)
+		w, _ := zw.CreateHeader(hdr)
+		w.Write([]byte(f.data))
+	}
+	zw.Close()
+
+	var tarBuf bytes.Buffer
+	gw := gzip.NewWriter(&tarBuf)
+	tw := tar.NewWriter(gw)
+	for _, hdr := range []*tar.Header{
+		{Name: "./Test.app/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "./Test.app/Contents/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "./Test.app/Contents/Info.plist", Typeflag: tar.TypeReg, Mode: 0o644, Size: 5},
+		{Name: "./Test.app/Contents/MacOS/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "./Test.app/Contents/MacOS/Test", Typeflag: tar.TypeReg, Mode: 0o755, Size: 6},
+		{Name: "./Test.app/Contents/Current", Typeflag: tar.TypeSymlink, Mode: 0o777, Linkname: "MacOS"},
+	} {
+		tw.WriteHeader(hdr)
+		switch hdr.Size {
+		case 5:
+			tw.Write([]byte("plist"))
+		case 6:
+			tw.Write([]byte("binary"))
+		}
+	}
+	tw.Close()
+	gw.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Test.zip", zipBuf.Bytes()},
+		{"Test.tar.gz", tarBuf.Bytes()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !delta.IsArchive(test.name) {
+				t.Fatal("should be an archive")
+			}
+
+			got, err := delta.Extract(test.name, test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, err := delta.Extract("Test.dmg", nil); !errors.Is(err, delta.ErrUnsupportedArchive) {
+			t.Errorf("want %v got %v", delta.ErrUnsupportedArchive, err)
+		}
+
+		var b bytes.Buffer
+		zw := zip.NewWriter(&b)
+		zw.Create("README.md")
+		zw.Close()
+		if _, err := delta.Extract("Test.zip", b.Bytes()); !errors.Is(err, delta.ErrNoBundle) {
+			t.Errorf("want %v got %v", delta.ErrNoBundle, err)
+		}
+	})
+}
@@ -0,0 +1,322 @@
+package delta
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/dsnet/compress/bzip2"
+)
+
+// bsdiff returns a BSDIFF40 patch to turn src into dst.
+//
+// This is a port of bsdiff 4.3 by Colin Percival, which Sparkle uses to create
+// and apply binary deltas. See https://www.daemonology.net/bsdiff/
+//
+//nolint:funlen,gocognit,cyclop
+func bsdiff(src, dst []byte) ([]byte, error) {
+	sa := qsufsort(src)
+
+	var ctrl, diff, extra bytes.Buffer
+	var buf [24]byte
+
+	var scan, pos, length int
+	var lastScan, lastPos, lastOffset int
+	for scan < len(dst) {
+		var oldScore int
+
+		scan += length
+		for scsc := scan; scan < len(dst); scan++ {
+			length, pos = search(sa, src, dst[scan:], 0, len(src))
+
+			for ; scsc < scan+length; scsc++ {
+				if scsc+lastOffset < len(src) && src[scsc+lastOffset] == dst[scsc] {
+					oldScore++
+				}
+			}
+
+			if (length == oldScore && length != 0) || length > oldScore+8 {
+				break
+			}
+
+			if scan+lastOffset < len(src) && src[scan+lastOffset] == dst[scan] {
+				oldScore--
+			}
+		}
+
+		if length == oldScore && scan != len(dst) {
+			continue
+		}
+
+		// Extend the previous match forwards.
+		var lenF int
+		for i, s, sf := 0, 0, 0; lastScan+i < scan && lastPos+i < len(src); {
+			if src[lastPos+i] == dst[lastScan+i] {
+				s++
+			}
+			i++
+			if s*2-i > sf*2-lenF {
+				sf, lenF = s, i
+			}
+		}
+
+		// Extend the current match backwards.
+		var lenB int
+		if scan < len(dst) {
+			for i, s, sb := 1, 0, 0; scan >= lastScan+i && pos >= i; i++ {
+				if src[pos-i] == dst[scan-i] {
+					s++
+				}
+				if s*2-i > sb*2-lenB {
+					sb, lenB = s, i
+				}
+			}
+		}
+
+		// Resolve the overlap between both extensions.
+		if lastScan+lenF > scan-lenB {
+			overlap := (lastScan + lenF) - (scan - lenB)
+			var s, ss, lenS int
+			for i := range overlap {
+				if dst[lastScan+lenF-overlap+i] == src[lastPos+lenF-overlap+i] {
+					s++
+				}
+				if dst[scan-lenB+i] == src[pos-lenB+i] {
+					s--
+				}
+				if s > ss {
+					ss, lenS = s, i+1
+				}
+			}
+			lenF += lenS - overlap
+			lenB -= lenS
+		}
+
+		for i := range lenF {
+			diff.WriteByte(dst[lastScan+i] - src[lastPos+i])
+		}
+		extra.Write(dst[lastScan+lenF : scan-lenB])
+
+		putOffset(buf[0:], lenF)
+		putOffset(buf[8:], (scan-lenB)-(lastScan+lenF))
+		putOffset(buf[16:], (pos-lenB)-(lastPos+lenF))
+		ctrl.Write(buf[:])
+
+		lastScan = scan - lenB
+		lastPos = pos - lenB
+		lastOffset = pos - scan
+	}
+
+	blocks := make([][]byte, 3)
+	for i, b := range [][]byte{ctrl.Bytes(), diff.Bytes(), extra.Bytes()} {
+		var err error
+		if blocks[i], err = compressBzip2(b); err != nil {
+			return nil, err
+		}
+	}
+
+	patch := make([]byte, 32, 32+len(blocks[0])+len(blocks[1])+len(blocks[2]))
+	copy(patch, "BSDIFF40")
+	putOffset(patch[8:], len(blocks[0]))
+	putOffset(patch[16:], len(blocks[1]))
+	putOffset(patch[24:], len(dst))
+	for _, b := range blocks {
+		patch = append(patch, b...)
+	}
+
+	return patch, nil
+}
+
+// putOffset writes an offset as a little-endian sign-magnitude integer.
+func putOffset(b []byte, x int) {
+	if x < 0 {
+		binary.LittleEndian.PutUint64(b, uint64(-x)|1<<63)
+	} else {
+		binary.LittleEndian.PutUint64(b, uint64(x))
+	}
+}
+
+// search returns the length and position of the longest match of dst in src.
+func search(sa []int, src, dst []byte, start, end int) (int, int) {
+	for end-start >= 2 {
+		x := start + (end-start)/2
+		if n := min(len(src)-sa[x], len(dst)); bytes.Compare(src[sa[x]:sa[x]+n], dst[:n]) < 0 {
+			start = x
+		} else {
+			end = x
+		}
+	}
+
+	x := matchLen(src[sa[start]:], dst)
+	y := matchLen(src[sa[end]:], dst)
+	if x > y {
+		return x, sa[start]
+	}
+	return y, sa[end]
+}
+
+func matchLen(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// qsufsort returns the suffix array of b, including the empty suffix, using
+// the Larsson-Sadakane algorithm.
+//
+//nolint:cyclop
+func qsufsort(b []byte) []int {
+	sa := make([]int, len(b)+1)
+	v := make([]int, len(b)+1)
+
+	var buckets [256]int
+	for _, c := range b {
+		buckets[c]++
+	}
+	for i := 1; i < 256; i++ {
+		buckets[i] += buckets[i-1]
+	}
+	for i := 255; i > 0; i-- {
+		buckets[i] = buckets[i-1]
+	}
+	buckets[0] = 0
+
+	for i, c := range b {
+		buckets[c]++
+		sa[buckets[c]] = i
+	}
+	sa[0] = len(b)
+	for i, c := range b {
+		v[i] = buckets[c]
+	}
+	v[len(b)] = 0
+	for i := 1; i < 256; i++ {
+		if buckets[i] == buckets[i-1]+1 {
+			sa[buckets[i]] = -1
+		}
+	}
+	sa[0] = -1
+
+	for h := 1; sa[0] != -(len(b) + 1); h += h {
+		var n, i int
+		for i < len(b)+1 {
+			if sa[i] < 0 {
+				n -= sa[i]
+				i -= sa[i]
+			} else {
+				if n != 0 {
+					sa[i-n] = -n
+				}
+				n = v[sa[i]] + 1 - i
+				split(sa, v, i, n, h)
+				i += n
+				n = 0
+			}
+		}
+		if n != 0 {
+			sa[i-n] = -n
+		}
+	}
+
+	for i := range v {
+		sa[v[i]] = i
+	}
+
+	return sa
+}
+
+//nolint:cyclop
+func split(sa, v []int, start, n, h int) {
+	if n < 16 {
+		for k, j := start, 0; k < start+n; k += j {
+			j = 1
+			x := v[sa[k]+h]
+			for i := 1; k+i < start+n; i++ {
+				if v[sa[k+i]+h] < x {
+					x = v[sa[k+i]+h]
+					j = 0
+				}
+				if v[sa[k+i]+h] == x {
+					sa[k+j], sa[k+i] = sa[k+i], sa[k+j]
+					j++
+				}
+			}
+			for i := range j {
+				v[sa[k+i]] = k + j - 1
+			}
+			if j == 1 {
+				sa[k] = -1
+			}
+		}
+		return
+	}
+
+	x := v[sa[start+n/2]+h]
+	var jj, kk int
+	for i := start; i < start+n; i++ {
+		if v[sa[i]+h] < x {
+			jj++
+		}
+		if v[sa[i]+h] == x {
+			kk++
+		}
+	}
+	jj += start
+	kk += jj
+
+	i, j, k := start, 0, 0
+	for i < jj {
+		switch {
+		case v[sa[i]+h] < x:
+			i++
+		case v[sa[i]+h] == x:
+			sa[i], sa[jj+j] = sa[jj+j], sa[i]
+			j++
+		default:
+			sa[i], sa[kk+k] = sa[kk+k], sa[i]
+			k++
+		}
+	}
+
+	for jj+j < kk {
+		if v[sa[jj+j]+h] == x {
+			j++
+		} else {
+			sa[jj+j], sa[kk+k] = sa[kk+k], sa[jj+j]
+			k++
+		}
+	}
+
+	if jj > start {
+		split(sa, v, start, jj-start, h)
+	}
+
+	for i := range kk - jj {
+		v[sa[jj+i]] = kk - 1
+	}
+	if jj == kk-1 {
+		sa[jj] = -1
+	}
+
+	if start+n > kk {
+		split(sa, v, kk, start+n-kk, h)
+	}
+}
+
+func compressBzip2(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := bzip2.NewWriter(&buf, &bzip2.WriterConfig{Level: bzip2.BestCompression})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(b); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
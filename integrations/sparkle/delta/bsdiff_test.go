@@ -0,0 +1,128 @@
+package delta_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/dsnet/compress/bzip2"
+
+	"github.com/kubri/kubri/integrations/sparkle/delta"
+)
+
+func TestBSDiff(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(rnd.IntN(256))
+		}
+		return b
+	}
+
+	base := random(64 * 1024)
+	modified := bytes.Clone(base)
+	for range 100 {
+		modified[rnd.IntN(len(modified))]++
+	}
+	modified = append(modified[:1000], append(random(500), modified[1000:]...)...)
+	modified = append(modified[:30000], modified[40000:]...)
+
+	tests := []struct {
+		name     string
+		src, dst []byte
+	}{
+		{"Empty", nil, nil},
+		{"EmptySource", nil, []byte("test")},
+		{"EmptyDestination", []byte("test"), nil},
+		{"Equal", base, base},
+		{"Repetitive", bytes.Repeat([]byte("abc"), 1000), bytes.Repeat([]byte("abcd"), 1000)},
+		{"Modified", base, modified},
+		{"Random", random(1000), random(2000)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := delta.BSDiff(test.src, test.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := bspatch(test.src, patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.dst) {
+				t.Fatal("patched data does not match")
+			}
+		})
+	}
+
+	t.Run("Size", func(t *testing.T) {
+		patch, _ := delta.BSDiff(base, modified)
+		if len(patch) > len(modified)/10 {
+			t.Fatalf("patch too large: %d bytes for %d bytes", len(patch), len(modified))
+		}
+	})
+}
+
+// bspatch applies a BSDIFF40 patch.
+func bspatch(src, patch []byte) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != "BSDIFF40" {
+		return nil, io.ErrUnexpectedEOF
+	}
+	ctrlLen, diffLen, size := offset(patch[8:]), offset(patch[16:]), offset(patch[24:])
+
+	blocks := make([]io.Reader, 3)
+	for i, b := range [][]byte{
+		patch[32 : 32+ctrlLen],
+		patch[32+ctrlLen : 32+ctrlLen+diffLen],
+		patch[32+ctrlLen+diffLen:],
+	} {
+		r, err := bzip2.NewReader(bytes.NewReader(b), nil)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = r
+	}
+	ctrl, diff, extra := blocks[0], blocks[1], blocks[2]
+
+	dst := make([]byte, size)
+	var srcPos, dstPos int
+	for dstPos < size {
+		var buf [24]byte
+		if _, err := io.ReadFull(ctrl, buf[:]); err != nil {
+			return nil, err
+		}
+		x, y, z := offset(buf[0:]), offset(buf[8:]), offset(buf[16:])
+
+		if _, err := io.ReadFull(diff, dst[dstPos:dstPos+x]); err != nil {
+			return nil, err
+		}
+		for i := range x {
+			if srcPos+i >= 0 && srcPos+i < len(src) {
+				dst[dstPos+i] += src[srcPos+i]
+			}
+		}
+		dstPos += x
+		srcPos += x
+
+		if _, err := io.ReadFull(extra, dst[dstPos:dstPos+y]); err != nil {
+			return nil, err
+		}
+		dstPos += y
+		srcPos += z
+	}
+
+	return dst, nil
+}
+
+func offset(b []byte) int {
+	x := binary.LittleEndian.Uint64(b)
+	if x&(1<<63) != 0 {
+		return -int(x &^ (1 << 63))
+	}
+	return int(x)
+}
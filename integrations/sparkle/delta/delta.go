@@ -0,0 +1,180 @@
+// Package delta creates Sparkle binary delta updates between app bundles.
+//
+// Deltas use version 2 of the BinaryDelta format, a xar archive holding the
+// changed files and bsdiff patches, which is supported by Sparkle 1.10+ and 2.
+package delta
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	majorVersion = 2
+	minorVersion = 0
+)
+
+// File is a file, directory or symlink in an app bundle. Data holds the
+// contents of files and the target of symlinks.
+type File struct {
+	Mode fs.FileMode
+	Data []byte
+}
+
+// Tree is an app bundle, mapping slash-separated paths relative to the bundle
+// to files. Parent directories must be included.
+type Tree map[string]*File
+
+// Create returns a delta to update the before bundle to the after bundle.
+//
+//nolint:cyclop
+func Create(before, after Tree) ([]byte, error) {
+	w := newXarWriter()
+	w.AddSubdoc("binary-delta-attributes", [][2]string{
+		{"major-version", strconv.Itoa(majorVersion)},
+		{"minor-version", strconv.Itoa(minorVersion)},
+		{"before-tree-sha1", Hash(before)},
+		{"after-tree-sha1", Hash(after)},
+	})
+
+	deleteProp := [2]string{"delete", "true"}
+	extractProp := [2]string{"extract", "true"}
+
+	names := slices.Collect(maps.Keys(before))
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	// Deleting a directory deletes its contents.
+	var deleted []string
+	isDeleted := func(name string) bool {
+		return slices.ContainsFunc(deleted, func(dir string) bool { return strings.HasPrefix(name, dir+"/") })
+	}
+
+	for _, name := range names {
+		src, dst := before[name], after[name]
+
+		switch {
+		case dst == nil:
+			if !isDeleted(name) {
+				w.Add(name, nil, nil, [][2]string{deleteProp})
+				deleted = append(deleted, name)
+			}
+
+		case src == nil:
+			w.Add(name, dst, nil, [][2]string{extractProp})
+
+		case src.Mode.Type() != dst.Mode.Type(), dst.Mode&fs.ModeSymlink != 0 && !bytes.Equal(src.Data, dst.Data):
+			w.Add(name, dst, nil, [][2]string{deleteProp, extractProp})
+			deleted = append(deleted, name)
+
+		case dst.Mode.IsRegular() && !bytes.Equal(src.Data, dst.Data):
+			patch, err := bsdiff(src.Data, dst.Data)
+			if err != nil {
+				return nil, err
+			}
+			// Patches don't help if the file was completely rewritten.
+			if len(patch) >= len(dst.Data) {
+				w.Add(name, dst, nil, [][2]string{deleteProp, extractProp})
+				continue
+			}
+			props := [][2]string{{"binary-delta", "true"}}
+			if perm(src.Mode) != perm(dst.Mode) {
+				props = append(props, [2]string{"mod-permissions", strconv.Itoa(int(perm(dst.Mode)))})
+			}
+			w.Add(name, dst, patch, props)
+
+		case perm(src.Mode) != perm(dst.Mode):
+			w.Add(name, nil, nil, [][2]string{{"mod-permissions", strconv.Itoa(int(perm(dst.Mode)))}})
+		}
+	}
+
+	return w.Bytes()
+}
+
+// fts_info values for the tree hash.
+const (
+	ftsD  = 1
+	ftsF  = 8
+	ftsSL = 12
+)
+
+// Hash returns the SHA-1 hash of a bundle, which Sparkle uses to verify the
+// bundle before and after applying the delta.
+//
+// The hash covers the hash of the contents, path, type and permissions of each
+// entry, visited depth-first in lexical order.
+func Hash(t Tree) string {
+	children := map[string][]string{}
+	for name := range t {
+		dir := path.Dir(name)
+		children[dir] = append(children[dir], name)
+	}
+	for _, names := range children {
+		slices.SortFunc(names, func(a, b string) int { return strings.Compare(path.Base(a), path.Base(b)) })
+	}
+
+	h := sha1.New()
+	var walk func(dir string)
+	walk = func(dir string) {
+		for _, name := range children[dir] {
+			f := t[name]
+
+			var sum [sha1.Size]byte
+			var typ uint16
+			switch {
+			case f.Mode.IsDir():
+				typ = ftsD
+				sum = [sha1.Size]byte(bytes.Repeat([]byte{0xdd}, sha1.Size))
+			case f.Mode&fs.ModeSymlink != 0:
+				typ = ftsSL
+				sum = sha1.Sum(f.Data)
+			default:
+				typ = ftsF
+				sum = sha1.Sum(f.Data)
+			}
+
+			h.Write(sum[:])
+			h.Write([]byte(name))
+			_ = binary.Write(h, binary.LittleEndian, typ)
+			_ = binary.Write(h, binary.LittleEndian, perm(f.Mode))
+
+			if f.Mode.IsDir() {
+				walk(name)
+			}
+		}
+	}
+	walk(".")
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// perm returns the unix permission bits, including setuid, setgid and sticky.
+func perm(m fs.FileMode) uint16 {
+	p := uint16(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		p |= 0o4000
+	}
+	if m&fs.ModeSetgid != 0 {
+		p |= 0o2000
+	}
+	if m&fs.ModeSticky != 0 {
+		p |= 0o1000
+	}
+	return p
+}
+
+func formatMode(m fs.FileMode) string {
+	return "0" + strconv.FormatUint(uint64(perm(m)), 8)
+}
@@ -0,0 +1,316 @@
+package delta_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/sparkle/delta"
+)
+
+func TestCreate(t *testing.T) {
+	binary := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	binary2 := bytes.Clone(binary)
+	copy(binary2[1000:], "patched")
+
+	dir := func() *delta.File { return &delta.File{Mode: fs.ModeDir | 0o755} }
+	file := func(s string) *delta.File { return &delta.File{Mode: 0o644, Data: []byte(s)} }
+	exe := func(b []byte) *delta.File { return &delta.File{Mode: 0o755, Data: b} }
+	link := func(s string) *delta.File { return &delta.File{Mode: fs.ModeSymlink | 0o755, Data: []byte(s)} }
+
+	before := delta.Tree{
+		"Contents":                         dir(),
+		"Contents/Info.plist":              file("1.0.0"),
+		"Contents/MacOS":                   dir(),
+		"Contents/MacOS/Test":              exe(binary),
+		"Contents/MacOS/helper":            file("helper"),
+		"Contents/Resources":               dir(),
+		"Contents/Resources/unchanged.txt": file("unchanged"),
+		"Contents/Resources/link":          link("unchanged.txt"),
+		"Contents/Resources/replaced":      dir(),
+		"Contents/Resources/replaced/file": file("replaced"),
+		"Contents/Resources/removed":       dir(),
+		"Contents/Resources/removed/file":  file("removed"),
+	}
+	after := delta.Tree{
+		"Contents":                         dir(),
+		"Contents/Info.plist":              file("2.0.0"),
+		"Contents/MacOS":                   dir(),
+		"Contents/MacOS/Test":              exe(binary2),
+		"Contents/MacOS/helper":            exe([]byte("helper")),
+		"Contents/Resources":               dir(),
+		"Contents/Resources/unchanged.txt": file("unchanged"),
+		"Contents/Resources/link":          link("new.txt"),
+		"Contents/Resources/new.txt":       file("new"),
+		"Contents/Resources/replaced":      file("replaced"),
+		"Contents/Resources/added":         dir(),
+		"Contents/Resources/added/file":    file("added"),
+	}
+
+	b, err := delta.Create(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toc, heap := readXar(t, b)
+
+	attrs := map[string]string{}
+	for _, p := range toc.Attributes.Props {
+		attrs[p.XMLName.Local] = p.Value
+	}
+	wantAttrs := map[string]string{
+		"major-version":    "2",
+		"minor-version":    "0",
+		"before-tree-sha1": delta.Hash(before),
+		"after-tree-sha1":  delta.Hash(after),
+	}
+	if diff := cmp.Diff(wantAttrs, attrs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	got := apply(t, before, toc, heap)
+	if diff := cmp.Diff(after, got); diff != "" {
+		t.Fatal(diff)
+	}
+	if delta.Hash(got) != attrs["after-tree-sha1"] {
+		t.Fatal("should match after tree hash")
+	}
+
+	// Only changed files should be included.
+	var names []string
+	walkXar(toc.Files, "", func(name string, f *xarFile) {
+		if len(f.Props) > 0 {
+			names = append(names, name)
+		}
+	})
+	wantNames := []string{
+		"Contents/Info.plist",
+		"Contents/MacOS/Test",
+		"Contents/MacOS/helper",
+		"Contents/Resources/added",
+		"Contents/Resources/added/file",
+		"Contents/Resources/link",
+		"Contents/Resources/new.txt",
+		"Contents/Resources/removed",
+		"Contents/Resources/replaced",
+	}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Fatal(diff)
+	}
+
+	t.Run("bsdtar", func(t *testing.T) {
+		if _, err := exec.LookPath("bsdtar"); err != nil {
+			t.Skip("bsdtar not found")
+		}
+
+		path := filepath.Join(t.TempDir(), "test.delta")
+		if err := os.WriteFile(path, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command("bsdtar", "-tf", path).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %s", err, out)
+		}
+		if !strings.Contains(string(out), "Contents/MacOS/Test") {
+			t.Fatalf("missing file in archive:\n%s", out)
+		}
+	})
+}
+
+func TestHash(t *testing.T) {
+	tree := delta.Tree{
+		"Contents":            {Mode: fs.ModeDir | 0o755},
+		"Contents/Info.plist": {Mode: 0o644, Data: []byte("test")},
+		"Contents/MacOS":      {Mode: fs.ModeDir | 0o755},
+		"Contents/MacOS/Test": {Mode: 0o755, Data: []byte("test")},
+	}
+	hash := delta.Hash(tree)
+
+	if len(hash) != 40 {
+		t.Fatalf("want SHA-1 hex digest got %q", hash)
+	}
+
+	modify := []func(delta.Tree){
+		func(t delta.Tree) { t["Contents/Info.plist"].Data = []byte("changed") },
+		func(t delta.Tree) { t["Contents/MacOS/Test"].Mode = 0o644 },
+		func(t delta.Tree) { t["Contents/MacOS/Test"].Mode = fs.ModeSymlink | 0o755 },
+		func(t delta.Tree) { t["Contents/Resources"] = &delta.File{Mode: fs.ModeDir | 0o755} },
+		func(t delta.Tree) { t["Contents/MacOS/test"], t["Contents/MacOS/Test"] = t["Contents/MacOS/Test"], nil },
+	}
+	for i, fn := range modify {
+		tree := cloneTree(tree)
+		fn(tree)
+		maps.DeleteFunc(tree, func(_ string, f *delta.File) bool { return f == nil })
+		if delta.Hash(tree) == hash {
+			t.Errorf("%d: hash should change", i)
+		}
+	}
+
+	if delta.Hash(cloneTree(tree)) != hash {
+		t.Error("hash should be stable")
+	}
+}
+
+func cloneTree(t delta.Tree) delta.Tree {
+	res := delta.Tree{}
+	for k, v := range t {
+		f := *v
+		res[k] = &f
+	}
+	return res
+}
+
+type xarTOC struct {
+	Attributes struct {
+		Props []xarProp `xml:",any"`
+	} `xml:"binary-delta-attributes"`
+	Checksum struct {
+		Offset int `xml:"offset"`
+		Size   int `xml:"size"`
+	} `xml:"toc>checksum"`
+	Files []*xarFile `xml:"toc>file"`
+}
+
+type xarFile struct {
+	Name string `xml:"name"`
+	Type string `xml:"type"`
+	Link string `xml:"link"`
+	Mode string `xml:"mode"`
+	Data *struct {
+		Length            int    `xml:"length"`
+		Offset            int    `xml:"offset"`
+		Size              int    `xml:"size"`
+		ArchivedChecksum  string `xml:"archived-checksum"`
+		ExtractedChecksum string `xml:"extracted-checksum"`
+	} `xml:"data"`
+	Children []*xarFile `xml:"file"`
+	Props    []xarProp  `xml:",any"`
+}
+
+type xarProp struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+func (f *xarFile) prop(name string) (string, bool) {
+	for _, p := range f.Props {
+		if p.XMLName.Local == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+func readXar(t *testing.T, b []byte) (*xarTOC, []byte) {
+	t.Helper()
+
+	if string(b[:4]) != "xar!" {
+		t.Fatal("invalid magic")
+	}
+	size := int(binary.BigEndian.Uint16(b[4:]))
+	compressed := b[size : size+int(binary.BigEndian.Uint64(b[8:]))]
+	heap := b[size+len(compressed):]
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != int(binary.BigEndian.Uint64(b[16:])) {
+		t.Fatal("invalid toc length")
+	}
+
+	var toc xarTOC
+	if err = xml.Unmarshal(raw, &toc); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha1.Sum(compressed)
+	if !bytes.Equal(heap[toc.Checksum.Offset:toc.Checksum.Offset+toc.Checksum.Size], sum[:]) {
+		t.Fatal("invalid toc checksum")
+	}
+
+	return &toc, heap
+}
+
+func walkXar(files []*xarFile, dir string, fn func(string, *xarFile)) {
+	for _, f := range files {
+		name := path.Join(dir, f.Name)
+		fn(name, f)
+		walkXar(f.Children, name, fn)
+	}
+}
+
+// apply applies a delta the same way Sparkle does.
+func apply(t *testing.T, before delta.Tree, toc *xarTOC, heap []byte) delta.Tree {
+	t.Helper()
+
+	tree := cloneTree(before)
+	walkXar(toc.Files, "", func(name string, f *xarFile) {
+		var data []byte
+		if f.Data != nil {
+			archived := heap[f.Data.Offset : f.Data.Offset+f.Data.Length]
+			if sum := sha1.Sum(archived); hex.EncodeToString(sum[:]) != f.Data.ArchivedChecksum {
+				t.Fatalf("%s: invalid archived checksum", name)
+			}
+			r, err := bzip2.NewReader(bytes.NewReader(archived), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, err = io.ReadAll(r); err != nil {
+				t.Fatal(err)
+			}
+			if sum := sha1.Sum(data); hex.EncodeToString(sum[:]) != f.Data.ExtractedChecksum {
+				t.Fatalf("%s: invalid extracted checksum", name)
+			}
+		}
+
+		if _, ok := f.prop("delete"); ok {
+			maps.DeleteFunc(tree, func(k string, _ *delta.File) bool { return k == name || strings.HasPrefix(k, name+"/") })
+		}
+
+		mode, _ := strconv.ParseUint(f.Mode, 8, 32)
+		if _, ok := f.prop("binary-delta"); ok {
+			b, err := bspatch(tree[name].Data, data)
+			if err != nil {
+				t.Fatalf("%s: %s", name, err)
+			}
+			tree[name].Data = b
+		} else if _, ok := f.prop("extract"); ok {
+			switch f.Type {
+			case "directory":
+				tree[name] = &delta.File{Mode: fs.ModeDir | fs.FileMode(mode)}
+			case "symlink":
+				tree[name] = &delta.File{Mode: fs.ModeSymlink | fs.FileMode(mode), Data: []byte(f.Link)}
+			default:
+				tree[name] = &delta.File{Mode: fs.FileMode(mode), Data: data}
+			}
+		}
+
+		if v, ok := f.prop("mod-permissions"); ok {
+			perm, _ := strconv.Atoi(v)
+			tree[name].Mode = tree[name].Mode.Type() | fs.FileMode(perm)
+		}
+	})
+
+	return tree
+}
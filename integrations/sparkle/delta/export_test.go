@@ -0,0 +1,3 @@
+package delta
+
+var BSDiff = bsdiff
@@ -0,0 +1,223 @@
+package delta
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"io/fs"
+	"path"
+)
+
+// See https://github.com/mackyle/xar/wiki/xarformat
+const (
+	xarMagic      = 0x78617221 // xar!
+	xarHeaderSize = 28
+	xarVersion    = 1
+	xarSHA1       = 1
+)
+
+type xarTOC struct {
+	XMLName  xml.Name    `xml:"xar"`
+	Subdocs  []xarSubdoc `xml:",any"`
+	Checksum struct {
+		Style  string `xml:"style,attr"`
+		Offset int    `xml:"offset"`
+		Size   int    `xml:"size"`
+	} `xml:"toc>checksum"`
+	Files []*xarFile `xml:"toc>file"`
+}
+
+type xarSubdoc struct {
+	XMLName xml.Name
+	Name    string    `xml:"subdoc_name,attr"`
+	Props   []xarProp `xml:",any"`
+}
+
+type xarFile struct {
+	ID       int        `xml:"id,attr"`
+	Name     string     `xml:"name"`
+	Type     string     `xml:"type"`
+	Link     *xarLink   `xml:"link,omitempty"`
+	Mode     string     `xml:"mode,omitempty"`
+	Data     *xarData   `xml:"data,omitempty"`
+	Props    []xarProp  `xml:",any"`
+	Children []*xarFile `xml:"file"`
+
+	content []byte
+}
+
+type xarLink struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xarData struct {
+	Length            int         `xml:"length"`
+	Offset            int         `xml:"offset"`
+	Size              int         `xml:"size"`
+	Encoding          xarEncoding `xml:"encoding"`
+	ArchivedChecksum  xarChecksum `xml:"archived-checksum"`
+	ExtractedChecksum xarChecksum `xml:"extracted-checksum"`
+}
+
+type xarEncoding struct {
+	Style string `xml:"style,attr"`
+}
+
+type xarChecksum struct {
+	Style string `xml:"style,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xarProp struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+func newProps(props [][2]string) []xarProp {
+	res := make([]xarProp, len(props))
+	for i, p := range props {
+		res[i] = xarProp{XMLName: xml.Name{Local: p[0]}, Value: p[1]}
+	}
+	return res
+}
+
+// xarWriter creates xar archives with bzip2 compressed files.
+type xarWriter struct {
+	toc   xarTOC
+	files map[string]*xarFile
+}
+
+func newXarWriter() *xarWriter {
+	return &xarWriter{files: map[string]*xarFile{}}
+}
+
+// AddSubdoc adds a subdocument with the properties.
+func (w *xarWriter) AddSubdoc(name string, props [][2]string) {
+	w.toc.Subdocs = append(w.toc.Subdocs, xarSubdoc{
+		XMLName: xml.Name{Local: name},
+		Name:    name,
+		Props:   newProps(props),
+	})
+}
+
+// Add adds an entry with the properties at the slash-separated path. Missing
+// parent directories are added without properties. If f is nil, an empty file
+// without data is added. If data is set, it's stored in place of the contents
+// of f, e.g. for patches.
+func (w *xarWriter) Add(name string, f *File, data []byte, props [][2]string) {
+	file := w.file(name)
+	file.Type = "file"
+	file.Props = newProps(props)
+
+	switch {
+	case f == nil:
+	case f.Mode.IsDir():
+		file.Type = "directory"
+	case f.Mode&fs.ModeSymlink != 0:
+		file.Type = "symlink"
+		file.Link = &xarLink{Type: "broken", Value: string(f.Data)}
+	case data != nil:
+		file.content = data
+	default:
+		file.content = f.Data
+	}
+
+	if f != nil {
+		file.Mode = formatMode(f.Mode)
+		if file.Type == "file" && file.content == nil {
+			file.content = []byte{}
+		}
+	}
+}
+
+func (w *xarWriter) file(name string) *xarFile {
+	if f, ok := w.files[name]; ok {
+		return f
+	}
+
+	var parent *xarFile
+	if dir := path.Dir(name); dir != "." {
+		parent = w.file(dir)
+	}
+
+	f := &xarFile{ID: len(w.files) + 1, Name: path.Base(name), Type: "directory"}
+	w.files[name] = f
+
+	if parent == nil {
+		w.toc.Files = append(w.toc.Files, f)
+	} else {
+		parent.Children = append(parent.Children, f)
+	}
+
+	return f
+}
+
+// Bytes returns the archive.
+func (w *xarWriter) Bytes() ([]byte, error) {
+	// The heap starts with the checksum of the table of contents.
+	heap := make([]byte, sha1.Size)
+	if err := writeHeap(&heap, w.toc.Files); err != nil {
+		return nil, err
+	}
+
+	w.toc.Checksum.Style = "sha1"
+	w.toc.Checksum.Size = sha1.Size
+
+	toc, err := xml.MarshalIndent(w.toc, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	toc = append([]byte(xml.Header), toc...)
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err = zw.Write(toc); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	compressed := buf.Bytes()
+
+	sum := sha1.Sum(compressed)
+	copy(heap, sum[:])
+
+	b := make([]byte, xarHeaderSize, xarHeaderSize+len(compressed)+len(heap))
+	binary.BigEndian.PutUint32(b[0:], xarMagic)
+	binary.BigEndian.PutUint16(b[4:], xarHeaderSize)
+	binary.BigEndian.PutUint16(b[6:], xarVersion)
+	binary.BigEndian.PutUint64(b[8:], uint64(len(compressed)))
+	binary.BigEndian.PutUint64(b[16:], uint64(len(toc)))
+	binary.BigEndian.PutUint32(b[24:], xarSHA1)
+	b = append(b, compressed...)
+	return append(b, heap...), nil
+}
+
+func writeHeap(heap *[]byte, files []*xarFile) error {
+	for _, f := range files {
+		if f.content != nil {
+			b, err := compressBzip2(f.content)
+			if err != nil {
+				return err
+			}
+			archived, extracted := sha1.Sum(b), sha1.Sum(f.content)
+			f.Data = &xarData{
+				Length:            len(b),
+				Offset:            len(*heap),
+				Size:              len(f.content),
+				Encoding:          xarEncoding{Style: "application/x-bzip2"},
+				ArchivedChecksum:  xarChecksum{Style: "sha1", Value: hex.EncodeToString(archived[:])},
+				ExtractedChecksum: xarChecksum{Style: "sha1", Value: hex.EncodeToString(extracted[:])},
+			}
+			*heap = append(*heap, b...)
+		}
+		if err := writeHeap(heap, f.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sparkle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/kubri/kubri/integrations/sparkle/delta"
+	"github.com/kubri/kubri/source"
+)
+
+// addDeltas adds delta updates from previous versions to the new items. Items
+// must be ordered from newest to oldest.
+func addDeltas(ctx context.Context, c *Config, releases []*source.Release, newItems, items []*Item) error {
+	// Items read from the feed only have a version, so all releases are listed
+	// the first time the tag of one of them is needed.
+	tags := map[string]string{}
+	addTags := func(releases []*source.Release) {
+		for _, r := range releases {
+			tags[strings.TrimPrefix(r.Version, "v")] = r.Version
+		}
+	}
+	addTags(releases)
+	var listed bool
+	getTag := func(version string) (string, error) {
+		if _, ok := tags[version]; !ok && !listed {
+			listed = true
+			all, err := c.Source.ListReleases(ctx, &source.ListOptions{Prerelease: true})
+			if err != nil {
+				return "", err
+			}
+			addTags(all)
+		}
+		if tag, ok := tags[version]; ok {
+			return tag, nil
+		}
+		return "", fmt.Errorf("%w: %s", source.ErrNoReleaseFound, version)
+	}
+
+	extract := func(item *Item) (delta.Tree, error) {
+		tag, err := getTag(item.Version)
+		if err != nil {
+			return nil, err
+		}
+		name := assetName(item)
+		b, err := c.Source.DownloadAsset(ctx, tag, name)
+		if err != nil {
+			return nil, err
+		}
+		return delta.Extract(name, b)
+	}
+
+	for i, item := range newItems {
+		if !isDeltaCandidate(item) {
+			continue
+		}
+
+		after, err := extract(item)
+		if errors.Is(err, delta.ErrNoBundle) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		tag, err := getTag(item.Version)
+		if err != nil {
+			return err
+		}
+		name := assetName(item)
+		base := strings.TrimSuffix(strings.TrimSuffix(name, path.Ext(name)), ".tar")
+
+		// Previous bundles are extracted one at a time to limit memory usage.
+		for _, prev := range getPreviousItems(item, items[i+1:], c.Deltas) {
+			before, err := extract(prev)
+			if err != nil {
+				log.Printf("Skipping delta from %s to %s: %s", prev.Version, item.Version, err)
+				continue
+			}
+
+			b, err := delta.Create(before, after)
+			if err != nil {
+				return err
+			}
+
+			edSig, _, err := signAsset(c, MacOS, b)
+			if err != nil {
+				return err
+			}
+
+			url, err := uploadAsset(ctx, c, tag+"/"+base+"-"+prev.Version+".delta", b)
+			if err != nil {
+				return err
+			}
+
+			if item.Deltas == nil {
+				item.Deltas = &Deltas{}
+			}
+			item.Deltas.Enclosures = append(item.Deltas.Enclosures, &Enclosure{
+				URL:         url,
+				OS:          item.Enclosure.OS,
+				Version:     item.Version,
+				DeltaFrom:   prev.Version,
+				EDSignature: edSig,
+				Length:      len(b),
+				Type:        "application/octet-stream",
+			})
+		}
+	}
+
+	return nil
+}
+
+// getPreviousItems returns up to n items of previous versions for the same
+// asset, e.g. 'App-1.0.0.zip' for 'App-1.1.0.zip'.
+func getPreviousItems(item *Item, items []*Item, n int) []*Item {
+	name := assetName(item)
+	var res []*Item
+	for _, prev := range items {
+		if len(res) == n {
+			break
+		}
+		if prev.Version == item.Version || !isDeltaCandidate(prev) {
+			continue
+		}
+		if strings.ReplaceAll(assetName(prev), prev.Version, item.Version) == name {
+			res = append(res, prev)
+		}
+	}
+	return res
+}
+
+func isDeltaCandidate(item *Item) bool {
+	return item.Enclosure != nil && item.Enclosure.OS == MacOS.String() && delta.IsArchive(assetName(item))
+}
+
+func assetName(item *Item) string {
+	if u, err := url.Parse(item.Enclosure.URL); err == nil {
+		if name, err := url.PathUnescape(path.Base(u.EscapedPath())); err == nil {
+			return name
+		}
+	}
+	return path.Base(item.Enclosure.URL)
+}
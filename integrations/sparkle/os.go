@@ -17,6 +17,7 @@ const (
 	Windows32
 	Windows64
 	WindowsARM64
+	Linux
 )
 
 var ErrUnknownOS = errors.New("unknown os")
@@ -35,11 +36,13 @@ func (os OS) String() string {
 		return "windows-x64"
 	case WindowsARM64:
 		return "windows-arm64"
+	case Linux:
+		return "linux"
 	}
 }
 
 func (os OS) MarshalText() ([]byte, error) {
-	if os > WindowsARM64 {
+	if os > Linux {
 		return nil, ErrUnknownOS
 	}
 	return []byte(os.String()), nil
@@ -47,7 +50,7 @@ func (os OS) MarshalText() ([]byte, error) {
 
 func (os *OS) UnmarshalText(text []byte) error {
 	s := string(text)
-	for i := Unknown; i <= WindowsARM64; i++ {
+	for i := Unknown; i <= Linux; i++ {
 		if i.String() == s {
 			*os = i
 			return nil
@@ -79,6 +82,9 @@ func detectOS(name string) OS {
 	case "":
 	case ".dmg", ".pkg", ".mpkg":
 		return MacOS
+	// Tarballs are also used for macOS bundles, so they need to be set with detect-os.
+	case ".AppImage", ".appimage", ".deb":
+		return Linux
 	case ".exe", ".msi":
 		is32, _ := reWin32.MatchString(name)
 		is64, _ := reWin64.MatchString(name)
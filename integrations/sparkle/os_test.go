@@ -18,6 +18,7 @@ func TestOS_MarshalText(t *testing.T) {
 		{sparkle.Windows32, "windows-x86", nil},
 		{sparkle.Windows64, "windows-x64", nil},
 		{sparkle.WindowsARM64, "windows-arm64", nil},
+		{sparkle.Linux, "linux", nil},
 		{sparkle.Unknown, "", nil},
 		{255, "", sparkle.ErrUnknownOS},
 	}
@@ -43,6 +44,7 @@ func TestOS_UnmarshalText(t *testing.T) {
 		{"windows-x86", sparkle.Windows32, nil},
 		{"windows-x64", sparkle.Windows64, nil},
 		{"windows-arm64", sparkle.WindowsARM64, nil},
+		{"linux", sparkle.Linux, nil},
 		{"", sparkle.Unknown, nil},
 		{"foo", sparkle.Unknown, sparkle.ErrUnknownOS},
 	}
@@ -68,6 +70,8 @@ func TestIsOS(t *testing.T) {
 		{sparkle.Windows32, sparkle.Windows, true},
 		{sparkle.Windows64, sparkle.Windows, true},
 		{sparkle.WindowsARM64, sparkle.Windows, true},
+		{sparkle.Linux, sparkle.Linux, true},
+		{sparkle.Linux, sparkle.Windows, false},
 		{sparkle.Unknown, sparkle.MacOS, false},
 		{sparkle.MacOS, sparkle.Unknown, true},
 		{255, sparkle.Windows, false},
@@ -102,6 +106,11 @@ func TestDetectOS(t *testing.T) {
 		{"test_amd64.msi", sparkle.Windows64},
 		{"test_arm64.msi", sparkle.WindowsARM64},
 		{"test_aarch64.msi", sparkle.WindowsARM64},
+		{"test.AppImage", sparkle.Linux},
+		{"test.appimage", sparkle.Linux},
+		{"test.deb", sparkle.Linux},
+		{"test.tar.gz", sparkle.Unknown},
+		{"test.tgz", sparkle.Unknown},
 	}
 
 	for _, test := range tests {
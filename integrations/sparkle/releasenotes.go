@@ -0,0 +1,126 @@
+package sparkle
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+
+	"github.com/kubri/kubri/source"
+)
+
+//nolint:gochecknoglobals
+var reLang = regexp.MustCompile(`^[a-zA-Z]{2,3}([_-][a-zA-Z0-9]+)*$`)
+
+// getReleaseNotes writes the release notes of a release to HTML files and
+// returns links to them, starting with the default language.
+func getReleaseNotes(ctx context.Context, c *Config, release *source.Release) ([]*ReleaseNotesLink, error) {
+	notes := map[string][]byte{}
+	if release.Description != "" {
+		notes[""] = renderNotes(release.Name, "md", []byte(release.Description))
+	}
+
+	for _, asset := range release.Assets {
+		lang, ext, ok := parseNotesName(asset.Name, "release-notes")
+		if !ok {
+			continue
+		}
+		b, err := c.Source.DownloadAsset(ctx, release.Version, asset.Name)
+		if err != nil {
+			return nil, err
+		}
+		notes[lang] = renderNotes(release.Name, ext, b)
+	}
+
+	if c.ReleaseNotes.Dir != "" {
+		if err := readNotesDir(c.ReleaseNotes.Dir, release, notes); err != nil {
+			return nil, err
+		}
+	}
+
+	langs := make([]string, 0, len(notes))
+	for lang := range notes {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+
+	version := strings.TrimPrefix(release.Version, "v")
+	links := make([]*ReleaseNotesLink, 0, len(langs))
+	for _, lang := range langs {
+		name := version + ".html"
+		if lang != "" {
+			name = version + "." + lang + ".html"
+		}
+		url, err := uploadAsset(ctx, c, path.Join(c.ReleaseNotes.Folder, name), notes[lang])
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, &ReleaseNotesLink{Lang: lang, URL: url})
+	}
+
+	return links, nil
+}
+
+// readNotesDir reads the release notes of the release from dir.
+func readNotesDir(dir string, release *source.Release, notes map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		lang, ext, ok := parseNotesName(entry.Name(), release.Version)
+		if !ok {
+			lang, ext, ok = parseNotesName(entry.Name(), strings.TrimPrefix(release.Version, "v"))
+		}
+		if !ok || entry.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		notes[lang] = renderNotes(release.Name, ext, b)
+	}
+
+	return nil
+}
+
+// parseNotesName returns the language and extension of release notes named
+// <prefix>.md, <prefix>.html, <prefix>.<lang>.md or <prefix>.<lang>.html.
+func parseNotesName(name, prefix string) (string, string, bool) {
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	if ext != "md" && ext != "html" {
+		return "", "", false
+	}
+
+	rest, ok := strings.CutPrefix(strings.TrimSuffix(name, "."+ext), prefix)
+	if !ok {
+		return "", "", false
+	}
+	if rest == "" {
+		return "", ext, true
+	}
+
+	lang, ok := strings.CutPrefix(rest, ".")
+	if !ok || !reLang.MatchString(lang) {
+		return "", "", false
+	}
+
+	return lang, ext, true
+}
+
+func renderNotes(title, ext string, b []byte) []byte {
+	if ext == "html" {
+		return b
+	}
+	return blackfriday.Run(b, blackfriday.WithRenderer(blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Title: title,
+		Flags: blackfriday.CommonHTMLFlags | blackfriday.CompletePage | blackfriday.UseXHTML,
+	})))
+}
@@ -49,12 +49,16 @@ func (r *RSS) UnmarshalXML(dec *xml.Decoder, _ xml.StartElement) error {
 				PubDate:                           item.PubDate,
 				Description:                       item.Description,
 				Version:                           item.Version,
-				ReleaseNotesLink:                  item.ReleaseNotesLink,
+				ReleaseNotesLink:                  getReleaseNotesLinks(item.ReleaseNotesLink),
+				FullReleaseNotesLink:              item.FullReleaseNotesLink,
+				Channel:                           item.Channel,
 				CriticalUpdate:                    (*CriticalUpdate)(item.CriticalUpdate),
 				Tags:                              (*Tags)(item.Tags),
 				MinimumAutoupdateVersion:          item.MinimumAutoupdateVersion,
 				IgnoreSkippedUpgradesBelowVersion: item.IgnoreSkippedUpgradesBelowVersion,
+				PhasedRolloutInterval:             item.PhasedRolloutInterval,
 				Enclosure:                         (*Enclosure)(item.Enclosure),
+				Deltas:                            getDeltas(item.Deltas),
 			})
 		}
 
@@ -64,6 +68,28 @@ func (r *RSS) UnmarshalXML(dec *xml.Decoder, _ xml.StartElement) error {
 	return dec.Skip()
 }
 
+func getReleaseNotesLinks(l []*unmarshalReleaseNotesLink) []*ReleaseNotesLink {
+	if l == nil {
+		return nil
+	}
+	links := make([]*ReleaseNotesLink, len(l))
+	for i, link := range l {
+		links[i] = (*ReleaseNotesLink)(link)
+	}
+	return links
+}
+
+func getDeltas(d *unmarshalDeltas) *Deltas {
+	if d == nil {
+		return nil
+	}
+	deltas := &Deltas{Enclosures: make([]*Enclosure, len(d.Enclosures))}
+	for i, e := range d.Enclosures {
+		deltas.Enclosures[i] = (*Enclosure)(e)
+	}
+	return deltas
+}
+
 type Channel struct {
 	Title       string  `xml:"title"`
 	Link        string  `xml:"link,omitempty"`
@@ -73,16 +99,20 @@ type Channel struct {
 }
 
 type Item struct {
-	Title                             string          `xml:"title"`
-	PubDate                           string          `xml:"pubDate"`
-	Description                       *CdataString    `xml:"description,omitempty"`
-	Version                           string          `xml:"sparkle:version,omitempty"`
-	ReleaseNotesLink                  string          `xml:"sparkle:releaseNotesLink,omitempty"`
-	CriticalUpdate                    *CriticalUpdate `xml:"sparkle:criticalUpdate,omitempty"`
-	Tags                              *Tags           `xml:"sparkle:tags,omitempty"`
-	MinimumAutoupdateVersion          string          `xml:"sparkle:minimumAutoupdateVersion,omitempty"`
-	IgnoreSkippedUpgradesBelowVersion string          `xml:"sparkle:ignoreSkippedUpgradesBelowVersion,omitempty"`
-	Enclosure                         *Enclosure      `xml:"enclosure,omitempty"`
+	Title                             string              `xml:"title"`
+	PubDate                           string              `xml:"pubDate"`
+	Description                       *CdataString        `xml:"description,omitempty"`
+	Version                           string              `xml:"sparkle:version,omitempty"`
+	ReleaseNotesLink                  []*ReleaseNotesLink `xml:"sparkle:releaseNotesLink,omitempty"`
+	FullReleaseNotesLink              string              `xml:"sparkle:fullReleaseNotesLink,omitempty"`
+	Channel                           string              `xml:"sparkle:channel,omitempty"`
+	CriticalUpdate                    *CriticalUpdate     `xml:"sparkle:criticalUpdate,omitempty"`
+	Tags                              *Tags               `xml:"sparkle:tags,omitempty"`
+	MinimumAutoupdateVersion          string              `xml:"sparkle:minimumAutoupdateVersion,omitempty"`
+	IgnoreSkippedUpgradesBelowVersion string              `xml:"sparkle:ignoreSkippedUpgradesBelowVersion,omitempty"`
+	PhasedRolloutInterval             int                 `xml:"sparkle:phasedRolloutInterval,omitempty"`
+	Enclosure                         *Enclosure          `xml:"enclosure,omitempty"`
+	Deltas                            *Deltas             `xml:"sparkle:deltas,omitempty"`
 }
 
 // CdataString for XML CDATA
@@ -91,6 +121,11 @@ type CdataString struct {
 	Value string `xml:",cdata"`
 }
 
+type ReleaseNotesLink struct {
+	Lang string `xml:"xml:lang,attr,omitempty"`
+	URL  string `xml:",chardata"`
+}
+
 type CriticalUpdate struct {
 	Version string `xml:"sparkle:version,attr,omitempty"`
 }
@@ -110,10 +145,15 @@ func (xb *Bool) UnmarshalText([]byte) error {
 	return nil
 }
 
+type Deltas struct {
+	Enclosures []*Enclosure `xml:"enclosure"`
+}
+
 type Enclosure struct {
 	URL                  string `xml:"url,attr"`
 	OS                   string `xml:"sparkle:os,attr"`
 	Version              string `xml:"sparkle:version,attr"`
+	DeltaFrom            string `xml:"sparkle:deltaFrom,attr,omitempty"`
 	DSASignature         string `xml:"sparkle:dsaSignature,attr,omitempty"`
 	EDSignature          string `xml:"sparkle:edSignature,attr,omitempty"`
 	InstallerArguments   string `xml:"sparkle:installerArguments,attr,omitempty"`
@@ -129,29 +169,45 @@ type unmarshalRSS struct {
 	Description string   `xml:"description,omitempty"`
 	Language    string   `xml:"language,omitempty"`
 	Items       []struct {
-		Title            string       `xml:"title"`
-		PubDate          string       `xml:"pubDate"`
-		Description      *CdataString `xml:"description,omitempty"`
-		Version          string       `xml:"version,omitempty"`
-		ReleaseNotesLink string       `xml:"releaseNotesLink,omitempty"`
-		CriticalUpdate   *struct {
+		Title                string                       `xml:"title"`
+		PubDate              string                       `xml:"pubDate"`
+		Description          *CdataString                 `xml:"description,omitempty"`
+		Version              string                       `xml:"version,omitempty"`
+		ReleaseNotesLink     []*unmarshalReleaseNotesLink `xml:"releaseNotesLink,omitempty"`
+		FullReleaseNotesLink string                       `xml:"fullReleaseNotesLink,omitempty"`
+		Channel              string                       `xml:"channel,omitempty"`
+		CriticalUpdate       *struct {
 			Version string `xml:"version,attr,omitempty"`
 		} `xml:"criticalUpdate,omitempty"`
 		Tags *struct {
 			CriticalUpdate Bool `xml:"criticalUpdate,omitempty"`
 		} `xml:"tags,omitempty"`
-		MinimumAutoupdateVersion          string `xml:"minimumAutoupdateVersion,omitempty"`
-		IgnoreSkippedUpgradesBelowVersion string `xml:"ignoreSkippedUpgradesBelowVersion,omitempty"`
-		Enclosure                         *struct {
-			URL                  string `xml:"url,attr"`
-			OS                   string `xml:"os,attr"`
-			Version              string `xml:"version,attr"`
-			DSASignature         string `xml:"dsaSignature,attr,omitempty"`
-			EDSignature          string `xml:"edSignature,attr,omitempty"`
-			InstallerArguments   string `xml:"installerArguments,attr,omitempty"`
-			MinimumSystemVersion string `xml:"minimumSystemVersion,attr,omitempty"`
-			Length               int    `xml:"length,attr,omitempty"`
-			Type                 string `xml:"type,attr"`
-		} `xml:"enclosure,omitempty"`
+		MinimumAutoupdateVersion          string              `xml:"minimumAutoupdateVersion,omitempty"`
+		IgnoreSkippedUpgradesBelowVersion string              `xml:"ignoreSkippedUpgradesBelowVersion,omitempty"`
+		PhasedRolloutInterval             int                 `xml:"phasedRolloutInterval,omitempty"`
+		Enclosure                         *unmarshalEnclosure `xml:"enclosure,omitempty"`
+		Deltas                            *unmarshalDeltas    `xml:"deltas,omitempty"`
 	} `xml:"item"`
 }
+
+type unmarshalReleaseNotesLink struct {
+	Lang string `xml:"lang,attr,omitempty"`
+	URL  string `xml:",chardata"`
+}
+
+type unmarshalDeltas struct {
+	Enclosures []*unmarshalEnclosure `xml:"enclosure"`
+}
+
+type unmarshalEnclosure struct {
+	URL                  string `xml:"url,attr"`
+	OS                   string `xml:"os,attr"`
+	Version              string `xml:"version,attr"`
+	DeltaFrom            string `xml:"deltaFrom,attr,omitempty"`
+	DSASignature         string `xml:"dsaSignature,attr,omitempty"`
+	EDSignature          string `xml:"edSignature,attr,omitempty"`
+	InstallerArguments   string `xml:"installerArguments,attr,omitempty"`
+	MinimumSystemVersion string `xml:"minimumSystemVersion,attr,omitempty"`
+	Length               int    `xml:"length,attr,omitempty"`
+	Type                 string `xml:"type,attr"`
+}
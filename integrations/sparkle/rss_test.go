@@ -33,11 +33,18 @@ func TestRSSMarshalUnmarshal(t *testing.T) {
 					},
 				},
 				{
-					Title:          "v1.1.0",
-					Description:    &sparkle.CdataString{"\n\t\t\t\t<h2>Test</h2>\n\t\t\t"},
-					PubDate:        "Mon, 02 Jan 2007 15:04:05 +0000",
-					Version:        "1.1.0",
-					CriticalUpdate: &sparkle.CriticalUpdate{Version: "1.0.0"},
+					Title:       "v1.1.0",
+					Description: &sparkle.CdataString{"\n\t\t\t\t<h2>Test</h2>\n\t\t\t"},
+					PubDate:     "Mon, 02 Jan 2007 15:04:05 +0000",
+					Version:     "1.1.0",
+					ReleaseNotesLink: []*sparkle.ReleaseNotesLink{
+						{URL: "https://example.com/1.1.0.html"},
+						{Lang: "de", URL: "https://example.com/1.1.0.de.html"},
+					},
+					FullReleaseNotesLink:  "https://example.com/changelog.html",
+					Channel:               "beta",
+					CriticalUpdate:        &sparkle.CriticalUpdate{Version: "1.0.0"},
+					PhasedRolloutInterval: 86400,
 					Enclosure: &sparkle.Enclosure{
 						URL:         "https://example.com/test_v1.1.0.dmg",
 						OS:          "macos",
@@ -46,6 +53,17 @@ func TestRSSMarshalUnmarshal(t *testing.T) {
 						Length:      100,
 						Type:        "application/x-apple-diskimage",
 					},
+					Deltas: &sparkle.Deltas{
+						Enclosures: []*sparkle.Enclosure{{
+							URL:         "https://example.com/test_v1.1.0-1.0.0.delta",
+							OS:          "macos",
+							Version:     "1.1.0",
+							DeltaFrom:   "1.0.0",
+							EDSignature: "test",
+							Length:      10,
+							Type:        "application/octet-stream",
+						}},
+					},
 				},
 			},
 		}},
@@ -74,8 +92,16 @@ func TestRSSMarshalUnmarshal(t *testing.T) {
 				<h2>Test</h2>
 			]]></description>
 			<sparkle:version>1.1.0</sparkle:version>
+			<sparkle:releaseNotesLink>https://example.com/1.1.0.html</sparkle:releaseNotesLink>
+			<sparkle:releaseNotesLink xml:lang="de">https://example.com/1.1.0.de.html</sparkle:releaseNotesLink>
+			<sparkle:fullReleaseNotesLink>https://example.com/changelog.html</sparkle:fullReleaseNotesLink>
+			<sparkle:channel>beta</sparkle:channel>
 			<sparkle:criticalUpdate sparkle:version="1.0.0"></sparkle:criticalUpdate>
+			<sparkle:phasedRolloutInterval>86400</sparkle:phasedRolloutInterval>
 			<enclosure url="https://example.com/test_v1.1.0.dmg" sparkle:os="macos" sparkle:version="1.1.0" sparkle:edSignature="test" length="100" type="application/x-apple-diskimage"></enclosure>
+			<sparkle:deltas>
+				<enclosure url="https://example.com/test_v1.1.0-1.0.0.delta" sparkle:os="macos" sparkle:version="1.1.0" sparkle:deltaFrom="1.0.0" sparkle:edSignature="test" length="10" type="application/octet-stream"></enclosure>
+			</sparkle:deltas>
 		</item>
 	</channel>
 </rss>`
@@ -0,0 +1,192 @@
+package sparkle
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kubri/kubri/pkg/crypto/dsa"
+	"github.com/kubri/kubri/pkg/crypto/ed25519"
+)
+
+var (
+	ErrNoPublicKey        = errors.New("no public key")
+	ErrDownloadFailed     = errors.New("download failed")
+	ErrLengthMismatch     = errors.New("length mismatch")
+	ErrMissingSignature   = errors.New("missing signature")
+	ErrMalformedSignature = errors.New("malformed signature")
+	ErrInvalidSignature   = errors.New("invalid signature")
+)
+
+// Problem is a broken enclosure in a feed.
+type Problem struct {
+	FileName string
+	Version  string
+	URL      string
+	Err      error
+}
+
+func (p *Problem) Error() string {
+	return fmt.Sprintf("%s: %s (%s): %s", p.FileName, p.Version, p.URL, p.Err)
+}
+
+func (p *Problem) Unwrap() error {
+	return p.Err
+}
+
+// Verify downloads the enclosures of all items in the existing feeds and
+// checks their length and signatures against the public keys. It returns the
+// enclosures which failed verification. Feeds which don't exist yet are skipped.
+// It fails with ErrNoPublicKey if neither a DSA nor an EdDSA key is configured.
+func Verify(ctx context.Context, c *Config) ([]*Problem, error) {
+	keys := getPublicKeys(c)
+	if keys.dsa == nil && keys.ed25519 == nil {
+		return nil, ErrNoPublicKey
+	}
+
+	feeds := c.Feeds
+	if len(feeds) == 0 {
+		feeds = []*Feed{{FileName: c.FileName}}
+	}
+
+	var problems []*Problem
+	for _, feed := range feeds {
+		rss, err := readRSS(ctx, c, feed.FileName)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Skipping %s: feed does not exist", feed.FileName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, channel := range rss.Channels {
+			for _, item := range channel.Items {
+				enclosures := []*Enclosure{item.Enclosure}
+				if item.Deltas != nil {
+					enclosures = append(enclosures, item.Deltas.Enclosures...)
+				}
+				for _, enclosure := range enclosures {
+					if enclosure == nil {
+						continue
+					}
+					if err = verifyEnclosure(ctx, c, keys, enclosure); err != nil {
+						problems = append(problems, &Problem{
+							FileName: feed.FileName,
+							Version:  item.Version,
+							URL:      enclosure.URL,
+							Err:      err,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// publicKeys are the keys to verify signatures with. Signatures are only
+// required for keys which are set, and only where Build would create them.
+type publicKeys struct {
+	dsa     *dsa.PublicKey
+	ed25519 ed25519.PublicKey
+}
+
+func getPublicKeys(c *Config) publicKeys {
+	keys := publicKeys{dsa: c.DSAPublicKey, ed25519: c.Ed25519PublicKey}
+	if keys.dsa == nil && c.DSAKey != nil {
+		keys.dsa = dsa.Public(c.DSAKey)
+	}
+	if keys.ed25519 == nil && c.Ed25519Key != nil {
+		keys.ed25519 = ed25519.Public(c.Ed25519Key)
+	}
+	return keys
+}
+
+func verifyEnclosure(ctx context.Context, c *Config, keys publicKeys, e *Enclosure) error {
+	b, err := download(ctx, c, e.URL)
+	if err != nil {
+		return err
+	}
+
+	if e.Length != len(b) {
+		return fmt.Errorf("%w: want %d got %d", ErrLengthMismatch, e.Length, len(b))
+	}
+
+	if keys.ed25519 != nil {
+		if e.EDSignature == "" {
+			return fmt.Errorf("%w: edSignature", ErrMissingSignature)
+		}
+		sig, err := base64.StdEncoding.DecodeString(e.EDSignature)
+		if err != nil {
+			return fmt.Errorf("%w: edSignature: %w", ErrMalformedSignature, err)
+		}
+		if !ed25519.Verify(keys.ed25519, b, sig) {
+			return fmt.Errorf("%w: edSignature", ErrInvalidSignature)
+		}
+	}
+
+	if keys.dsa != nil {
+		// Build only signs full Windows packages with DSA, but any DSA signature
+		// present is verified.
+		var os OS
+		_ = os.UnmarshalText([]byte(e.OS))
+		if e.DSASignature == "" {
+			if isOS(os, Windows) && e.DeltaFrom == "" {
+				return fmt.Errorf("%w: dsaSignature", ErrMissingSignature)
+			}
+			return nil
+		}
+		sig, err := base64.StdEncoding.DecodeString(e.DSASignature)
+		if err != nil {
+			return fmt.Errorf("%w: dsaSignature: %w", ErrMalformedSignature, err)
+		}
+		sum := sha1.Sum(b)
+		if !dsa.Verify(keys.dsa, sum[:], sig) {
+			return fmt.Errorf("%w: dsaSignature", ErrInvalidSignature)
+		}
+	}
+
+	return nil
+}
+
+// download reads files hosted on the target directly, and downloads all other
+// files over HTTP.
+func download(ctx context.Context, c *Config, rawURL string) ([]byte, error) {
+	if base, err := c.Target.URL(ctx, ""); err == nil {
+		if name, ok := strings.CutPrefix(rawURL, strings.TrimSuffix(base, "/")+"/"); ok {
+			if name, err = url.PathUnescape(name); err == nil {
+				if r, err := c.Target.NewReader(ctx, name); err == nil {
+					defer r.Close()
+					return io.ReadAll(r)
+				}
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDownloadFailed, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDownloadFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrDownloadFailed, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,183 @@
+package sparkle_test
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubri/kubri/integrations/sparkle"
+	"github.com/kubri/kubri/pkg/crypto/dsa"
+	"github.com/kubri/kubri/pkg/crypto/ed25519"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestVerify(t *testing.T) {
+	data := []byte("test")
+
+	dsaKey, err := dsa.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edKey, err := ed25519.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edSig, _ := ed25519.Sign(edKey, data)
+	sum := sha1.Sum(data)
+	dsaSig, _ := dsa.Sign(dsaKey, sum[:])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{file}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("file") == "missing.dmg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tgt, err := target.New(target.Config{Path: t.TempDir(), URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, b := range map[string][]byte{"ok.dmg": data, "changed.dmg": []byte("tset"), "delta.delta": data, "bad.delta": data} {
+		w, _ := tgt.NewWriter(t.Context(), name)
+		w.Write(b)
+		w.Close()
+	}
+
+	c := &sparkle.Config{
+		Target:           tgt,
+		DSAPublicKey:     dsa.Public(dsaKey),
+		Ed25519PublicKey: ed25519.Public(edKey),
+		Feeds:            []*sparkle.Feed{{FileName: "macos.xml"}, {FileName: "windows.xml"}, {FileName: "new.xml"}},
+	}
+
+	enclosure := func(url, os string, length int, edSig, dsaSig []byte) *sparkle.Enclosure {
+		return &sparkle.Enclosure{
+			URL:          url,
+			OS:           os,
+			Version:      "1.0.0",
+			EDSignature:  base64.StdEncoding.EncodeToString(edSig),
+			DSASignature: base64.StdEncoding.EncodeToString(dsaSig),
+			Length:       length,
+			Type:         "application/octet-stream",
+		}
+	}
+
+	malformed := enclosure(srv.URL+"/malformed.dmg", "macos", 4, nil, nil)
+	malformed.EDSignature = "not base64"
+
+	badDelta := enclosure("https://example.com/bad.delta", "windows", 4, edSig, edSig)
+	badDelta.DeltaFrom = "0.9.0"
+
+	feeds := map[string][]*sparkle.Item{
+		"macos.xml": {
+			{
+				Version:   "1.0.0",
+				Enclosure: enclosure("https://example.com/ok.dmg", "macos", 4, edSig, dsaSig),
+				Deltas: &sparkle.Deltas{Enclosures: []*sparkle.Enclosure{
+					enclosure("https://example.com/delta.delta", "macos", 4, edSig, nil),
+					badDelta,
+				}},
+			},
+			{Version: "1.0.0", Enclosure: enclosure("https://example.com/changed.dmg", "macos", 4, edSig, nil)},
+			{Version: "1.0.0", Enclosure: enclosure("https://example.com/ok.dmg", "macos", 10, edSig, nil)},
+			{Version: "1.0.0", Enclosure: enclosure(srv.URL+"/unsigned.dmg", "macos", 4, nil, nil)},
+			{Version: "1.0.0", Enclosure: enclosure(srv.URL+"/missing.dmg", "macos", 4, edSig, nil)},
+			{Version: "1.0.0", Enclosure: malformed},
+			{Version: "1.0.0", Enclosure: enclosure(srv.URL+"/invalid.dmg", "macos", 4, edSig, edSig)},
+		},
+		"windows.xml": {
+			{Version: "1.0.0", Enclosure: enclosure(srv.URL+"/ok.msi", "windows-x64", 4, edSig, dsaSig)},
+			{Version: "1.0.0", Enclosure: enclosure(srv.URL+"/unsigned.msi", "windows", 4, edSig, nil)},
+			{Version: "1.0.0", Enclosure: enclosure(srv.URL+"/invalid.msi", "windows", 4, edSig, edSig)},
+			{
+				Version:   "1.0.0",
+				Enclosure: &sparkle.Enclosure{URL: srv.URL + "/malformed.msi", OS: "windows", Length: 4, EDSignature: base64.StdEncoding.EncodeToString(edSig), DSASignature: "!"},
+			},
+		},
+	}
+
+	for name, items := range feeds {
+		w, _ := tgt.NewWriter(t.Context(), name)
+		err = xml.NewEncoder(w).Encode(&sparkle.RSS{Channels: []*sparkle.Channel{{Title: "Test", Items: items}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	want := []struct {
+		url string
+		err error
+	}{
+		{"https://example.com/bad.delta", sparkle.ErrInvalidSignature},
+		{"https://example.com/changed.dmg", sparkle.ErrInvalidSignature},
+		{"https://example.com/ok.dmg", sparkle.ErrLengthMismatch},
+		{srv.URL + "/unsigned.dmg", sparkle.ErrMissingSignature},
+		{srv.URL + "/missing.dmg", sparkle.ErrDownloadFailed},
+		{srv.URL + "/malformed.dmg", sparkle.ErrMalformedSignature},
+		{srv.URL + "/invalid.dmg", sparkle.ErrInvalidSignature},
+		{srv.URL + "/unsigned.msi", sparkle.ErrMissingSignature},
+		{srv.URL + "/invalid.msi", sparkle.ErrInvalidSignature},
+		{srv.URL + "/malformed.msi", sparkle.ErrMalformedSignature},
+	}
+
+	check := func(t *testing.T, c *sparkle.Config) {
+		t.Helper()
+
+		got, err := sparkle.Verify(t.Context(), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("want %d problems got %d: %v", len(want), len(got), got)
+		}
+		for i, p := range got {
+			if p.URL != want[i].url || !errors.Is(p, want[i].err) {
+				t.Errorf("want %s: %s got %s", want[i].url, want[i].err, p)
+			}
+		}
+	}
+
+	t.Run("PublicKeys", func(t *testing.T) {
+		check(t, c)
+	})
+
+	t.Run("PrivateKeys", func(t *testing.T) {
+		check(t, &sparkle.Config{Target: tgt, DSAKey: dsaKey, Ed25519Key: edKey, Feeds: c.Feeds})
+	})
+
+	t.Run("NoPublicKey", func(t *testing.T) {
+		if _, err := sparkle.Verify(t.Context(), &sparkle.Config{Target: tgt, Feeds: c.Feeds}); !errors.Is(err, sparkle.ErrNoPublicKey) {
+			t.Errorf("want %v got %v", sparkle.ErrNoPublicKey, err)
+		}
+	})
+
+	t.Run("NoFeed", func(t *testing.T) {
+		got, err := sparkle.Verify(t.Context(), &sparkle.Config{Target: tgt, Ed25519Key: edKey, FileName: "nope.xml"})
+		if err != nil || len(got) != 0 {
+			t.Errorf("should skip missing feed: %v %v", got, err)
+		}
+	})
+
+	t.Run("InvalidFeed", func(t *testing.T) {
+		w, _ := tgt.NewWriter(t.Context(), "invalid.xml")
+		w.Write([]byte("nope"))
+		w.Close()
+
+		if _, err := sparkle.Verify(t.Context(), &sparkle.Config{Target: tgt, Ed25519Key: edKey, FileName: "invalid.xml"}); err == nil {
+			t.Error("should fail for invalid feed")
+		}
+	})
+}
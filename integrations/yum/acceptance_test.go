@@ -53,7 +53,7 @@ func TestAcceptance(t *testing.T) {
 
 			for i, test := range tests {
 				t.Run(test.name, func(t *testing.T) {
-					config := &yum.Config{Source: src, Target: tgt, Version: test.version, PGPKey: pgpKey}
+					config := &yum.Config{Source: src, Target: tgt, Version: test.version, PGPKeys: []*pgp.PrivateKey{pgpKey}}
 					if err := yum.Build(t.Context(), config); err != nil {
 						t.Fatal(err)
 					}
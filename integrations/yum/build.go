@@ -2,9 +2,11 @@ package yum
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"unsafe"
 
@@ -14,21 +16,61 @@ import (
 )
 
 type Config struct {
-	Source     *source.Source
-	Version    string
-	Prerelease bool
-	Target     target.Target
-	PGPKey     *pgp.PrivateKey
+	Source       *source.Source
+	Version      string
+	Prerelease   bool
+	Target       target.Target
+	PGPKeys      []*pgp.PrivateKey
+	SignPackages bool
+	Name         string
+	Title        string
+	RepoOptions  map[string]string
+	UpdateInfo   bool
+	SQLite       string
+	Advisories   []Advisory
+	Groups       []byte
+	Modules      []byte
+
+	// Deprecated: Use PGPKeys. PGPKey is only used if PGPKeys is empty.
+	PGPKey *pgp.PrivateKey
 }
 
+var ErrMissingKey = errors.New("signing packages requires a pgp key")
+
 // Build creates or updates a YUM repository.
 func Build(ctx context.Context, c *Config) error {
+	cfg := *c
+	cfg.PGPKeys = pgp.Keys(c.PGPKeys, c.PGPKey)
+	c = &cfg
+
+	if c.SignPackages && len(c.PGPKeys) == 0 {
+		return ErrMissingKey
+	}
+	if c.Groups != nil {
+		if err := validateGroups(c.Groups); err != nil {
+			return err
+		}
+	}
+	if c.Modules != nil {
+		if err := validateModules(c.Modules); err != nil {
+			return err
+		}
+	}
+
 	repo, err := openRepo(ctx, c.Target)
 	if err != nil {
 		return err
 	}
 	defer os.RemoveAll(repo.dir)
 
+	repo.sqlite = c.SQLite
+	repo.groups = c.Groups
+	repo.modules = c.Modules
+
+	if c.SignPackages {
+		repo.signKey = c.PGPKeys[0]
+	}
+
 	version := c.Version
 	if v := getVersionConstraint(repo.primary.Package); v != "" {
 		version += "," + v
@@ -47,6 +89,7 @@ func Build(ctx context.Context, c *Config) error {
 
 	var hasReleases bool
 	for _, release := range releases {
+		var pkgs []*Package
 		for _, asset := range release.Assets {
 			if path.Ext(asset.Name) != ".rpm" {
 				continue
@@ -55,26 +98,45 @@ func Build(ctx context.Context, c *Config) error {
 			if err != nil {
 				return err
 			}
-			if err = repo.Add(b); err != nil {
+			p, err := repo.Add(b)
+			if err != nil {
 				return err
 			}
+			pkgs = append(pkgs, p)
 			hasReleases = true
 		}
+		if c.UpdateInfo && len(pkgs) > 0 {
+			u, err := newUpdate(release, pkgs, c.Advisories)
+			if err != nil {
+				return err
+			}
+			repo.AddUpdate(u)
+		}
 	}
 	if !hasReleases {
 		return nil
 	}
 
-	if err = repo.Write(c.PGPKey); err != nil {
+	if err = repo.Write(c.PGPKeys); err != nil {
 		return err
 	}
 
+	if c.Name != "" {
+		if err = writeRepoFiles(ctx, c, repo.dir); err != nil {
+			return err
+		}
+	}
+
 	err = target.CopyFS(ctx, c.Target, os.DirFS(repo.dir))
 	if err != nil {
 		return err
 	}
 
 	for _, path := range repo.files {
+		// Unchanged files keep the same checksum and path.
+		if _, err = os.Stat(filepath.Join(repo.dir, path)); err == nil {
+			continue
+		}
 		if err = c.Target.Remove(ctx, path); err != nil {
 			log.Printf("Failed to delete %s: %s", path, err)
 		}
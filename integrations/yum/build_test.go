@@ -29,7 +29,8 @@ func TestBuild(t *testing.T) {
 		c := &yum.Config{}
 		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
 		c.Target, _ = target.New(target.Config{Path: dir})
-		c.PGPKey, _ = pgp.NewPrivateKey("test", "test@example.com")
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key}
 
 		if err := yum.Build(t.Context(), c); err != nil {
 			t.Fatal(err)
@@ -52,11 +53,112 @@ func TestBuild(t *testing.T) {
 		}
 
 		pub, _ := pgp.UnmarshalPublicKey(got["repodata/repomd.xml.key"].Data)
-		if diff := cmp.Diff(pub, c.PGPKey, test.ComparePGPKeys()); diff != "" {
+		if diff := cmp.Diff(pub, key, test.ComparePGPKeys()); diff != "" {
 			t.Fatal(diff)
 		}
 		if !pgp.Verify(pub, got["repodata/repomd.xml"].Data, got["repodata/repomd.xml.asc"].Data) {
 			t.Error("should pass pgp verification")
 		}
 	})
+	t.Run("MultiplePGPKeys", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &yum.Config{}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir})
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		next, _ := pgp.NewPrivateKey("next", "next@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key, next}
+
+		if err := yum.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		wantKeys, _ := pgp.MarshalPublicKeys(pgp.PublicKeys(c.PGPKeys)...)
+		if diff := cmp.Diff(string(wantKeys), string(got["repodata/repomd.xml.key"].Data)); diff != "" {
+			t.Error(diff)
+		}
+		if !pgp.Verify(pgp.Public(key), got["repodata/repomd.xml"].Data, got["repodata/repomd.xml.asc"].Data) {
+			t.Error("should pass pgp verification with the first key")
+		}
+		if pgp.Verify(pgp.Public(next), got["repodata/repomd.xml"].Data, got["repodata/repomd.xml.asc"].Data) {
+			t.Error("should only be signed with the first key")
+		}
+	})
+	t.Run("RepoFiles", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &yum.Config{
+			Name:         "kubri-test",
+			Title:        "Kubri Test",
+			SignPackages: true,
+			RepoOptions:  map[string]string{"enabled": "0", "priority": "10"},
+		}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir, URL: "https://example.com/yum"})
+		key, _ := pgp.NewPrivateKey("test", "test@example.com")
+		c.PGPKeys = []*pgp.PrivateKey{key}
+
+		if err := yum.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		wantRepo := `[kubri-test]
+name=Kubri Test
+baseurl=https://example.com/yum/
+enabled=0
+gpgcheck=1
+repo_gpgcheck=1
+gpgkey=https://example.com/yum/repodata/repomd.xml.key
+priority=10
+`
+		if diff := cmp.Diff(wantRepo, string(got["kubri-test.repo"].Data)); diff != "" {
+			t.Error(diff)
+		}
+
+		wantZypper := `[kubri-test]
+name=Kubri Test
+baseurl=https://example.com/yum/
+type=rpm-md
+enabled=0
+autorefresh=1
+gpgcheck=1
+repo_gpgcheck=1
+pkg_gpgcheck=1
+gpgkey=https://example.com/yum/repodata/repomd.xml.key
+priority=10
+`
+		if diff := cmp.Diff(wantZypper, string(got["kubri-test-zypper.repo"].Data)); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("RepoFilesUnsigned", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c := &yum.Config{Name: "kubri-test"}
+		c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+		c.Target, _ = target.New(target.Config{Path: dir, URL: "https://example.com/yum"})
+
+		if err := yum.Build(t.Context(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		got := test.ReadFS(os.DirFS(dir))
+
+		wantRepo := `[kubri-test]
+name=kubri-test
+baseurl=https://example.com/yum/
+enabled=1
+gpgcheck=0
+repo_gpgcheck=0
+`
+		if diff := cmp.Diff(wantRepo, string(got["kubri-test.repo"].Data)); diff != "" {
+			t.Error(diff)
+		}
+	})
 }
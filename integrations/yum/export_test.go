@@ -5,3 +5,5 @@ import "time"
 func SetTime(t time.Time) {
 	timeNow = t.Unix
 }
+
+var SignRPM = signRPM
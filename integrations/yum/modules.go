@@ -0,0 +1,51 @@
+package yum
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidGroups  = errors.New("invalid comps.xml")
+	ErrInvalidModules = errors.New("invalid modules.yaml")
+)
+
+// validateGroups checks that the data is a comps.xml file.
+func validateGroups(b []byte) error {
+	var comps struct {
+		XMLName xml.Name `xml:"comps"`
+	}
+	if err := xml.Unmarshal(b, &comps); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidGroups, err)
+	}
+	return nil
+}
+
+// validateModules checks that each document in the modules.yaml file has a
+// document type, e.g. modulemd or modulemd-defaults.
+func validateModules(b []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for i := 0; ; i++ {
+		var doc struct {
+			Document string `yaml:"document"`
+		}
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			if i == 0 {
+				return fmt.Errorf("%w: no documents", ErrInvalidModules)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidModules, err)
+		}
+		if doc.Document == "" {
+			return fmt.Errorf("%w: document %d is missing its type", ErrInvalidModules, i+1)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package yum_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/integrations/yum"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestGroupsAndModules(t *testing.T) {
+	groups := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<comps>
+  <group>
+    <id>kubri</id>
+    <name>Kubri</name>
+    <packagelist>
+      <packagereq type="default">kubri-test</packagereq>
+    </packagelist>
+  </group>
+</comps>
+`)
+	modules := []byte(`---
+document: modulemd
+version: 2
+data:
+  name: kubri
+  stream: "1"
+  version: 1
+  context: c0ffee42
+  arch: x86_64
+  summary: Kubri
+  description: Kubri test module.
+  license:
+    module: [MIT]
+  artifacts:
+    rpms:
+      - kubri-test-0:2.0.0-1.x86_64
+...
+---
+document: modulemd-defaults
+version: 1
+data:
+  module: kubri
+  stream: "1"
+...
+`)
+
+	dir := t.TempDir()
+	c := &yum.Config{Groups: groups, Modules: modules}
+	c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+	c.Target, _ = target.New(target.Config{Path: dir})
+
+	// Build twice to ensure unchanged group and module files are kept.
+	c.Version = "<2"
+	if err := yum.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+	c.Version = ""
+	if err := yum.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "repodata/repomd.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var md yum.RepoMD
+	if err = xml.Unmarshal(b, &md); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]byte{"group": groups, "group_gz": groups, "modules": modules}
+	got := map[string][]byte{}
+	for _, d := range md.Data {
+		if _, ok := want[d.Type]; !ok {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, d.Location.HREF))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(d.Location.HREF) == ".gz" {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b, err = io.ReadAll(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got[d.Type] = b
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+
+	// Old files should be removed.
+	files, _ := filepath.Glob(filepath.Join(dir, "repodata/*"))
+	if len(files) != len(md.Data)+1 {
+		t.Errorf("want %d files got %d", len(md.Data)+1, len(files))
+	}
+}
+
+func TestGroupsAndModulesInvalid(t *testing.T) {
+	tests := []struct {
+		desc string
+		c    *yum.Config
+		err  error
+	}{
+		{"groups", &yum.Config{Groups: []byte("<nope/>")}, yum.ErrInvalidGroups},
+		{"modules", &yum.Config{Modules: []byte("data: {}\n")}, yum.ErrInvalidModules},
+		{"empty modules", &yum.Config{Modules: []byte{}}, yum.ErrInvalidModules},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if err := yum.Build(t.Context(), test.c); !errors.Is(err, test.err) {
+				t.Fatalf("should return %q got %q", test.err, err)
+			}
+		})
+	}
+}
@@ -11,20 +11,34 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cavaliergopher/rpm"
 
+	"github.com/kubri/kubri/integrations/yum/sqlite"
 	"github.com/kubri/kubri/pkg/crypto/pgp"
 	"github.com/kubri/kubri/target"
 )
 
 type repo struct {
-	primary   *MetaData
-	filelists *FileLists
-	other     *Other
+	primary    *MetaData
+	filelists  *FileLists
+	other      *Other
+	updateinfo *UpdateInfo
+
+	// sqlite is the compression format of the SQLite databases. Databases
+	// aren't written if empty.
+	sqlite string
+
+	// signKey is used to sign packages when they are added.
+	signKey *pgp.PrivateKey
+
+	// groups and modules hold the comps.xml and modules.yaml files to publish.
+	groups  []byte
+	modules []byte
 
 	dir   string
 	files []string
@@ -58,6 +72,12 @@ func openRepo(ctx context.Context, t target.Target) (*repo, error) {
 			r = &res.filelists
 		case "other":
 			r = &res.other
+		case "updateinfo":
+			r = &res.updateinfo
+		case "primary_db", "filelists_db", "other_db", "group", "group_gz", "modules":
+			// These are generated again on each build.
+			res.files = append(res.files, v.Location.HREF)
+			continue
 		default:
 			continue
 		}
@@ -75,10 +95,17 @@ func openRepo(ctx context.Context, t target.Target) (*repo, error) {
 }
 
 //nolint:funlen
-func (r *repo) Add(b []byte) error {
+func (r *repo) Add(b []byte) (*Package, error) {
+	if r.signKey != nil {
+		var err error
+		if b, err = signRPM(b, r.signKey); err != nil {
+			return nil, err
+		}
+	}
+
 	h, err := rpm.Read(bytes.NewReader(b))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	id := h.String()
@@ -125,8 +152,12 @@ func (r *repo) Add(b []byte) error {
 			HeaderRange: HeaderRange{Start: start, End: end},
 			Provides:    getEntries(h.Provides()),
 			Obsoletes:   getEntries(h.Obsoletes()),
-			Requires:    getEntries(h.Requires()),
+			Requires:    getEntries(filterRequires(h.Requires())),
 			Conflicts:   getEntries(h.Conflicts()),
+			Suggests:    getEntries(h.Suggests()),
+			Enhances:    getEntries(h.Enhances()),
+			Recommends:  getEntries(h.Recommends()),
+			Supplements: getEntries(h.Supplements()),
 			Files:       filterPackageFiles(files),
 		},
 	}
@@ -148,11 +179,15 @@ func (r *repo) Add(b []byte) error {
 		Version: p.Version,
 	})
 
-	return writeFile(filepath.Join(r.dir, p.Location.HREF), b)
+	if err = writeFile(filepath.Join(r.dir, p.Location.HREF), b); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
 }
 
 //nolint:funlen
-func (r *repo) Write(pgpKey *pgp.PrivateKey) error {
+func (r *repo) Write(pgpKeys []*pgp.PrivateKey) error {
 	md := &RepoMD{}
 
 	data := map[string]any{
@@ -160,8 +195,14 @@ func (r *repo) Write(pgpKey *pgp.PrivateKey) error {
 		"filelists": r.filelists,
 		"other":     r.other,
 	}
+	names := []string{"primary", "filelists", "other"}
 
-	for _, name := range []string{"primary", "filelists", "other"} {
+	if r.updateinfo != nil && len(r.updateinfo.Update) > 0 {
+		data["updateinfo"] = r.updateinfo
+		names = append(names, "updateinfo")
+	}
+
+	for _, name := range names {
 		raw, err := xmlMarshal(data[name])
 		if err != nil {
 			return err
@@ -189,6 +230,28 @@ func (r *repo) Write(pgpKey *pgp.PrivateKey) error {
 		md.Data = append(md.Data, d)
 	}
 
+	if r.sqlite != "" {
+		if err := r.writeDatabases(md); err != nil {
+			return err
+		}
+	}
+
+	if r.groups != nil {
+		// Group files are published both plain and compressed, as createrepo does.
+		if err := r.writeData(md, "group", "comps.xml", r.groups, false); err != nil {
+			return err
+		}
+		if err := r.writeData(md, "group_gz", "comps.xml", r.groups, true); err != nil {
+			return err
+		}
+	}
+
+	if r.modules != nil {
+		if err := r.writeData(md, "modules", "modules.yaml", r.modules, true); err != nil {
+			return err
+		}
+	}
+
 	md.Revision = timeNow()
 	filename := filepath.Join(r.dir, "repodata/repomd.xml")
 
@@ -200,8 +263,8 @@ func (r *repo) Write(pgpKey *pgp.PrivateKey) error {
 		return err
 	}
 
-	if pgpKey != nil {
-		key, err := pgp.MarshalPublicKey(pgp.Public(pgpKey))
+	if len(pgpKeys) > 0 {
+		key, err := pgp.MarshalPublicKeys(pgp.PublicKeys(pgpKeys)...)
 		if err != nil {
 			return err
 		}
@@ -209,7 +272,9 @@ func (r *repo) Write(pgpKey *pgp.PrivateKey) error {
 			return err
 		}
 
-		sig, err := pgp.Sign(pgpKey, b)
+		// Every public key is published, but only the first key signs, so clients
+		// can import the next key before it is used.
+		sig, err := pgp.Sign(pgpKeys[0], b)
 		if err != nil {
 			return err
 		}
@@ -221,6 +286,81 @@ func (r *repo) Write(pgpKey *pgp.PrivateKey) error {
 	return nil
 }
 
+func (r *repo) writeDatabases(md *RepoMD) error {
+	// Each database records the checksum of the XML metadata it was built from.
+	checksums := map[string]string{}
+	for _, d := range md.Data {
+		checksums[d.Type] = d.Checksum.Value
+	}
+
+	dbs := map[string]*sqlite.Database{
+		"primary":   primaryDB(r.primary, checksums["primary"]),
+		"filelists": filelistsDB(r.filelists, checksums["filelists"]),
+		"other":     otherDB(r.other, checksums["other"]),
+	}
+
+	for _, name := range []string{"primary", "filelists", "other"} {
+		raw, err := sqlite.Marshal(dbs[name])
+		if err != nil {
+			return err
+		}
+
+		b, ext, err := compressDB(raw, r.sqlite)
+		if err != nil {
+			return err
+		}
+
+		var d Data
+		d.Type = name + "_db"
+		d.Checksum = getChecksum(b)
+		d.OpenChecksum = getChecksum(raw)
+		d.Location.HREF = "repodata/" + d.Checksum.Value + "-" + name + ".sqlite" + ext
+		d.Timestamp = timeNow()
+		d.Size = len(b)
+		d.OpenSize = len(raw)
+		d.DatabaseVersion = dbVersion
+
+		if err = writeFile(filepath.Join(r.dir, d.Location.HREF), b); err != nil {
+			return err
+		}
+
+		md.Data = append(md.Data, d)
+	}
+
+	return nil
+}
+
+// writeData writes an additional metadata file and adds it to repomd.xml.
+func (r *repo) writeData(md *RepoMD, typ, name string, raw []byte, gz bool) error {
+	b := raw
+	if gz {
+		var err error
+		if b, err = compress(raw); err != nil {
+			return err
+		}
+		name += ".gz"
+	}
+
+	var d Data
+	d.Type = typ
+	d.Checksum = getChecksum(b)
+	d.Location.HREF = "repodata/" + d.Checksum.Value + "-" + name
+	d.Timestamp = timeNow()
+	d.Size = len(b)
+	if gz {
+		d.OpenChecksum = getChecksum(raw)
+		d.OpenSize = len(raw)
+	}
+
+	if err := writeFile(filepath.Join(r.dir, d.Location.HREF), b); err != nil {
+		return err
+	}
+
+	md.Data = append(md.Data, d)
+
+	return nil
+}
+
 func readXML(ctx context.Context, t target.Target, path string, res any) error {
 	rd, err := t.NewReader(ctx, path)
 	if err != nil {
@@ -272,19 +412,45 @@ func getEntries(d []rpm.Dependency) *Entries {
 
 	for _, d := range d {
 		e := Entry{
-			Name: d.Name(),
-			Ver:  d.Version(),
-			Rel:  d.Release(),
+			Name:  d.Name(),
+			Flags: getFlags(d.Flags()),
+			Ver:   d.Version(),
+			Rel:   d.Release(),
 		}
 		if e.Ver != "" {
 			e.Epoch = strconv.Itoa(d.Epoch())
 		}
+		if d.Flags()&(rpm.DepFlagPrereq|rpm.DepFlagScriptPre|rpm.DepFlagScriptPost) != 0 {
+			e.Pre = "1"
+		}
 		entries = append(entries, e)
 	}
 
 	return &Entries{entries}
 }
 
+func getFlags(flags int) string {
+	switch flags & (rpm.DepFlagLesser | rpm.DepFlagGreater | rpm.DepFlagEqual) {
+	case rpm.DepFlagEqual:
+		return "EQ"
+	case rpm.DepFlagLesser:
+		return "LT"
+	case rpm.DepFlagGreater:
+		return "GT"
+	case rpm.DepFlagLesserOrEqual:
+		return "LE"
+	case rpm.DepFlagGreaterOrEqual:
+		return "GE"
+	default:
+		return ""
+	}
+}
+
+// filterRequires removes rpmlib dependencies, which are only used by rpm itself.
+func filterRequires(d []rpm.Dependency) []rpm.Dependency {
+	return slices.DeleteFunc(d, func(d rpm.Dependency) bool { return strings.HasPrefix(d.Name(), "rpmlib(") })
+}
+
 func getChecksum(b []byte) Checksum {
 	sum := sha256.Sum256(b)
 	return Checksum{
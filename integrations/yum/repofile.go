@@ -0,0 +1,82 @@
+package yum
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// writeRepoFiles writes .repo files for YUM/DNF and zypper, so users can add
+// the repository without writing their own.
+func writeRepoFiles(ctx context.Context, c *Config, dir string) error {
+	url, err := c.Target.URL(ctx, "")
+	if err != nil {
+		return err
+	}
+	url = strings.TrimSuffix(url, "/") + "/"
+
+	gpgcheck, repoGPGCheck := "0", "0"
+	if len(c.PGPKeys) > 0 {
+		repoGPGCheck = "1"
+		// Packages can only be verified if they are signed.
+		if c.SignPackages {
+			gpgcheck = "1"
+		}
+	}
+
+	yum := [][2]string{
+		{"name", cmp.Or(c.Title, c.Name)},
+		{"baseurl", url},
+		{"enabled", "1"},
+		{"gpgcheck", gpgcheck},
+		{"repo_gpgcheck", repoGPGCheck},
+	}
+	zypper := [][2]string{
+		{"name", cmp.Or(c.Title, c.Name)},
+		{"baseurl", url},
+		{"type", "rpm-md"},
+		{"enabled", "1"},
+		{"autorefresh", "1"},
+		{"gpgcheck", repoGPGCheck},
+		{"repo_gpgcheck", repoGPGCheck},
+		{"pkg_gpgcheck", gpgcheck},
+	}
+	if len(c.PGPKeys) > 0 {
+		key := [2]string{"gpgkey", url + "repodata/repomd.xml.key"}
+		yum = append(yum, key)
+		zypper = append(zypper, key)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, c.Name+".repo"), marshalRepoFile(c.Name, yum, c.RepoOptions), 0o600)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, c.Name+"-zypper.repo"), marshalRepoFile(c.Name, zypper, c.RepoOptions), 0o600)
+}
+
+// marshalRepoFile returns a .repo file. Options override the defaults or are
+// appended in alphabetical order.
+func marshalRepoFile(id string, defaults [][2]string, options map[string]string) []byte {
+	var b strings.Builder
+	b.WriteString("[" + id + "]\n")
+
+	for _, kv := range defaults {
+		v, ok := options[kv[0]]
+		if !ok {
+			v = kv[1]
+		}
+		b.WriteString(kv[0] + "=" + v + "\n")
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(options)) {
+		if !slices.ContainsFunc(defaults, func(kv [2]string) bool { return kv[0] == k }) {
+			b.WriteString(k + "=" + options[k] + "\n")
+		}
+	}
+
+	return []byte(b.String())
+}
@@ -0,0 +1,243 @@
+package yum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+
+	"github.com/kubri/kubri/pkg/crypto/pgp"
+)
+
+var ErrInvalidRPM = errors.New("invalid rpm")
+
+// See https://rpm-software-management.github.io/rpm/manual/format_v4.html
+const (
+	leadSize = 96
+
+	tagHeaderSignatures = 62
+	sigTagDSA           = 267
+	sigTagRSA           = 268
+	sigTagPGP           = 1002
+	sigTagGPG           = 1005
+	sigTagReservedSpace = 1008
+
+	typeInt16       = 3
+	typeInt32       = 4
+	typeInt64       = 5
+	typeString      = 6
+	typeBin         = 7
+	typeStringArray = 8
+	typeI18NString  = 9
+)
+
+//nolint:gochecknoglobals
+var headerMagic = []byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0}
+
+type headerEntry struct {
+	tag   uint32
+	typ   uint32
+	count uint32
+	data  []byte
+}
+
+// signRPM adds an OpenPGP header signature to the package, replacing any
+// existing signatures. rpm only supports a single header signature, so only
+// one key can be used. RSA signatures are stored as RSAHEADER and signatures
+// from any other algorithm (e.g. EdDSA) as DSAHEADER, as rpm does.
+func signRPM(b []byte, key *pgp.PrivateKey) ([]byte, error) {
+	if len(b) < leadSize {
+		return nil, ErrInvalidRPM
+	}
+
+	entries, sigSize, err := readHeader(b[leadSize:])
+	if err != nil {
+		return nil, err
+	}
+	start := leadSize + pad(sigSize)
+
+	_, hdrSize, err := readHeader(b[start:])
+	if err != nil {
+		return nil, err
+	}
+
+	// The header signature covers the main header, including the magic.
+	sig, err := pgp.Sign(key, b[start:start+hdrSize])
+	if err != nil {
+		return nil, err
+	}
+	tag, err := signatureTag(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = slices.DeleteFunc(entries, func(e headerEntry) bool {
+		switch e.tag {
+		case sigTagDSA, sigTagRSA, sigTagPGP, sigTagGPG:
+			return true
+		}
+		return false
+	})
+	entries = append(entries, headerEntry{tag: tag, typ: typeBin, count: uint32(len(sig)), data: sig}) //nolint:gosec
+	slices.SortFunc(entries, func(a, b headerEntry) int { return int(a.tag) - int(b.tag) })
+
+	header := marshalHeader(entries)
+
+	// Shrink the reserved space to keep the package layout unchanged, if possible.
+	if i := slices.IndexFunc(entries, func(e headerEntry) bool { return e.tag == sigTagReservedSpace }); i >= 0 {
+		if n := pad(len(header)) - pad(sigSize); n > 0 && n < len(entries[i].data) {
+			entries[i].data = entries[i].data[n:]
+			entries[i].count = uint32(len(entries[i].data)) //nolint:gosec
+			header = marshalHeader(entries)
+		}
+	}
+
+	res := make([]byte, 0, leadSize+pad(len(header))+len(b)-start)
+	res = append(res, b[:leadSize]...)
+	res = append(res, header...)
+	res = append(res, make([]byte, pad(len(header))-len(header))...)
+	return append(res, b[start:]...), nil
+}
+
+// signatureTag returns the signature header tag for the signature's algorithm.
+func signatureTag(sig []byte) (uint32, error) {
+	p, err := packet.Read(bytes.NewReader(sig))
+	if err != nil {
+		return 0, err
+	}
+	s, ok := p.(*packet.Signature)
+	if !ok {
+		return 0, errors.New("invalid signature")
+	}
+	switch s.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSASignOnly:
+		return sigTagRSA, nil
+	default:
+		return sigTagDSA, nil
+	}
+}
+
+// readHeader returns the entries of a header, excluding the region tag, along
+// with the size of the header.
+func readHeader(b []byte) ([]headerEntry, int, error) {
+	if len(b) < 16 || !bytes.Equal(b[:8], headerMagic) {
+		return nil, 0, ErrInvalidRPM
+	}
+
+	count := int(binary.BigEndian.Uint32(b[8:]))
+	size := int(binary.BigEndian.Uint32(b[12:]))
+	index := b[16:]
+	if count > len(index)/16 || size > len(index)-count*16 {
+		return nil, 0, ErrInvalidRPM
+	}
+	data := index[count*16 : count*16+size]
+
+	entries := make([]headerEntry, 0, count)
+	for i := range count {
+		e := index[i*16:]
+		entry := headerEntry{
+			tag:   binary.BigEndian.Uint32(e),
+			typ:   binary.BigEndian.Uint32(e[4:]),
+			count: binary.BigEndian.Uint32(e[12:]),
+		}
+		if entry.tag == tagHeaderSignatures {
+			continue
+		}
+
+		off := int(binary.BigEndian.Uint32(e[8:]))
+		if off > len(data) {
+			return nil, 0, ErrInvalidRPM
+		}
+		n := entrySize(entry.typ, int(entry.count), data[off:])
+		if n < 0 || off+n > len(data) {
+			return nil, 0, ErrInvalidRPM
+		}
+		entry.data = data[off : off+n]
+
+		entries = append(entries, entry)
+	}
+
+	return entries, 16 + count*16 + size, nil
+}
+
+// marshalHeader returns a signature header with the entries, preceded by the
+// region tag.
+func marshalHeader(entries []headerEntry) []byte {
+	var data []byte
+	index := make([]byte, 0, (len(entries)+1)*16)
+	index = binary.BigEndian.AppendUint32(index, tagHeaderSignatures)
+	index = binary.BigEndian.AppendUint32(index, typeBin)
+	index = binary.BigEndian.AppendUint32(index, 0) // Set below.
+	index = binary.BigEndian.AppendUint32(index, 16)
+
+	for _, e := range entries {
+		if n := alignment(e.typ); len(data)%n != 0 {
+			data = append(data, make([]byte, n-len(data)%n)...)
+		}
+		index = binary.BigEndian.AppendUint32(index, e.tag)
+		index = binary.BigEndian.AppendUint32(index, e.typ)
+		index = binary.BigEndian.AppendUint32(index, uint32(len(data))) //nolint:gosec
+		index = binary.BigEndian.AppendUint32(index, e.count)
+		data = append(data, e.data...)
+	}
+
+	// The region trailer is stored at the end of the data, pointing back at the index.
+	binary.BigEndian.PutUint32(index[8:], uint32(len(data))) //nolint:gosec
+	data = binary.BigEndian.AppendUint32(data, tagHeaderSignatures)
+	data = binary.BigEndian.AppendUint32(data, typeBin)
+	data = binary.BigEndian.AppendUint32(data, uint32(-int32(len(index)))) //nolint:gosec
+	data = binary.BigEndian.AppendUint32(data, 16)
+
+	b := make([]byte, 0, 16+len(index)+len(data))
+	b = append(b, headerMagic...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(index)/16)) //nolint:gosec
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))     //nolint:gosec
+	b = append(b, index...)
+	return append(b, data...)
+}
+
+func entrySize(typ uint32, count int, data []byte) int {
+	switch typ {
+	case typeInt16:
+		return count * 2
+	case typeInt32:
+		return count * 4
+	case typeInt64:
+		return count * 8
+	case typeString:
+		count = 1
+		fallthrough
+	case typeStringArray, typeI18NString:
+		n := 0
+		for range count {
+			i := bytes.IndexByte(data[n:], 0)
+			if i < 0 {
+				return -1
+			}
+			n += i + 1
+		}
+		return n
+	default:
+		return count
+	}
+}
+
+func alignment(typ uint32) int {
+	switch typ {
+	case typeInt16:
+		return 2
+	case typeInt32:
+		return 4
+	case typeInt64:
+		return 8
+	default:
+		return 1
+	}
+}
+
+// pad returns the size padded to a multiple of 8 bytes.
+func pad(n int) int {
+	return (n + 7) &^ 7
+}
@@ -0,0 +1,186 @@
+package yum_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	pgpcrypto "github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/cavaliergopher/rpm"
+
+	"github.com/kubri/kubri/integrations/yum"
+	"github.com/kubri/kubri/pkg/crypto/pgp"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestSignPackages(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := pgp.NewPrivateKey("test", "test@example.com")
+	next, _ := pgp.NewPrivateKey("next", "next@example.com")
+
+	c := &yum.Config{SignPackages: true, PGPKeys: []*pgp.PrivateKey{key, next}}
+	c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+	c.Target, _ = target.New(target.Config{Path: dir})
+
+	if err := yum.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	var md yum.MetaData
+	readRepoData(t, dir, "primary", &md)
+	if len(md.Package) == 0 {
+		t.Fatal("no packages")
+	}
+
+	for _, p := range md.Package {
+		b, err := os.ReadFile(filepath.Join(dir, p.Location.HREF))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sum := sha256.Sum256(b); p.Checksum.Value != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: checksum mismatch", p.Location.HREF)
+		}
+		if p.Size.Package != len(b) {
+			t.Errorf("%s: want size %d got %d", p.Location.HREF, len(b), p.Size.Package)
+		}
+
+		verifyRPM(t, p.Location.HREF, b, key, p.Format.HeaderRange)
+	}
+}
+
+func TestSignRPMReplacesSignature(t *testing.T) {
+	b, err := os.ReadFile("../../testdata/v1.0.0/kubri-test-1.0.0-1.x86_64.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := pgp.NewPrivateKey("test", "test@example.com")
+	next, _ := pgp.NewPrivateKey("next", "next@example.com")
+
+	signed, err := yum.SignRPM(b, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err = yum.SignRPM(signed, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := rpm.Read(bytes.NewReader(signed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end := p.HeaderRange()
+	verifyRPM(t, "resigned", signed, next, yum.HeaderRange{Start: start, End: end})
+
+	if !bytes.Equal(signed[start:], b[len(b)-len(signed)+start:]) {
+		t.Error("header and payload should not change")
+	}
+}
+
+func TestSignRPMKeyAlgorithms(t *testing.T) {
+	b, err := os.ReadFile("../../testdata/v1.0.0/kubri-test-1.0.0-1.x86_64.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519, _ := pgp.NewPrivateKey("test", "test@example.com")
+	rsa, _ := pgpcrypto.GenerateKey("test", "test@example.com", "rsa", 2048)
+
+	for name, key := range map[string]*pgp.PrivateKey{"EdDSA": ed25519, "RSA": rsa} {
+		t.Run(name, func(t *testing.T) {
+			signed, err := yum.SignRPM(b, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := rpm.Read(bytes.NewReader(signed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, end := p.HeaderRange()
+			verifyRPM(t, name, signed, key, yum.HeaderRange{Start: start, End: end})
+			checkSig(t, signed, key)
+		})
+	}
+}
+
+func TestSignPackagesMissingKey(t *testing.T) {
+	c := &yum.Config{SignPackages: true}
+	c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+	c.Target, _ = target.New(target.Config{Path: t.TempDir()})
+
+	if err := yum.Build(t.Context(), c); !errors.Is(err, yum.ErrMissingKey) {
+		t.Fatalf("should return %q got %q", yum.ErrMissingKey, err)
+	}
+}
+
+func verifyRPM(t *testing.T, name string, b []byte, key *pgp.PrivateKey, hr yum.HeaderRange) {
+	t.Helper()
+
+	p, err := rpm.Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+
+	start, end := p.HeaderRange()
+	if start != hr.Start || end != hr.End {
+		t.Errorf("%s: want header range %d-%d got %d-%d", name, start, end, hr.Start, hr.End)
+	}
+
+	// rpm stores RSA signatures as RSAHEADER and all others as DSAHEADER.
+	tag, other := 267, 268
+	if key.GetEntity().PrimaryKey.PubKeyAlgo == packet.PubKeyAlgoRSA {
+		tag, other = 268, 267
+	}
+	sig := p.Signature.GetTag(tag)
+	if sig == nil {
+		t.Fatalf("%s: missing header signature", name)
+	}
+	if p.Signature.GetTag(other) != nil {
+		t.Errorf("%s: unexpected signature tag %d", name, other)
+	}
+	if !pgp.Verify(pgp.Public(key), b[start:end], sig.Bytes()) {
+		t.Errorf("%s: should pass pgp verification", name)
+	}
+
+	// The header digest must still match.
+	sum := sha256.Sum256(b[start:end])
+	if got := p.Signature.GetTag(273).String(); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("%s: header digest mismatch", name)
+	}
+}
+
+// checkSig verifies the package signature with rpmkeys, if available.
+func checkSig(t *testing.T, b []byte, key *pgp.PrivateKey) {
+	t.Helper()
+
+	if _, err := exec.LookPath("rpmkeys"); err != nil {
+		t.Skip("rpmkeys not found")
+	}
+
+	dir := t.TempDir()
+	pub, _ := pgp.MarshalPublicKey(pgp.Public(key))
+	if err := os.WriteFile(filepath.Join(dir, "key.asc"), pub, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "test.rpm"), b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	db := filepath.Join(dir, "db")
+	if out, err := exec.Command("rpmkeys", "--dbpath", db, "--import", filepath.Join(dir, "key.asc")).CombinedOutput(); err != nil {
+		t.Fatalf("failed to import key: %s", out)
+	}
+	out, err := exec.Command("rpmkeys", "--dbpath", db, "--checksig", filepath.Join(dir, "test.rpm")).CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "signatures OK") {
+		t.Errorf("rpmkeys --checksig failed: %s", out)
+	}
+}
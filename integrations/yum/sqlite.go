@@ -0,0 +1,261 @@
+package yum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/ulikunitz/xz"
+
+	"github.com/kubri/kubri/integrations/yum/sqlite"
+)
+
+// SQLite database compression formats.
+const (
+	SQLiteBzip2 = "bzip2"
+	SQLiteXZ    = "xz"
+)
+
+// dbVersion is the version of the createrepo database schema.
+const dbVersion = 10
+
+//nolint:gochecknoglobals
+var depTables = []string{
+	"requires", "provides", "conflicts", "obsoletes",
+	"suggests", "enhances", "recommends", "supplements",
+}
+
+func dbInfo(checksum string) sqlite.Table {
+	return sqlite.Table{
+		Name: "db_info",
+		SQL:  "CREATE TABLE db_info (dbversion INTEGER, checksum TEXT)",
+		Rows: [][]any{{dbVersion, checksum}},
+	}
+}
+
+func dbPackages(pkgIDs []string) sqlite.Table {
+	rows := make([][]any, len(pkgIDs))
+	for i, id := range pkgIDs {
+		rows[i] = []any{nil, id}
+	}
+	return sqlite.Table{
+		Name: "packages",
+		SQL:  "CREATE TABLE packages (  pkgKey INTEGER PRIMARY KEY,  pkgId TEXT)",
+		Rows: rows,
+	}
+}
+
+//nolint:funlen
+func primaryDB(md *MetaData, checksum string) *sqlite.Database {
+	packages := sqlite.Table{
+		Name: "packages",
+		SQL: "CREATE TABLE packages (  pkgKey INTEGER PRIMARY KEY,  pkgId TEXT,  name TEXT,  arch TEXT,  " +
+			"version TEXT,  epoch TEXT,  release TEXT,  summary TEXT,  description TEXT,  url TEXT,  " +
+			"time_file INTEGER,  time_build INTEGER,  rpm_license TEXT,  rpm_vendor TEXT,  rpm_group TEXT,  " +
+			"rpm_buildhost TEXT,  rpm_sourcerpm TEXT,  rpm_header_start INTEGER,  rpm_header_end INTEGER,  " +
+			"rpm_packager TEXT,  size_package INTEGER,  size_installed INTEGER,  size_archive INTEGER,  " +
+			"location_href TEXT,  location_base TEXT,  checksum_type TEXT)",
+	}
+	files := sqlite.Table{
+		Name: "files",
+		SQL:  "CREATE TABLE files (  name TEXT,  type TEXT,  pkgKey INTEGER)",
+	}
+	deps := make([]sqlite.Table, len(depTables))
+	for i, name := range depTables {
+		deps[i] = sqlite.Table{
+			Name: name,
+			SQL: "CREATE TABLE " + name + " (  name TEXT,  flags TEXT,  epoch TEXT,  version TEXT,  release TEXT,  " +
+				"pkgKey INTEGER )",
+		}
+	}
+	deps[0].SQL = strings.TrimSuffix(deps[0].SQL, ")") + ", pre BOOLEAN DEFAULT FALSE)"
+
+	for i, p := range md.Package {
+		key := int64(i) + 1
+
+		packages.Rows = append(packages.Rows, []any{
+			nil, p.Checksum.Value, p.Name, p.Arch, p.Version.Ver, p.Version.Epoch, p.Version.Rel,
+			p.Summary, p.Description, text(p.URL), p.Time.File, p.Time.Build, text(p.Format.License),
+			text(p.Format.Vendor), text(strings.Join(p.Format.Group, ", ")), text(p.Format.BuildHost),
+			text(p.Format.SourceRPM), int64(p.Format.HeaderRange.Start), int64(p.Format.HeaderRange.End),
+			text(p.Packager), int64(p.Size.Package), int64(p.Size.Installed), int64(p.Size.Archive), //nolint:gosec
+			p.Location.HREF, nil, p.Checksum.Type,
+		})
+
+		for _, f := range p.Format.Files {
+			files.Rows = append(files.Rows, []any{f, "file", key})
+		}
+
+		f := p.Format
+		for i, e := range []*Entries{
+			f.Requires, f.Provides, f.Conflicts, f.Obsoletes,
+			f.Suggests, f.Enhances, f.Recommends, f.Supplements,
+		} {
+			if e == nil {
+				continue
+			}
+			for _, e := range e.Entries {
+				row := []any{e.Name, text(e.Flags), text(e.Epoch), text(e.Ver), text(e.Rel), key}
+				if i == 0 {
+					row = append(row, e.Pre == "1")
+				}
+				deps[i].Rows = append(deps[i].Rows, row)
+			}
+		}
+	}
+
+	db := &sqlite.Database{
+		Tables: append([]sqlite.Table{dbInfo(checksum), packages, files}, deps...),
+		Indexes: []sqlite.Index{
+			{Name: "packagename", Table: "packages", SQL: "CREATE INDEX packagename ON packages (name)", Columns: []int{2}},
+			{Name: "packageId", Table: "packages", SQL: "CREATE INDEX packageId ON packages (pkgId)", Columns: []int{1}},
+			{Name: "filenames", Table: "files", SQL: "CREATE INDEX filenames ON files (name)", Columns: []int{0}},
+			{Name: "pkgfiles", Table: "files", SQL: "CREATE INDEX pkgfiles ON files (pkgKey)", Columns: []int{2}},
+		},
+	}
+
+	trigger := "CREATE TRIGGER removals AFTER DELETE ON packages  BEGIN    DELETE FROM files WHERE pkgKey = old.pkgKey;"
+	for _, name := range depTables {
+		db.Indexes = append(db.Indexes,
+			sqlite.Index{
+				Name:    "pkg" + name,
+				Table:   name,
+				SQL:     "CREATE INDEX pkg" + name + " on " + name + " (pkgKey)",
+				Columns: []int{5},
+			},
+			sqlite.Index{
+				Name:    name + "name",
+				Table:   name,
+				SQL:     "CREATE INDEX " + name + "name ON " + name + " (name)",
+				Columns: []int{0},
+			},
+		)
+		trigger += "    DELETE FROM " + name + " WHERE pkgKey = old.pkgKey;"
+	}
+	db.Triggers = []sqlite.Trigger{{Name: "removals", Table: "packages", SQL: trigger + "  END"}}
+
+	return db
+}
+
+func filelistsDB(fl *FileLists, checksum string) *sqlite.Database {
+	ids := make([]string, len(fl.Package))
+	filelist := sqlite.Table{
+		Name: "filelist",
+		SQL:  "CREATE TABLE filelist (  pkgKey INTEGER,  dirname TEXT,  filenames TEXT,  filetypes TEXT)",
+	}
+
+	for i, p := range fl.Package {
+		ids[i] = p.PkgID
+		key := int64(i) + 1
+
+		// Files are grouped by directory, with names separated by slashes.
+		var dirs []string
+		names := map[string][]string{}
+		types := map[string][]byte{}
+		for _, f := range p.Files {
+			dir, name := path.Split(f.Path)
+			dir = strings.TrimSuffix(dir, "/")
+			if _, ok := names[dir]; !ok {
+				dirs = append(dirs, dir)
+			}
+			names[dir] = append(names[dir], name)
+			typ := byte('f')
+			switch f.Type {
+			case "dir":
+				typ = 'd'
+			case "ghost":
+				typ = 'g'
+			}
+			types[dir] = append(types[dir], typ)
+		}
+		for _, dir := range dirs {
+			filelist.Rows = append(filelist.Rows, []any{key, dir, strings.Join(names[dir], "/"), string(types[dir])})
+		}
+	}
+
+	return &sqlite.Database{
+		Tables: []sqlite.Table{dbInfo(checksum), dbPackages(ids), filelist},
+		Indexes: []sqlite.Index{
+			{Name: "keyfile", Table: "filelist", SQL: "CREATE INDEX keyfile ON filelist (pkgKey)", Columns: []int{0}},
+			{Name: "pkgId", Table: "packages", SQL: "CREATE INDEX pkgId ON packages (pkgId)", Columns: []int{1}},
+			{Name: "dirnames", Table: "filelist", SQL: "CREATE INDEX dirnames ON filelist (dirname)", Columns: []int{1}},
+		},
+		Triggers: []sqlite.Trigger{{
+			Name:  "remove_filelist",
+			Table: "packages",
+			SQL: "CREATE TRIGGER remove_filelist AFTER DELETE ON packages  BEGIN    " +
+				"DELETE FROM filelist WHERE pkgKey = old.pkgKey;  END",
+		}},
+	}
+}
+
+func otherDB(o *Other, checksum string) *sqlite.Database {
+	ids := make([]string, len(o.Package))
+	for i, p := range o.Package {
+		ids[i] = p.PkgID
+	}
+
+	return &sqlite.Database{
+		Tables: []sqlite.Table{
+			dbInfo(checksum),
+			dbPackages(ids),
+			{
+				Name: "changelog",
+				SQL:  "CREATE TABLE changelog (  pkgKey INTEGER,  author TEXT,  date INTEGER,  changelog TEXT)",
+			},
+		},
+		Indexes: []sqlite.Index{
+			{Name: "keychange", Table: "changelog", SQL: "CREATE INDEX keychange ON changelog (pkgKey)", Columns: []int{0}},
+			{Name: "pkgId", Table: "packages", SQL: "CREATE INDEX pkgId ON packages (pkgId)", Columns: []int{1}},
+		},
+		Triggers: []sqlite.Trigger{{
+			Name:  "remove_changelogs",
+			Table: "packages",
+			SQL: "CREATE TRIGGER remove_changelogs AFTER DELETE ON packages  BEGIN    " +
+				"DELETE FROM changelog WHERE pkgKey = old.pkgKey;  END",
+		}},
+	}
+}
+
+// text returns nil for empty strings so they are stored as NULL, as createrepo does.
+func text(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func compressDB(p []byte, format string) ([]byte, string, error) {
+	var (
+		b   bytes.Buffer
+		w   io.WriteCloser
+		ext string
+		err error
+	)
+
+	switch format {
+	case SQLiteBzip2:
+		ext = ".bz2"
+		w, err = bzip2.NewWriter(&b, &bzip2.WriterConfig{Level: bzip2.BestCompression})
+	case SQLiteXZ:
+		ext = ".xz"
+		w, err = xz.NewWriter(&b)
+	default:
+		return nil, "", errors.New("unsupported sqlite compression: " + format)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err = w.Write(p); err != nil {
+		return nil, "", err
+	}
+	if err = w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return b.Bytes(), ext, nil
+}
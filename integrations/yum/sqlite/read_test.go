@@ -0,0 +1,376 @@
+package sqlite_test
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kubri/kubri/integrations/yum/sqlite"
+)
+
+// TestRead reads the database back with a minimal reader following the SQLite
+// file format, so the writer is tested without needing the sqlite3 CLI.
+func TestRead(t *testing.T) {
+	b, err := sqlite.Marshal(testDatabase())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newReader(t, b)
+
+	type entry struct {
+		typ, name, table string
+		root             int64
+	}
+	var schema []entry
+	for _, row := range r.table(1) {
+		schema = append(schema, entry{row.values[0].(string), row.values[1].(string), row.values[2].(string), row.values[3].(int64)})
+	}
+
+	roots := map[string]uint32{}
+	var triggers int
+	for _, e := range schema {
+		switch e.typ {
+		case "table", "index":
+			roots[e.name] = uint32(e.root)
+		case "trigger":
+			triggers++
+		}
+	}
+	if triggers != 21 {
+		t.Errorf("want 21 triggers got %d", triggers)
+	}
+
+	items := r.table(roots["items"])
+	if len(items) != count {
+		t.Fatalf("want %d items got %d", count, len(items))
+	}
+	if empty := r.table(roots["empty"]); len(empty) != 0 {
+		t.Errorf("want no rows in empty got %d", len(empty))
+	}
+
+	byName := map[string]row{}
+	for i, item := range items {
+		if item.rowid != int64(i+1) {
+			t.Fatalf("want rowid %d got %d", i+1, item.rowid)
+		}
+		if item.values[0] != nil {
+			t.Errorf("rowid %d: want NULL key got %v", item.rowid, item.values[0])
+		}
+		byName[item.values[1].(string)] = item
+	}
+
+	if item := byName["item-4990"]; item.rowid != 11 ||
+		item.values[2] != int64(-100) ||
+		!bytes.Equal(item.values[3].([]byte), []byte{0x0a}) ||
+		item.values[4] != int64(1) {
+		t.Errorf("item-4990: want 11|-100|0A|1 got %d|%v|%v|%v", item.rowid, item.values[2], item.values[3], item.values[4])
+	}
+
+	if item := byName[strings.Repeat("item-4900", 1000)]; item.rowid != 101 {
+		t.Errorf("item-4900: want rowid 101 got %d", item.rowid)
+	}
+
+	var minInt int
+	for _, item := range items {
+		if item.values[2] == int64(-1<<60) {
+			minInt++
+		}
+	}
+	if minInt != count/8 {
+		t.Errorf("want %d items with num %d got %d", count/8, int64(-1<<60), minInt)
+	}
+
+	for _, index := range []struct {
+		name   string
+		column int
+	}{{"itemname", 1}, {"itemnum", 2}} {
+		keys := r.index(roots[index.name])
+		if len(keys) != count {
+			t.Fatalf("%s: want %d entries got %d", index.name, count, len(keys))
+		}
+		for i, key := range keys {
+			item := items[key[1].(int64)-1]
+			if !equal(key[0], item.values[index.column]) {
+				t.Fatalf("%s: entry %d does not match row %d", index.name, i, item.rowid)
+			}
+			if i > 0 && compare(keys[i-1], key) >= 0 {
+				t.Fatalf("%s: entry %d is out of order", index.name, i)
+			}
+		}
+	}
+
+	if keys := r.index(roots["emptyname"]); len(keys) != 0 {
+		t.Errorf("emptyname: want no entries got %d", len(keys))
+	}
+
+	for page := uint32(1); page <= r.pages; page++ {
+		if !r.seen[page] {
+			t.Errorf("page %d is not used", page)
+		}
+	}
+}
+
+type row struct {
+	rowid  int64
+	values []any
+}
+
+type reader struct {
+	t        *testing.T
+	b        []byte
+	pageSize int
+	pages    uint32
+	seen     map[uint32]bool
+}
+
+func newReader(t *testing.T, b []byte) *reader {
+	t.Helper()
+
+	if !bytes.HasPrefix(b, []byte("SQLite format 3\x00")) {
+		t.Fatal("invalid header")
+	}
+
+	r := &reader{
+		t:        t,
+		b:        b,
+		pageSize: int(binary.BigEndian.Uint16(b[16:])),
+		pages:    binary.BigEndian.Uint32(b[28:]),
+		seen:     map[uint32]bool{},
+	}
+	if r.pageSize == 1 {
+		r.pageSize = 65536
+	}
+	if len(b) != int(r.pages)*r.pageSize {
+		t.Fatalf("want %d pages of %d bytes got %d bytes", r.pages, r.pageSize, len(b))
+	}
+
+	return r
+}
+
+// page returns the b-tree page and the offset of its header, marking it as used.
+func (r *reader) page(n uint32) ([]byte, int) {
+	r.t.Helper()
+	if n < 1 || n > r.pages {
+		r.t.Fatalf("page %d out of range", n)
+	}
+	if r.seen[n] {
+		r.t.Fatalf("page %d is used more than once", n)
+	}
+	r.seen[n] = true
+
+	p := r.b[int(n-1)*r.pageSize : int(n)*r.pageSize]
+	if n == 1 {
+		return p, 100
+	}
+	return p, 0
+}
+
+// cells returns the cell contents of a b-tree page along with its right-most
+// child pointer for interior pages.
+func (r *reader) cells(n uint32, leaf, interior byte) (typ byte, cells [][]byte, right uint32) {
+	r.t.Helper()
+
+	p, off := r.page(n)
+	typ = p[off]
+	header := 8
+	switch typ {
+	case leaf:
+	case interior:
+		header = 12
+		right = binary.BigEndian.Uint32(p[off+8:])
+	default:
+		r.t.Fatalf("page %d: unexpected page type %#x", n, typ)
+	}
+
+	count := int(binary.BigEndian.Uint16(p[off+3:]))
+	for i := range count {
+		cells = append(cells, p[binary.BigEndian.Uint16(p[off+header+2*i:]):])
+	}
+
+	return typ, cells, right
+}
+
+func (r *reader) table(root uint32) []row {
+	r.t.Helper()
+
+	typ, cells, right := r.cells(root, 0x0d, 0x05)
+	var rows []row
+	for _, cell := range cells {
+		if typ == 0x05 {
+			rows = append(rows, r.table(binary.BigEndian.Uint32(cell))...)
+			continue
+		}
+		size, n := varint(cell)
+		rowid, m := varint(cell[n:])
+		payload := r.payload(cell[n+m:], int(size), r.pageSize-35)
+		rows = append(rows, row{int64(rowid), r.record(payload)})
+	}
+	if typ == 0x05 {
+		rows = append(rows, r.table(right)...)
+	}
+
+	return rows
+}
+
+func (r *reader) index(root uint32) [][]any {
+	r.t.Helper()
+
+	typ, cells, right := r.cells(root, 0x0a, 0x02)
+	var keys [][]any
+	for _, cell := range cells {
+		if typ == 0x02 {
+			keys = append(keys, r.index(binary.BigEndian.Uint32(cell))...)
+			cell = cell[4:]
+		}
+		size, n := varint(cell)
+		payload := r.payload(cell[n:], int(size), (r.pageSize-12)*64/255-23)
+		keys = append(keys, r.record(payload))
+	}
+	if typ == 0x02 {
+		keys = append(keys, r.index(right)...)
+	}
+
+	return keys
+}
+
+// payload returns the full payload of a cell, following any overflow pages.
+func (r *reader) payload(cell []byte, size, maxLocal int) []byte {
+	r.t.Helper()
+
+	if size <= maxLocal {
+		return cell[:size]
+	}
+
+	minLocal := (r.pageSize-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(r.pageSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	payload := slices.Clone(cell[:local])
+	next := binary.BigEndian.Uint32(cell[local:])
+	for len(payload) < size {
+		if next == 0 {
+			r.t.Fatal("overflow chain ends early")
+		}
+		p, _ := r.page(next)
+		next = binary.BigEndian.Uint32(p)
+		payload = append(payload, p[4:min(4+size-len(payload), r.pageSize)]...)
+	}
+	if next != 0 {
+		r.t.Fatal("overflow chain is too long")
+	}
+
+	return payload
+}
+
+func (r *reader) record(b []byte) []any {
+	r.t.Helper()
+
+	size, n := varint(b)
+	header, body := b[n:size], b[size:]
+
+	var values []any
+	for len(header) > 0 {
+		serial, n := varint(header)
+		header = header[n:]
+
+		switch {
+		case serial == 0:
+			values = append(values, nil)
+		case serial <= 6:
+			width := []int{1, 2, 3, 4, 6, 8}[serial-1]
+			var v int64
+			for _, c := range body[:width] {
+				v = v<<8 | int64(c)
+			}
+			v = v << (64 - 8*width) >> (64 - 8*width) // Sign extend.
+			values = append(values, v)
+			body = body[width:]
+		case serial == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(body)))
+			body = body[8:]
+		case serial == 8, serial == 9:
+			values = append(values, int64(serial-8))
+		case serial >= 12 && serial%2 == 0:
+			width := int(serial-12) / 2
+			values = append(values, slices.Clone(body[:width]))
+			body = body[width:]
+		case serial >= 13:
+			width := int(serial-13) / 2
+			values = append(values, string(body[:width]))
+			body = body[width:]
+		default:
+			r.t.Fatalf("invalid serial type %d", serial)
+		}
+	}
+	if len(body) > 0 {
+		r.t.Fatalf("record has %d trailing bytes", len(body))
+	}
+
+	return values
+}
+
+func varint(b []byte) (uint64, int) {
+	var v uint64
+	for i := range 8 {
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v<<8 | uint64(b[8]), 9
+}
+
+func equal(a, b any) bool {
+	return compare([]any{a}, []any{b}) == 0
+}
+
+// compare compares index keys using the BINARY collation.
+func compare(a, b []any) int {
+	for i := range min(len(a), len(b)) {
+		if c := compareValue(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+func compareValue(a, b any) int {
+	switch a := a.(type) {
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	case int64:
+		switch b := b.(type) {
+		case nil:
+			return 1
+		case int64:
+			return cmp.Compare(a, b)
+		default:
+			return -1
+		}
+	case string:
+		switch b := b.(type) {
+		case nil, int64:
+			return 1
+		case string:
+			return strings.Compare(a, b)
+		default:
+			return -1
+		}
+	case []byte:
+		if b, ok := b.([]byte); ok {
+			return bytes.Compare(a, b)
+		}
+		return 1
+	}
+	return 0
+}
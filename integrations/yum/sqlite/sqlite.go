@@ -0,0 +1,533 @@
+// Package sqlite writes SQLite database files.
+//
+// It only supports creating a new database from complete tables in one go,
+// which is all that's needed to publish repository metadata without a cgo or
+// SQLite engine dependency. See https://www.sqlite.org/fileformat2.html.
+package sqlite
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	pageSize   = 4096
+	headerSize = 100
+)
+
+// Database holds the schema and contents of a database.
+type Database struct {
+	Tables   []Table
+	Indexes  []Index
+	Triggers []Trigger
+}
+
+// Table is a table along with its rows. Each row is assigned a rowid starting
+// from 1, in order. Columns declared as INTEGER PRIMARY KEY are aliases of the
+// rowid, so their values must be nil.
+//
+// Supported values are nil, bool, int, int64, string and []byte.
+type Table struct {
+	Name string
+	SQL  string
+	Rows [][]any
+}
+
+// Index is an index on a table. Columns holds the indexes of the indexed columns.
+type Index struct {
+	Name    string
+	Table   string
+	SQL     string
+	Columns []int
+}
+
+// Trigger is a trigger on a table.
+type Trigger struct {
+	Name  string
+	Table string
+	SQL   string
+}
+
+// Marshal returns the database file.
+func Marshal(db *Database) ([]byte, error) {
+	w := &writer{}
+	w.alloc() // Page 1 holds the file header & schema table.
+
+	schema := make([][]any, 0, len(db.Tables)+len(db.Indexes)+len(db.Triggers))
+	tables := make(map[string]*Table, len(db.Tables))
+
+	for i := range db.Tables {
+		t := &db.Tables[i]
+		root, err := w.writeTable(t.Rows, 0)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		tables[t.Name] = t
+		schema = append(schema, []any{"table", t.Name, t.Name, int64(root), t.SQL})
+	}
+
+	for _, idx := range db.Indexes {
+		t, ok := tables[idx.Table]
+		if !ok {
+			return nil, fmt.Errorf("index %s: unknown table %s", idx.Name, idx.Table)
+		}
+		root, err := w.writeIndex(t.Rows, idx.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", idx.Name, err)
+		}
+		schema = append(schema, []any{"index", idx.Name, idx.Table, int64(root), idx.SQL})
+	}
+
+	for _, t := range db.Triggers {
+		schema = append(schema, []any{"trigger", t.Name, t.Table, int64(0), t.SQL})
+	}
+
+	if _, err := w.writeTable(schema, 1); err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+
+	w.writeHeader()
+
+	return bytes.Join(w.pages, nil), nil
+}
+
+type writer struct {
+	pages [][]byte
+}
+
+func (w *writer) alloc() uint32 {
+	w.pages = append(w.pages, make([]byte, pageSize))
+	return uint32(len(w.pages)) //nolint:gosec // Can't overflow.
+}
+
+func (w *writer) writeHeader() {
+	h := w.pages[0][:headerSize]
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], pageSize)
+	h[18] = 1                                                // File format write version (legacy).
+	h[19] = 1                                                // File format read version (legacy).
+	h[21] = 64                                               // Maximum embedded payload fraction.
+	h[22] = 32                                               // Minimum embedded payload fraction.
+	h[23] = 32                                               // Leaf payload fraction.
+	binary.BigEndian.PutUint32(h[24:], 1)                    // File change counter.
+	binary.BigEndian.PutUint32(h[28:], uint32(len(w.pages))) //nolint:gosec // Can't overflow.
+	binary.BigEndian.PutUint32(h[40:], 1)                    // Schema cookie.
+	binary.BigEndian.PutUint32(h[44:], 4)                    // Schema format number.
+	binary.BigEndian.PutUint32(h[56:], 1)                    // Text encoding (UTF-8).
+	binary.BigEndian.PutUint32(h[92:], 1)                    // Version-valid-for number.
+	binary.BigEndian.PutUint32(h[96:], 3046000)              // SQLite version number.
+}
+
+// Page types.
+const (
+	interiorIndex = 0x02
+	interiorTable = 0x05
+	leafIndex     = 0x0a
+	leafTable     = 0x0d
+)
+
+type node struct {
+	typ      byte
+	cells    [][]byte // Interior cells exclude the left child pointer.
+	children []*node
+	page     uint32
+}
+
+// writeTable writes a table b-tree and returns its root page. If root is set
+// the root node is written to that page.
+func (w *writer) writeTable(rows [][]any, root uint32) (uint32, error) {
+	capacity := w.capacity(root)
+
+	nodes := []*node{{typ: leafTable}}
+	keys := []uint64{0}
+	size := 0
+
+	for i, row := range rows {
+		rowid := uint64(i) + 1 //nolint:gosec // Can't overflow.
+
+		rec, err := record(row)
+		if err != nil {
+			return 0, err
+		}
+
+		cell := appendVarint(nil, uint64(len(rec)))
+		cell = appendVarint(cell, rowid)
+		cell = append(cell, w.payload(rec, pageSize-35)...)
+
+		n := nodes[len(nodes)-1]
+		if size+len(cell)+2 > capacity-8 {
+			n = &node{typ: leafTable}
+			nodes = append(nodes, n)
+			keys = append(keys, 0)
+			size = 0
+		}
+		n.cells = append(n.cells, cell)
+		keys[len(keys)-1] = rowid
+		size += len(cell) + 2
+	}
+
+	// Build interior levels until there is a single root node.
+	for len(nodes) > 1 {
+		var (
+			parents    []*node
+			parentKeys []uint64
+		)
+		p := &node{typ: interiorTable}
+		size = 0
+		for i := 0; i < len(nodes); i++ {
+			p.children = append(p.children, nodes[i])
+			if i == len(nodes)-1 {
+				break
+			}
+			cell := appendVarint(nil, keys[i])
+			if size+4+len(cell)+2 > capacity-12 {
+				if i == len(nodes)-2 {
+					// Keep a cell for the last node.
+					p.children = p.children[:len(p.children)-1]
+					p.cells = p.cells[:len(p.cells)-1]
+					i--
+				}
+				// The current child becomes the right-most child.
+				parents = append(parents, p)
+				parentKeys = append(parentKeys, keys[i])
+				p = &node{typ: interiorTable}
+				size = 0
+				continue
+			}
+			p.cells = append(p.cells, cell)
+			size += 4 + len(cell) + 2
+		}
+		parents = append(parents, p)
+		parentKeys = append(parentKeys, keys[len(keys)-1])
+		nodes, keys = parents, parentKeys
+	}
+
+	return w.writeTree(nodes[0], root), nil
+}
+
+type indexEntry struct {
+	key []any
+	rec []byte
+}
+
+// writeIndex writes an index b-tree and returns its root page.
+func (w *writer) writeIndex(rows [][]any, columns []int) (uint32, error) {
+	capacity := w.capacity(0)
+
+	entries := make([]indexEntry, len(rows))
+	for i, row := range rows {
+		key := make([]any, 0, len(columns)+1)
+		for _, c := range columns {
+			if c >= len(row) {
+				return 0, fmt.Errorf("invalid column %d", c)
+			}
+			key = append(key, row[c])
+		}
+		key = append(key, int64(i)+1)
+		rec, err := record(key)
+		if err != nil {
+			return 0, err
+		}
+		entries[i] = indexEntry{key, rec}
+	}
+	slices.SortStableFunc(entries, func(a, b indexEntry) int { return compareKeys(a.key, b.key) })
+
+	cells := make([][]byte, len(entries))
+	for i, e := range entries {
+		cells[i] = appendVarint(nil, uint64(len(e.rec)))
+		cells[i] = append(cells[i], w.payload(e.rec, (pageSize-12)*64/255-23)...)
+	}
+
+	// Pack leaves. In index b-trees each entry is stored once, so the entry
+	// separating two nodes moves up to the parent.
+	var (
+		nodes []*node
+		seps  [][]byte
+	)
+	n := &node{typ: leafIndex}
+	size := 0
+	for i := 0; i < len(cells); i++ {
+		if size+len(cells[i])+2 > capacity-8 && len(n.cells) > 0 {
+			if i == len(cells)-1 {
+				// Keep an entry for the last leaf.
+				i--
+				n.cells = n.cells[:len(n.cells)-1]
+			}
+			nodes = append(nodes, n)
+			seps = append(seps, cells[i])
+			n = &node{typ: leafIndex}
+			size = 0
+			continue
+		}
+		n.cells = append(n.cells, cells[i])
+		size += len(cells[i]) + 2
+	}
+	nodes = append(nodes, n)
+
+	for len(nodes) > 1 {
+		var (
+			parents    []*node
+			parentSeps [][]byte
+		)
+		p := &node{typ: interiorIndex}
+		size = 0
+		for i := 0; i < len(nodes); i++ {
+			p.children = append(p.children, nodes[i])
+			if i == len(nodes)-1 {
+				break
+			}
+			if size+4+len(seps[i])+2 > capacity-12 {
+				if i == len(nodes)-2 {
+					// Keep a cell for the last node.
+					p.children = p.children[:len(p.children)-1]
+					p.cells = p.cells[:len(p.cells)-1]
+					i--
+				}
+				parents = append(parents, p)
+				parentSeps = append(parentSeps, seps[i])
+				p = &node{typ: interiorIndex}
+				size = 0
+				continue
+			}
+			p.cells = append(p.cells, seps[i])
+			size += 4 + len(seps[i]) + 2
+		}
+		parents = append(parents, p)
+		nodes, seps = parents, parentSeps
+	}
+
+	return w.writeTree(nodes[0], 0), nil
+}
+
+func (w *writer) capacity(root uint32) int {
+	if root == 1 {
+		// Page 1 also holds the file header, so leave room in case the root
+		// node is a leaf.
+		return pageSize - headerSize
+	}
+	return pageSize
+}
+
+// writeTree assigns pages to the nodes and writes them.
+func (w *writer) writeTree(n *node, page uint32) uint32 {
+	if page == 0 {
+		page = w.alloc()
+	}
+	n.page = page
+	for _, c := range n.children {
+		w.writeTree(c, 0)
+	}
+
+	b := w.pages[page-1]
+	off := 0
+	if page == 1 {
+		off = headerSize
+	}
+
+	interior := n.typ == interiorIndex || n.typ == interiorTable
+	ptr := off + 8
+	if interior {
+		ptr += 4
+	}
+
+	content := pageSize
+	for i, cell := range n.cells {
+		content -= len(cell)
+		copy(b[content:], cell)
+		if interior {
+			// Interior cells start with the left child pointer.
+			content -= 4
+			binary.BigEndian.PutUint32(b[content:], n.children[i].page)
+		}
+		binary.BigEndian.PutUint16(b[ptr+2*i:], uint16(content)) //nolint:gosec // Can't overflow.
+	}
+
+	b[off] = n.typ
+	binary.BigEndian.PutUint16(b[off+3:], uint16(len(n.cells))) //nolint:gosec // Can't overflow.
+	binary.BigEndian.PutUint16(b[off+5:], uint16(content))      //nolint:gosec // Can't overflow.
+	if interior {
+		binary.BigEndian.PutUint32(b[off+8:], n.children[len(n.children)-1].page)
+	}
+
+	return page
+}
+
+// payload returns the part of the payload stored in the cell, writing the rest
+// to overflow pages.
+func (w *writer) payload(p []byte, maxLocal int) []byte {
+	if len(p) <= maxLocal {
+		return p
+	}
+
+	minLocal := (pageSize-12)*32/255 - 23
+	local := minLocal + (len(p)-minLocal)%(pageSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	var first, prev uint32
+	for rest := p[local:]; len(rest) > 0; {
+		page := w.alloc()
+		if prev == 0 {
+			first = page
+		} else {
+			binary.BigEndian.PutUint32(w.pages[prev-1], page)
+		}
+		n := copy(w.pages[page-1][4:], rest)
+		rest = rest[n:]
+		prev = page
+	}
+
+	return binary.BigEndian.AppendUint32(slices.Clip(p[:local]), first)
+}
+
+var errUnsupportedType = errors.New("unsupported type")
+
+// record encodes the values in the record format.
+func record(values []any) ([]byte, error) {
+	var header, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			header = appendVarint(header, 0)
+		case bool:
+			header = appendVarint(header, uint64(8+boolInt(v)))
+		case int:
+			header, body = appendInt(header, body, int64(v))
+		case int64:
+			header, body = appendInt(header, body, v)
+		case string:
+			header = appendVarint(header, uint64(len(v))*2+13)
+			body = append(body, v...)
+		case []byte:
+			header = appendVarint(header, uint64(len(v))*2+12)
+			body = append(body, v...)
+		default:
+			return nil, fmt.Errorf("%w: %T", errUnsupportedType, v)
+		}
+	}
+
+	// The header size includes the size varint itself.
+	size := len(header) + 1
+	for varintLen(uint64(size)) != size-len(header) { //nolint:gosec // Can't overflow.
+		size = len(header) + varintLen(uint64(size)) //nolint:gosec // Can't overflow.
+	}
+
+	b := make([]byte, 0, size+len(body))
+	b = appendVarint(b, uint64(size)) //nolint:gosec // Can't overflow.
+	b = append(b, header...)
+	return append(b, body...), nil
+}
+
+func appendInt(header, body []byte, v int64) ([]byte, []byte) {
+	switch {
+	case v == 0:
+		return append(header, 8), body
+	case v == 1:
+		return append(header, 9), body
+	case v >= -1<<7 && v < 1<<7:
+		return append(header, 1), append(body, byte(v))
+	case v >= -1<<15 && v < 1<<15:
+		return append(header, 2), binary.BigEndian.AppendUint16(body, uint16(v)) //nolint:gosec // Intentional.
+	case v >= -1<<23 && v < 1<<23:
+		return append(header, 3), append(body, byte(v>>16), byte(v>>8), byte(v))
+	case v >= -1<<31 && v < 1<<31:
+		return append(header, 4), binary.BigEndian.AppendUint32(body, uint32(v)) //nolint:gosec // Intentional.
+	case v >= -1<<47 && v < 1<<47:
+		b := binary.BigEndian.AppendUint64(nil, uint64(v)) //nolint:gosec // Intentional.
+		return append(header, 5), append(body, b[2:]...)
+	default:
+		return append(header, 6), binary.BigEndian.AppendUint64(body, uint64(v)) //nolint:gosec // Intentional.
+	}
+}
+
+// appendVarint appends a big-endian variable length integer.
+func appendVarint(b []byte, v uint64) []byte {
+	if v > 0x00ffffffffffffff {
+		var buf [9]byte
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+
+	var buf [8]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7f)
+	for v >>= 7; v != 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
+
+func varintLen(v uint64) int {
+	return len(appendVarint(nil, v))
+}
+
+// compareKeys compares index keys using the BINARY collation.
+func compareKeys(a, b []any) int {
+	for i := range a {
+		if c := compareValues(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	ca, cb := class(a), class(b)
+	if ca != cb {
+		return cmp.Compare(ca, cb)
+	}
+	switch a := a.(type) {
+	case string:
+		return cmp.Compare(a, b.(string)) //nolint:forcetypeassert
+	case []byte:
+		return bytes.Compare(a, b.([]byte)) //nolint:forcetypeassert
+	case nil:
+		return 0
+	default:
+		return cmp.Compare(toInt(a), toInt(b))
+	}
+}
+
+// class returns the sort order of the storage class of the value.
+func class(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case string:
+		return 2
+	case []byte:
+		return 3
+	default:
+		return 1
+	}
+}
+
+func toInt(v any) int64 {
+	switch v := v.(type) {
+	case bool:
+		return int64(boolInt(v))
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return 0
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
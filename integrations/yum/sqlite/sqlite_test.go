@@ -0,0 +1,108 @@
+package sqlite_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kubri/kubri/integrations/yum/sqlite"
+)
+
+// testDatabase returns a database with enough rows to need interior pages,
+// some payloads spilling into overflow pages and enough schema entries to need
+// an interior root on the first page.
+func testDatabase() *sqlite.Database {
+	db := &sqlite.Database{
+		Tables: []sqlite.Table{
+			{
+				Name: "items",
+				SQL:  "CREATE TABLE items (  key INTEGER PRIMARY KEY,  name TEXT,  num INTEGER,  data BLOB,  flag BOOLEAN)",
+			},
+			{
+				Name: "empty",
+				SQL:  "CREATE TABLE empty (  name TEXT)",
+			},
+		},
+		Indexes: []sqlite.Index{
+			{Name: "itemname", Table: "items", SQL: "CREATE INDEX itemname ON items (name)", Columns: []int{1}},
+			{Name: "itemnum", Table: "items", SQL: "CREATE INDEX itemnum ON items (num)", Columns: []int{2}},
+			{Name: "emptyname", Table: "empty", SQL: "CREATE INDEX emptyname ON empty (name)", Columns: []int{0}},
+		},
+		Triggers: []sqlite.Trigger{
+			{
+				Name:  "removals",
+				Table: "items",
+				SQL:   "CREATE TRIGGER removals AFTER DELETE ON items BEGIN DELETE FROM empty; END",
+			},
+		},
+	}
+
+	for i := range count {
+		name := "item-" + strconv.Itoa(count-i)
+		if i%100 == 0 {
+			name = strings.Repeat(name, 1000)
+		}
+		db.Tables[0].Rows = append(db.Tables[0].Rows, []any{nil, name, nums[i%len(nums)], []byte{byte(i)}, i%2 == 0})
+	}
+
+	for i := range 20 {
+		name := "trigger" + strconv.Itoa(i)
+		db.Triggers = append(db.Triggers, sqlite.Trigger{
+			Name:  name,
+			Table: "items",
+			SQL:   "CREATE TRIGGER " + name + " AFTER DELETE ON items BEGIN DELETE FROM empty" + strings.Repeat(" ", 1000) + "; END",
+		})
+	}
+
+	return db
+}
+
+const count = 5000
+
+//nolint:gochecknoglobals
+var nums = []int64{0, 1, -100, 1000, -1 << 20, 1 << 30, 1 << 40, -1 << 60}
+
+func TestMarshal(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not found")
+	}
+
+	db := testDatabase()
+
+	b, err := sqlite.Marshal(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+	if err = os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"PRAGMA integrity_check", "ok"},
+		{"SELECT count(*) FROM items", strconv.Itoa(count)},
+		{"SELECT count(*) FROM empty", "0"},
+		{"SELECT key, num, hex(data), flag FROM items WHERE name = 'item-4990'", "11|-100|0A|1"},
+		{"SELECT key, length(name) FROM items WHERE name LIKE 'item-4900%'", "101|9000"},
+		{"SELECT count(*) FROM items WHERE num = -1152921504606846976", strconv.Itoa(count / 8)},
+		{"SELECT count(*) FROM items INDEXED BY itemname WHERE name >= 'item-4' AND name < 'item-5'", "1111"},
+		{"SELECT count(*) FROM sqlite_master WHERE type = 'trigger'", "21"},
+	}
+
+	for _, test := range tests {
+		out, err := exec.Command("sqlite3", path, test.query).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %s", test.query, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != test.want {
+			t.Errorf("%s: want %q got %q", test.query, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package yum_test
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/ulikunitz/xz"
+
+	"github.com/kubri/kubri/integrations/yum"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestSQLite(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not found")
+	}
+
+	for _, format := range []string{yum.SQLiteBzip2, yum.SQLiteXZ} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+
+			c := &yum.Config{SQLite: format}
+			c.Source, _ = source.New(source.Config{Path: "../../testdata"})
+			c.Target, _ = target.New(target.Config{Path: dir})
+
+			// Build twice to ensure databases are replaced.
+			c.Version = "<2"
+			if err := yum.Build(t.Context(), c); err != nil {
+				t.Fatal(err)
+			}
+			c.Version = ""
+			if err := yum.Build(t.Context(), c); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(filepath.Join(dir, "repodata/repomd.xml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var md yum.RepoMD
+			if err = xml.Unmarshal(b, &md); err != nil {
+				t.Fatal(err)
+			}
+
+			checksums := map[string]string{}
+			dbs := map[string]string{}
+			for _, d := range md.Data {
+				name, ok := strings.CutSuffix(d.Type, "_db")
+				if !ok {
+					checksums[d.Type] = d.Checksum.Value
+					continue
+				}
+				if d.DatabaseVersion != 10 {
+					t.Errorf("%s: want database version 10 got %d", d.Type, d.DatabaseVersion)
+				}
+				dbs[name] = readDB(t, filepath.Join(dir, d.Location.HREF))
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, "repodata/*.sqlite.*"))
+			if len(files) != 3 {
+				t.Errorf("want 3 databases got %d", len(files))
+			}
+
+			tests := []struct {
+				db    string
+				query string
+				want  string
+			}{
+				{"primary", "PRAGMA integrity_check", "ok"},
+				{"primary", "SELECT dbversion, checksum FROM db_info", "10|" + checksums["primary"]},
+				{"primary", "SELECT count(*) FROM packages", "6"},
+				{"primary", "SELECT name, arch, version, epoch, release FROM packages WHERE location_href LIKE '%1.0.0-1.x86_64%'", "kubri-test|x86_64|1.0.0|0|1"}, //nolint:lll
+				{"primary", "SELECT count(*) FROM provides WHERE name = 'kubri-test' AND flags = 'EQ'", "6"},
+				{"primary", "SELECT DISTINCT name FROM recommends", "git"},
+				{"primary", "SELECT DISTINCT name FROM suggests", "wget"},
+				{"filelists", "PRAGMA integrity_check", "ok"},
+				{"filelists", "SELECT dbversion, checksum FROM db_info", "10|" + checksums["filelists"]},
+				{"filelists", "SELECT count(DISTINCT pkgKey) FROM filelist", "6"},
+				{"other", "PRAGMA integrity_check", "ok"},
+				{"other", "SELECT dbversion, checksum FROM db_info", "10|" + checksums["other"]},
+				{"other", "SELECT count(*) FROM packages", "6"},
+			}
+
+			for _, test := range tests {
+				out, err := exec.Command("sqlite3", dbs[test.db], test.query).CombinedOutput()
+				if err != nil {
+					t.Fatalf("%s: %s: %s", test.db, test.query, out)
+				}
+				if got := strings.TrimSpace(string(out)); got != test.want {
+					t.Errorf("%s: %s: want %q got %q", test.db, test.query, test.want, got)
+				}
+			}
+		})
+	}
+}
+
+func readDB(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader
+	switch filepath.Ext(path) {
+	case ".bz2":
+		r, err = bzip2.NewReader(f, nil)
+	case ".xz":
+		r, err = xz.NewReader(f)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "db.sqlite")
+	if err = os.WriteFile(out, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
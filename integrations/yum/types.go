@@ -12,13 +12,14 @@ type RepoMD struct {
 }
 
 type Data struct {
-	Type         string   `xml:"type,attr"`
-	Checksum     Checksum `xml:"checksum"`
-	OpenChecksum Checksum `xml:"open-checksum"`
-	Location     Location `xml:"location"`
-	Timestamp    int64    `xml:"timestamp"`
-	Size         int      `xml:"size,omitempty"`
-	OpenSize     int      `xml:"open-size,omitempty"`
+	Type            string   `xml:"type,attr"`
+	Checksum        Checksum `xml:"checksum"`
+	OpenChecksum    Checksum `xml:"open-checksum"`
+	Location        Location `xml:"location"`
+	Timestamp       int64    `xml:"timestamp"`
+	Size            int      `xml:"size,omitempty"`
+	OpenSize        int      `xml:"open-size,omitempty"`
+	DatabaseVersion int      `xml:"database_version,omitempty"`
 }
 
 type Checksum struct {
@@ -27,6 +28,15 @@ type Checksum struct {
 	Value string `xml:",chardata"`
 }
 
+// MarshalXML omits empty checksums, e.g. the open checksum of uncompressed files.
+func (c Checksum) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if c == (Checksum{}) {
+		return nil
+	}
+	type checksum Checksum
+	return enc.EncodeElement(checksum(c), start)
+}
+
 type Location struct {
 	HREF string `xml:"href,attr"`
 }
@@ -88,6 +98,10 @@ type Format struct {
 	Obsoletes   *Entries    `xml:"rpm:obsoletes,omitempty"`
 	Requires    *Entries    `xml:"rpm:requires,omitempty"`
 	Conflicts   *Entries    `xml:"rpm:conflicts,omitempty"`
+	Suggests    *Entries    `xml:"rpm:suggests,omitempty"`
+	Enhances    *Entries    `xml:"rpm:enhances,omitempty"`
+	Recommends  *Entries    `xml:"rpm:recommends,omitempty"`
+	Supplements *Entries    `xml:"rpm:supplements,omitempty"`
 	Files       []string    `xml:"file,omitempty"`
 }
 
@@ -106,6 +120,10 @@ func (f *Format) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
 		Obsoletes   *entries    `xml:"obsoletes"`
 		Requires    *entries    `xml:"requires"`
 		Conflicts   *entries    `xml:"conflicts"`
+		Suggests    *entries    `xml:"suggests"`
+		Enhances    *entries    `xml:"enhances"`
+		Recommends  *entries    `xml:"recommends"`
+		Supplements *entries    `xml:"supplements"`
 		Files       []string    `xml:"file"`
 	}
 	if err := dec.DecodeElement(&data, &start); err != nil {
@@ -123,6 +141,10 @@ func (f *Format) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
 		Obsoletes:   (*Entries)(data.Obsoletes),
 		Requires:    (*Entries)(data.Requires),
 		Conflicts:   (*Entries)(data.Conflicts),
+		Suggests:    (*Entries)(data.Suggests),
+		Enhances:    (*Entries)(data.Enhances),
+		Recommends:  (*Entries)(data.Recommends),
+		Supplements: (*Entries)(data.Supplements),
 		Files:       data.Files,
 	}
 
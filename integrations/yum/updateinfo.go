@@ -0,0 +1,192 @@
+package yum
+
+import (
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kubri/kubri/pkg/version"
+	"github.com/kubri/kubri/source"
+)
+
+var ErrInvalidAdvisory = errors.New("invalid advisory")
+
+// Advisory sets the advisory details for releases matching a version constraint.
+type Advisory struct {
+	Version  string
+	Type     string
+	Severity string
+	CVEs     []string
+}
+
+type UpdateInfo struct {
+	XMLName xml.Name `xml:"updates"`
+	Update  []Update `xml:"update"`
+}
+
+type Update struct {
+	Status      string       `xml:"status,attr"`
+	Type        string       `xml:"type,attr"`
+	Version     string       `xml:"version,attr"`
+	ID          string       `xml:"id"`
+	Title       string       `xml:"title"`
+	Issued      UpdateDate   `xml:"issued"`
+	Severity    string       `xml:"severity,omitempty"`
+	Description string       `xml:"description"`
+	References  []Reference  `xml:"references>reference"`
+	PkgList     []Collection `xml:"pkglist>collection"`
+}
+
+type UpdateDate struct {
+	Date string `xml:"date,attr"`
+}
+
+type Reference struct {
+	HREF  string `xml:"href,attr"`
+	ID    string `xml:"id,attr"`
+	Type  string `xml:"type,attr"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type Collection struct {
+	Short   string          `xml:"short,attr,omitempty"`
+	Name    string          `xml:"name,omitempty"`
+	Package []UpdatePackage `xml:"package"`
+}
+
+type UpdatePackage struct {
+	Name     string   `xml:"name,attr"`
+	Version  string   `xml:"version,attr"`
+	Release  string   `xml:"release,attr"`
+	Epoch    string   `xml:"epoch,attr"`
+	Arch     string   `xml:"arch,attr"`
+	Src      string   `xml:"src,attr,omitempty"`
+	Filename string   `xml:"filename"`
+	Sum      Checksum `xml:"sum"`
+}
+
+// advisoryFrontMatter is the optional YAML block at the start of release notes.
+type advisoryFrontMatter struct {
+	ID       string   `yaml:"id"`
+	Title    string   `yaml:"title"`
+	Type     string   `yaml:"type"`
+	Severity string   `yaml:"severity"`
+	CVEs     []string `yaml:"cves"`
+}
+
+// newUpdate returns an advisory for the packages in the release. Details are
+// taken from the first matching advisory rule and may be overridden by a front
+// matter block in the release notes.
+func newUpdate(r *source.Release, pkgs []*Package, rules []Advisory) (*Update, error) {
+	fm, notes, err := parseFrontMatter(r.Description)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidAdvisory, r.Version, err)
+	}
+
+	for _, rule := range rules {
+		constraint, err := version.NewConstraint(rule.Version)
+		if err != nil {
+			return nil, err
+		}
+		if constraint.Check(r.Version) {
+			fm.Type = cmp.Or(fm.Type, rule.Type)
+			fm.Severity = cmp.Or(fm.Severity, rule.Severity)
+			if fm.CVEs == nil {
+				fm.CVEs = rule.CVEs
+			}
+			break
+		}
+	}
+
+	v := strings.TrimPrefix(r.Version, "v")
+
+	title := fm.Title
+	if title == "" && r.Name != r.Version {
+		title = r.Name
+	}
+
+	u := &Update{
+		Status:      "stable",
+		Type:        cmp.Or(fm.Type, "bugfix"),
+		Version:     "1",
+		ID:          cmp.Or(fm.ID, pkgs[0].Name+"-"+v),
+		Title:       cmp.Or(title, pkgs[0].Name+" "+v),
+		Issued:      UpdateDate{Date: r.Date.UTC().Format("2006-01-02 15:04:05")},
+		Severity:    fm.Severity,
+		Description: notes,
+	}
+
+	if !slices.Contains([]string{"bugfix", "enhancement", "newpackage", "security"}, u.Type) {
+		return nil, fmt.Errorf("%w: %s: unknown type %q", ErrInvalidAdvisory, r.Version, u.Type)
+	}
+	if r.Prerelease {
+		u.Status = "testing"
+	}
+
+	for _, cve := range fm.CVEs {
+		u.References = append(u.References, Reference{
+			HREF:  "https://www.cve.org/CVERecord?id=" + cve,
+			ID:    cve,
+			Type:  "cve",
+			Title: cve,
+		})
+	}
+
+	c := Collection{Short: pkgs[0].Name, Name: pkgs[0].Name}
+	for _, p := range pkgs {
+		c.Package = append(c.Package, UpdatePackage{
+			Name:     p.Name,
+			Version:  p.Version.Ver,
+			Release:  p.Version.Rel,
+			Epoch:    p.Version.Epoch,
+			Arch:     p.Arch,
+			Src:      p.Format.SourceRPM,
+			Filename: p.Location.HREF[strings.LastIndexByte(p.Location.HREF, '/')+1:],
+			Sum:      Checksum{Type: p.Checksum.Type, Value: p.Checksum.Value},
+		})
+	}
+	u.PkgList = []Collection{c}
+
+	return u, nil
+}
+
+// parseFrontMatter splits a YAML front matter block delimited by `---` lines
+// from the release notes.
+func parseFrontMatter(s string) (advisoryFrontMatter, string, error) {
+	var fm advisoryFrontMatter
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(s, "---\n")
+	if !ok {
+		return fm, strings.TrimSpace(s), nil
+	}
+	block, notes, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return fm, strings.TrimSpace(s), nil
+	}
+	if err := yaml.Unmarshal([]byte(block), &fm); err != nil {
+		return fm, "", err
+	}
+
+	return fm, strings.TrimSpace(notes), nil
+}
+
+// AddUpdate adds an advisory to the repository, replacing any existing advisory
+// with the same ID. Advisories are kept in order of issue date.
+func (r *repo) AddUpdate(u *Update) {
+	if r.updateinfo == nil {
+		r.updateinfo = &UpdateInfo{}
+	}
+
+	updates := slices.DeleteFunc(r.updateinfo.Update, func(e Update) bool { return e.ID == u.ID })
+	updates = append(updates, *u)
+	slices.SortStableFunc(updates, func(a, b Update) int {
+		return cmp.Or(strings.Compare(a.Issued.Date, b.Issued.Date), strings.Compare(a.ID, b.ID))
+	})
+	r.updateinfo.Update = updates
+}
@@ -0,0 +1,196 @@
+package yum_test
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/kubri/kubri/integrations/yum"
+	"github.com/kubri/kubri/internal/testsource"
+	"github.com/kubri/kubri/source"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestUpdateInfo(t *testing.T) {
+	ts := time.Date(2023, 11, 19, 23, 37, 12, 0, time.UTC)
+	src := testsource.New([]*source.Release{
+		{
+			Version: "v1.0.0",
+			Date:    ts,
+			Description: `---
+type: security
+severity: Important
+cves: [CVE-2023-0001]
+---
+Fixed a vulnerability.`,
+		},
+		{
+			Version:     "v1.1.0",
+			Date:        ts,
+			Description: "Fixed a bug.",
+		},
+		{
+			Name:        "Kubri Test 2",
+			Version:     "v2.0.0",
+			Date:        ts,
+			Description: "Added a feature.",
+		},
+	})
+	for _, v := range []string{"1.0.0", "1.1.0", "2.0.0"} {
+		for _, arch := range []string{"i386", "x86_64"} {
+			name := "kubri-test-" + v + "-1." + arch + ".rpm"
+			b, _ := os.ReadFile(filepath.Join("../../testdata", "v"+v, name))
+			src.UploadAsset(t.Context(), "v"+v, name, b)
+		}
+	}
+
+	dir := t.TempDir()
+	c := &yum.Config{
+		Source:     src,
+		Version:    "<2",
+		UpdateInfo: true,
+		Advisories: []yum.Advisory{{Version: "2", Type: "enhancement", Severity: "Low"}},
+	}
+	c.Target, _ = target.New(target.Config{Path: dir})
+
+	if err := yum.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build again to ensure existing advisories are merged.
+	c.Version = ""
+	if err := yum.Build(t.Context(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgs := func(v string) []yum.Collection {
+		return []yum.Collection{{
+			Short: "kubri-test",
+			Name:  "kubri-test",
+			Package: []yum.UpdatePackage{
+				{Name: "kubri-test", Version: v, Release: "1", Epoch: "0", Arch: "i386", Src: "kubri-test-" + v + "-1.src.rpm", Filename: "kubri-test-" + v + "-1.i386.rpm"},     //nolint:lll
+				{Name: "kubri-test", Version: v, Release: "1", Epoch: "0", Arch: "x86_64", Src: "kubri-test-" + v + "-1.src.rpm", Filename: "kubri-test-" + v + "-1.x86_64.rpm"}, //nolint:lll
+			},
+		}}
+	}
+	issued := yum.UpdateDate{Date: "2023-11-19 23:37:12"}
+
+	want := &yum.UpdateInfo{
+		XMLName: xml.Name{Local: "updates"},
+		Update: []yum.Update{
+			{
+				Status:      "stable",
+				Type:        "security",
+				Version:     "1",
+				ID:          "kubri-test-1.0.0",
+				Title:       "kubri-test 1.0.0",
+				Issued:      issued,
+				Severity:    "Important",
+				Description: "Fixed a vulnerability.",
+				References: []yum.Reference{{
+					HREF:  "https://www.cve.org/CVERecord?id=CVE-2023-0001",
+					ID:    "CVE-2023-0001",
+					Type:  "cve",
+					Title: "CVE-2023-0001",
+				}},
+				PkgList: pkgs("1.0.0"),
+			},
+			{
+				Status:      "stable",
+				Type:        "bugfix",
+				Version:     "1",
+				ID:          "kubri-test-1.1.0",
+				Title:       "kubri-test 1.1.0",
+				Issued:      issued,
+				Description: "Fixed a bug.",
+				PkgList:     pkgs("1.1.0"),
+			},
+			{
+				Status:      "stable",
+				Type:        "enhancement",
+				Version:     "1",
+				ID:          "kubri-test-2.0.0",
+				Title:       "Kubri Test 2",
+				Issued:      issued,
+				Severity:    "Low",
+				Description: "Added a feature.",
+				PkgList:     pkgs("2.0.0"),
+			},
+		},
+	}
+
+	got := readUpdateInfo(t, dir)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(yum.UpdatePackage{}, "Sum")); diff != "" {
+		t.Error(diff)
+	}
+	for _, u := range got.Update {
+		for _, p := range u.PkgList[0].Package {
+			if p.Sum.Type != "sha256" || len(p.Sum.Value) != 64 {
+				t.Errorf("%s: invalid checksum: %v", p.Filename, p.Sum)
+			}
+		}
+	}
+}
+
+func TestUpdateInfoInvalidType(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0", Description: "---\ntype: nope\n---\n"}})
+	b, _ := os.ReadFile("../../testdata/v1.0.0/kubri-test-1.0.0-1.x86_64.rpm")
+	src.UploadAsset(t.Context(), "v1.0.0", "kubri-test-1.0.0-1.x86_64.rpm", b)
+
+	c := &yum.Config{Source: src, UpdateInfo: true}
+	c.Target, _ = target.New(target.Config{Path: t.TempDir()})
+
+	if err := yum.Build(t.Context(), c); !errors.Is(err, yum.ErrInvalidAdvisory) {
+		t.Fatalf("should return %q got %q", yum.ErrInvalidAdvisory, err)
+	}
+}
+
+func readUpdateInfo(t *testing.T, dir string) *yum.UpdateInfo {
+	t.Helper()
+
+	var res yum.UpdateInfo
+	readRepoData(t, dir, "updateinfo", &res)
+	return &res
+}
+
+// readRepoData decodes the metadata of the given type in the repository.
+func readRepoData(t *testing.T, dir, typ string, v any) {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join(dir, "repodata/repomd.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var md yum.RepoMD
+	if err = xml.Unmarshal(b, &md); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range md.Data {
+		if d.Type != typ {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, d.Location.HREF))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = xml.NewDecoder(r).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	t.Fatalf("missing %s in repomd.xml", typ)
+}
@@ -0,0 +1,141 @@
+// Package assets resolves the release assets referenced by package manager
+// manifests such as Homebrew formulae and Scoop manifests.
+package assets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/kubri/kubri/source"
+	"github.com/kubri/kubri/target"
+)
+
+var (
+	ErrNoAssets           = errors.New("no assets")
+	ErrDuplicateName      = errors.New("duplicate name")
+	ErrNoMatchingReleases = errors.New("no matching releases")
+)
+
+type Config struct {
+	Source         *source.Source
+	Target         target.Target
+	UploadPackages bool
+}
+
+// Asset is a release asset downloaded for a key, e.g. a platform.
+type Asset struct {
+	URL    string
+	SHA256 string
+}
+
+// Validate returns an error if the name was already seen, or if the patterns
+// are empty, malformed or use keys other than the given ones.
+func Validate[K ~string](seen map[string]bool, name string, patterns map[K]string, keys []K, errInvalid error) error {
+	if seen[name] {
+		return fmt.Errorf("%w: %s", ErrDuplicateName, name)
+	}
+	seen[name] = true
+
+	if len(patterns) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoAssets, name)
+	}
+	for k := range patterns {
+		if !slices.Contains(keys, k) {
+			return fmt.Errorf("%w: %s for %s", errInvalid, k, name)
+		}
+		if _, err := path.Match(patterns[k], ""); err != nil {
+			return fmt.Errorf("%w: %q for %s", err, patterns[k], name)
+		}
+	}
+	return nil
+}
+
+// Latest returns the latest release containing assets matching any of the
+// patterns along with the matching assets. If UploadPackages is set, the assets
+// are uploaded to the target and use its URLs.
+func Latest[K ~string](
+	ctx context.Context, c *Config, releases []*source.Release, patterns map[K]string,
+) (*source.Release, map[K]*Asset, error) {
+	keys := slices.Sorted(maps.Keys(patterns))
+
+	for _, r := range releases {
+		matches := map[K]*source.Asset{}
+		for _, k := range keys {
+			a, err := match(r, patterns[k])
+			if err != nil {
+				return nil, nil, err
+			}
+			if a != nil {
+				matches[k] = a
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		assets := map[K]*Asset{}
+		for _, k := range keys {
+			a := matches[k]
+			if a == nil {
+				log.Printf("Skipping %s: no asset matching %q in %s", k, patterns[k], r.Version)
+				continue
+			}
+
+			b, err := c.Source.DownloadAsset(ctx, r.Version, a.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			url := a.URL
+			if c.UploadPackages {
+				if url, err = upload(ctx, c.Target, r.Version+"/"+a.Name, b); err != nil {
+					return nil, nil, err
+				}
+			}
+			sum := sha256.Sum256(b)
+			assets[k] = &Asset{URL: url, SHA256: hex.EncodeToString(sum[:])}
+		}
+
+		return r, assets, nil
+	}
+
+	return nil, nil, ErrNoMatchingReleases
+}
+
+// Write writes b to name on the target.
+func Write(ctx context.Context, t target.Target, name string, b []byte) error {
+	w, err := t.NewWriter(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(b); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func match(r *source.Release, pattern string) (*source.Asset, error) {
+	for _, a := range r.Assets {
+		ok, err := path.Match(pattern, a.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, pattern)
+		}
+		if ok {
+			return a, nil
+		}
+	}
+	return nil, nil //nolint:nilnil
+}
+
+func upload(ctx context.Context, t target.Target, name string, b []byte) (string, error) {
+	if err := Write(ctx, t, name, b); err != nil {
+		return "", err
+	}
+	return t.URL(ctx, name)
+}
@@ -0,0 +1,144 @@
+package assets_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kubri/kubri/internal/assets"
+	"github.com/kubri/kubri/internal/testsource"
+	"github.com/kubri/kubri/source"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestValidate(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	allowed := []string{"a", "b"}
+	seen := map[string]bool{}
+
+	tests := []struct {
+		name     string
+		patterns map[string]string
+		err      error
+	}{
+		{"app", map[string]string{"a": "*"}, nil},
+		{"app", map[string]string{"a": "*"}, assets.ErrDuplicateName},
+		{"empty", nil, assets.ErrNoAssets},
+		{"invalid", map[string]string{"c": "*"}, errInvalid},
+		{"pattern", map[string]string{"a": "["}, path.ErrBadPattern},
+	}
+
+	for _, test := range tests {
+		if err := assets.Validate(seen, test.name, test.patterns, allowed, errInvalid); !errors.Is(err, test.err) {
+			t.Errorf("%s: want %v got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}, {Version: "v1.1.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "app_amd64.zip", []byte("v1.0.0 amd64"))
+	src.UploadAsset(t.Context(), "v1.0.0", "app_arm64.zip", []byte("v1.0.0 arm64"))
+	src.UploadAsset(t.Context(), "v1.1.0", "app_amd64.zip", []byte("v1.1.0 amd64"))
+
+	sum := func(s string) string {
+		b := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(b[:])
+	}
+
+	tests := []struct {
+		name     string
+		patterns map[string]string
+		version  string
+		want     map[string]*assets.Asset
+		err      error
+	}{
+		{
+			name:     "latest",
+			patterns: map[string]string{"x64": "*_amd64.zip", "arm64": "*_arm64.zip"},
+			version:  "v1.1.0",
+			want: map[string]*assets.Asset{
+				"x64": {URL: "https://example.com/v1.1.0/app_amd64.zip", SHA256: sum("v1.1.0 amd64")},
+			},
+		},
+		{
+			name:     "older",
+			patterns: map[string]string{"arm64": "*_arm64.zip"},
+			version:  "v1.0.0",
+			want: map[string]*assets.Asset{
+				"arm64": {URL: "https://example.com/v1.0.0/app_arm64.zip", SHA256: sum("v1.0.0 arm64")},
+			},
+		},
+		{
+			name:     "no match",
+			patterns: map[string]string{"x64": "*.msi"},
+			err:      assets.ErrNoMatchingReleases,
+		},
+		{
+			name:     "bad pattern",
+			patterns: map[string]string{"x64": "["},
+			err:      path.ErrBadPattern,
+		},
+	}
+
+	releases, err := src.ListReleases(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, got, err := assets.Latest(t.Context(), &assets.Config{Source: src}, releases, test.patterns)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("want %v got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if r.Version != test.version {
+				t.Errorf("want version %s got %s", test.version, r.Version)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLatestUploadPackages(t *testing.T) {
+	src := testsource.New([]*source.Release{{Version: "v1.0.0"}})
+	src.UploadAsset(t.Context(), "v1.0.0", "App.dmg", []byte("test"))
+
+	tgt, _ := target.New(target.Config{Path: t.TempDir(), URL: "https://dl.example.com"})
+
+	releases, err := src.ListReleases(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &assets.Config{Source: src, Target: tgt, UploadPackages: true}
+	_, got, err := assets.Latest(t.Context(), c, releases, map[string]string{"macos": "*.dmg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "https://dl.example.com/v1.0.0/App.dmg"; got["macos"].URL != want {
+		t.Errorf("want url %s got %s", want, got["macos"].URL)
+	}
+
+	r, err := tgt.NewReader(t.Context(), "v1.0.0/App.dmg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	r.Close()
+
+	if string(b) != "test" {
+		t.Error("should upload the package")
+	}
+}
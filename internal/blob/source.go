@@ -130,3 +130,7 @@ func (s *blobSource) UploadAsset(ctx context.Context, version, name string, data
 func (s *blobSource) DownloadAsset(ctx context.Context, version, name string) ([]byte, error) {
 	return s.bucket.ReadAll(ctx, path.Join(s.prefix, version, name))
 }
+
+func (s *blobSource) OpenAsset(ctx context.Context, version, name string) (io.ReadCloser, error) {
+	return s.bucket.NewReader(ctx, path.Join(s.prefix, version, name), nil)
+}
@@ -7,7 +7,9 @@ import (
 	"mime"
 	"net/url"
 	"path"
+	"slices"
 	"strings"
+	"time"
 
 	"gocloud.dev/blob"
 	"gocloud.dev/gcerrors"
@@ -47,6 +49,36 @@ func (t *blobTarget) NewReader(ctx context.Context, filename string) (io.ReadClo
 	return f, mapError("read", filename, err)
 }
 
+func (t *blobTarget) ReadDir(ctx context.Context, dir string) ([]fs.DirEntry, error) {
+	prefix := path.Join(t.prefix, dir)
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var entries []fs.DirEntry
+	it := t.bucket.List(&blob.ListOptions{Prefix: prefix, Delimiter: "/"})
+	for {
+		obj, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, mapError("readdir", dir, err)
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(obj.Key, prefix), "/")
+		entries = append(entries, fs.FileInfoToDirEntry(&fileInfo{name, obj.Size, obj.ModTime, obj.IsDir}))
+	}
+
+	// Blob storage has no directories, so an empty listing means it doesn't exist.
+	if len(entries) == 0 {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return entries, nil
+}
+
 func (t *blobTarget) Remove(ctx context.Context, filename string) error {
 	return mapError("remove", filename, t.bucket.Delete(ctx, path.Join(t.prefix, filename)))
 }
@@ -69,6 +101,26 @@ func (t *blobTarget) URL(ctx context.Context, filename string) (string, error) {
 	return url, nil
 }
 
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.dir }
+func (fi *fileInfo) Sys() any           { return nil }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
 func mapError(op, name string, err error) error {
 	switch gcerrors.Code(err) {
 	case gcerrors.OK:
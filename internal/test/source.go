@@ -2,6 +2,7 @@ package test
 
 import (
 	"bytes"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -102,6 +103,28 @@ func Source(t *testing.T, s *source.Source, makeURL func(version, asset string)
 			t.Error("should return error")
 		}
 	})
+
+	t.Run("OpenAsset", func(t *testing.T) {
+		t.Helper()
+
+		r, err := s.OpenAsset(t.Context(), want[0].Version, "test.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, b) {
+			t.Error("should be equal")
+		}
+
+		_, err = s.OpenAsset(t.Context(), want[0].Version, "fail.txt")
+		if err == nil {
+			t.Error("should return error")
+		}
+	})
 }
 
 func SourceWant() []*source.Release {
@@ -126,6 +126,33 @@ func Target(t *testing.T, tgt target.Target, makeURL func(string) string, opt ..
 		}
 	})
 
+	if _, ok := tgt.(target.DirReader); ok {
+		t.Run("ReadDir", func(t *testing.T) {
+			t.Helper()
+
+			entries, err := target.ReadDir(t.Context(), tgt, "path")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != "to" || !entries[0].IsDir() {
+				t.Fatalf("should return directory 'to' - got %v", entries)
+			}
+
+			entries, err = target.ReadDir(t.Context(), tgt, "path/to")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != "file" || entries[0].IsDir() {
+				t.Fatalf("should return file 'file' - got %v", entries)
+			}
+
+			_, err = target.ReadDir(t.Context(), tgt, "does/not/exist")
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("Should return %q - got %q", fs.ErrNotExist, err)
+			}
+		})
+	}
+
 	t.Run("URL", func(t *testing.T) {
 		t.Helper()
 
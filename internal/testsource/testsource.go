@@ -9,12 +9,20 @@ import (
 )
 
 type testSource struct {
-	data   []*source.Release
-	assets map[[2]string][]byte
+	data      []*source.Release
+	assets    map[[2]string][]byte
+	downloads map[string]int
 }
 
 func New(r []*source.Release) *source.Source {
-	return source.New(&testSource{r, map[[2]string][]byte{}})
+	return source.New(&testSource{data: r, assets: map[[2]string][]byte{}})
+}
+
+// NewCounting is like New but also returns the number of downloads of each
+// asset, keyed by version and asset name joined with a slash.
+func NewCounting(r []*source.Release) (*source.Source, map[string]int) {
+	s := &testSource{data: r, assets: map[[2]string][]byte{}, downloads: map[string]int{}}
+	return source.New(s), s.downloads
 }
 
 func (s *testSource) GetRelease(_ context.Context, version string) (*source.Release, error) {
@@ -31,6 +39,10 @@ func (s *testSource) ListReleases(context.Context) ([]*source.Release, error) {
 }
 
 func (s *testSource) DownloadAsset(ctx context.Context, version, name string) ([]byte, error) {
+	if s.downloads != nil {
+		s.downloads[version+"/"+name]++
+	}
+
 	r, err := s.GetRelease(ctx, version)
 	if err != nil {
 		return nil, err
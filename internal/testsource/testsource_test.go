@@ -6,6 +6,7 @@ import (
 
 	"github.com/kubri/kubri/internal/test"
 	"github.com/kubri/kubri/internal/testsource"
+	"github.com/kubri/kubri/source"
 )
 
 func TestFile(t *testing.T) {
@@ -14,3 +15,18 @@ func TestFile(t *testing.T) {
 		return "https://example.com/" + path.Join(version, asset)
 	})
 }
+
+func TestNewCounting(t *testing.T) {
+	s, downloads := testsource.NewCounting([]*source.Release{{Version: "v1.0.0"}})
+	s.UploadAsset(t.Context(), "v1.0.0", "test.txt", []byte("test"))
+
+	for range 2 {
+		if _, err := s.DownloadAsset(t.Context(), "v1.0.0", "test.txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := downloads["v1.0.0/test.txt"]; n != 2 {
+		t.Errorf("want 2 downloads got %d", n)
+	}
+}
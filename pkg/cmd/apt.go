@@ -0,0 +1,15 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func aptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apt",
+		Short: "Manage APT repositories",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(aptRefreshCmd())
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubri/kubri/integrations/apt"
+	"github.com/kubri/kubri/pkg/config"
+)
+
+func aptRefreshCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Re-sign Release files",
+		Long:  "Update the date & validity of APT Release files and sign them again without publishing packages.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			p, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			if p.Apt == nil {
+				return errors.New("apt is not configured")
+			}
+
+			log.Print("Refreshing APT repository...")
+			if err = apt.Refresh(cmd.Context(), p.Apt); err != nil {
+				return fmt.Errorf("failed to refresh APT repository: %w", err)
+			}
+			log.Print("Completed refreshing APT repository.")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "load configuration from a file")
+
+	return cmd
+}
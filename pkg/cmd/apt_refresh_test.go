@@ -0,0 +1,64 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kubri/kubri/internal/test"
+	"github.com/kubri/kubri/pkg/cmd"
+)
+
+func TestAptRefresh(t *testing.T) {
+	src := t.TempDir()
+	tgt := t.TempDir()
+
+	baseConfig := `
+		source:
+			type: file
+			path: ` + src + `
+		target:
+			type: file
+			path: ` + tgt
+
+	tests := []struct {
+		desc   string
+		config string
+		want   string
+		err    bool
+	}{
+		{
+			desc: "not configured",
+			want: "Error: apt is not configured",
+			err:  true,
+		},
+		{
+			desc:   "no repository",
+			config: "apt: {}",
+			want:   "Error: failed to refresh APT repository: no repository found",
+			err:    true,
+		},
+		{
+			desc:   "refresh",
+			config: "apt: {}",
+			want:   "Completed refreshing APT repository.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Chdir(t.TempDir())
+		os.WriteFile("kubri.yml", test.JoinYAML(tc.config, baseConfig), os.ModePerm)
+
+		if !tc.err {
+			os.MkdirAll(tgt+"/apt/dists/stable", 0o750)
+			os.WriteFile(tgt+"/apt/dists/stable/Release", []byte("Suite: stable\n"), 0o600)
+		}
+
+		var out bytes.Buffer
+		err := cmd.Execute("", cmd.WithArgs("apt", "refresh"), cmd.WithStderr(&out), cmd.WithStdout(&out))
+		if tc.err != (err != nil) || !strings.Contains(out.String(), tc.want) {
+			t.Errorf("%s should return %q:\n%s", tc.desc, tc.want, &out)
+		}
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/kubri/kubri/integrations/appinstaller"
 	"github.com/kubri/kubri/integrations/apt"
 	"github.com/kubri/kubri/integrations/arch"
+	"github.com/kubri/kubri/integrations/homebrew"
+	"github.com/kubri/kubri/integrations/scoop"
 	"github.com/kubri/kubri/integrations/sparkle"
 	"github.com/kubri/kubri/integrations/yum"
 	"github.com/kubri/kubri/pkg/config"
@@ -42,6 +44,8 @@ func buildCmd() *cobra.Command {
 				{"Arch", fn(arch.Build, p.Arch)},
 				{"YUM", fn(yum.Build, p.Yum)},
 				{"Sparkle", fn(sparkle.Build, p.Sparkle)},
+				{"Homebrew", fn(homebrew.Build, p.Homebrew)},
+				{"Scoop", fn(scoop.Build, p.Scoop)},
 			}
 
 			var n int
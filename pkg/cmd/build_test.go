@@ -68,6 +68,20 @@ func TestBuild(t *testing.T) {
 			config: "sparkle: {}",
 			want:   "Completed publishing Sparkle packages.",
 		},
+		{
+			desc:   "homebrew",
+			args:   []string{"build"},
+			path:   "kubri.yml",
+			config: "homebrew: {}",
+			want:   "Completed publishing Homebrew packages.",
+		},
+		{
+			desc:   "scoop",
+			args:   []string{"build"},
+			path:   "kubri.yml",
+			config: "scoop: {}",
+			want:   "Completed publishing Scoop packages.",
+		},
 	}
 
 	baseConfig := `
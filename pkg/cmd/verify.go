@@ -0,0 +1,15 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func verifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify published feeds",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(verifySparkleCmd())
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubri/kubri/integrations/sparkle"
+	"github.com/kubri/kubri/pkg/config"
+	"github.com/kubri/kubri/pkg/crypto/dsa"
+	"github.com/kubri/kubri/pkg/crypto/ed25519"
+)
+
+func verifySparkleCmd() *cobra.Command {
+	var configPath, dsaKeyPath, ed25519KeyPath string
+
+	cmd := &cobra.Command{
+		Use:   "sparkle",
+		Short: "Verify Sparkle appcast feeds",
+		Long:  "Download every enclosure in the existing Sparkle appcast feeds and check their length and signatures.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			p, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			if p.Sparkle == nil {
+				return errors.New("sparkle is not configured")
+			}
+			if dsaKeyPath != "" {
+				if p.Sparkle.DSAPublicKey, err = readPublicKey(dsaKeyPath, dsa.UnmarshalPublicKey); err != nil {
+					return err
+				}
+			}
+			if ed25519KeyPath != "" {
+				if p.Sparkle.Ed25519PublicKey, err = readPublicKey(ed25519KeyPath, ed25519.UnmarshalPublicKey); err != nil {
+					return err
+				}
+			}
+
+			log.Print("Verifying Sparkle feeds...")
+			problems, err := sparkle.Verify(cmd.Context(), p.Sparkle)
+			if err != nil {
+				return fmt.Errorf("failed to verify Sparkle feeds: %w", err)
+			}
+			for _, problem := range problems {
+				log.Print(problem)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("found %d broken enclosures", len(problems))
+			}
+			log.Print("Completed verifying Sparkle feeds.")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "load configuration from a file")
+	cmd.Flags().StringVar(&dsaKeyPath, "dsa-key", "", "verify dsaSignature with a public key file")
+	cmd.Flags().StringVar(&ed25519KeyPath, "ed25519-key", "", "verify edSignature with a public key file")
+
+	return cmd
+}
+
+func readPublicKey[T any](path string, unmarshal func([]byte) (T, error)) (T, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return unmarshal(b)
+}
@@ -0,0 +1,128 @@
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubri/kubri/internal/test"
+	"github.com/kubri/kubri/pkg/cmd"
+	"github.com/kubri/kubri/pkg/crypto/ed25519"
+)
+
+func TestVerifySparkle(t *testing.T) {
+	src := t.TempDir()
+	tgt := t.TempDir()
+
+	t.Setenv("KUBRI_PATH", t.TempDir())
+
+	baseConfig := `
+		source:
+			type: file
+			path: ` + src + `
+		target:
+			type: file
+			path: ` + tgt
+
+	key, _ := ed25519.NewPrivateKey()
+	pub, _ := ed25519.MarshalPublicKey(ed25519.Public(key))
+	keyPath := filepath.Join(t.TempDir(), "ed25519.pub")
+	os.WriteFile(keyPath, pub, 0o600)
+
+	sig, _ := ed25519.Sign(key, []byte("test"))
+
+	feed := func(length, sig string) []byte {
+		return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:sparkle="http://www.andymatuschak.org/xml-namespaces/sparkle">
+	<channel>
+		<item>
+			<title>v1.0.0</title>
+			<sparkle:version>1.0.0</sparkle:version>
+			<enclosure url="file://` + tgt + `/sparkle/test.dmg" sparkle:os="macos" sparkle:version="1.0.0" length="` + length + `" sparkle:edSignature="` + sig + `" type="application/x-apple-diskimage" />
+		</item>
+	</channel>
+</rss>`)
+	}
+	edSig := base64.StdEncoding.EncodeToString(sig)
+
+	tests := []struct {
+		desc   string
+		config string
+		feed   []byte
+		args   []string
+		want   string
+		err    bool
+	}{
+		{
+			desc: "not configured",
+			want: "Error: sparkle is not configured",
+			err:  true,
+		},
+		{
+			desc:   "no key",
+			config: "sparkle: {}",
+			feed:   feed("4", edSig),
+			want:   "Error: failed to verify Sparkle feeds: no public key",
+			err:    true,
+		},
+		{
+			desc:   "no feed",
+			config: "sparkle: {}",
+			args:   []string{"--ed25519-key", keyPath},
+			want:   "Skipping appcast.xml: feed does not exist",
+		},
+		{
+			desc:   "broken",
+			config: "sparkle: {}",
+			feed:   feed("10", edSig),
+			args:   []string{"--ed25519-key", keyPath},
+			want:   "Error: found 1 broken enclosures",
+			err:    true,
+		},
+		{
+			desc:   "valid",
+			config: "sparkle: {}",
+			feed:   feed("4", edSig),
+			args:   []string{"--ed25519-key", keyPath},
+			want:   "Completed verifying Sparkle feeds.",
+		},
+		{
+			desc:   "unsigned",
+			config: "sparkle: {}",
+			feed:   feed("4", ""),
+			args:   []string{"--ed25519-key", keyPath},
+			want:   "Error: found 1 broken enclosures",
+			err:    true,
+		},
+		{
+			desc:   "invalid key",
+			config: "sparkle: {}",
+			feed:   feed("4", edSig),
+			args:   []string{"--dsa-key", keyPath},
+			want:   "Error: invalid key",
+			err:    true,
+		},
+	}
+
+	os.MkdirAll(tgt+"/sparkle", 0o750)
+	os.WriteFile(tgt+"/sparkle/test.dmg", []byte("test"), 0o600)
+
+	for _, tc := range tests {
+		t.Chdir(t.TempDir())
+		os.WriteFile("kubri.yml", test.JoinYAML(tc.config, baseConfig), os.ModePerm)
+
+		os.Remove(tgt + "/sparkle/appcast.xml")
+		if tc.feed != nil {
+			os.WriteFile(tgt+"/sparkle/appcast.xml", tc.feed, 0o600)
+		}
+
+		var out bytes.Buffer
+		err := cmd.Execute("", cmd.WithArgs(append([]string{"verify", "sparkle"}, tc.args...)...), cmd.WithStderr(&out), cmd.WithStdout(&out))
+		if tc.err != (err != nil) || !strings.Contains(out.String(), tc.want) {
+			t.Errorf("%s should return %q:\n%s", tc.desc, tc.want, &out)
+		}
+	}
+}
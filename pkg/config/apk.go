@@ -9,29 +9,50 @@ import (
 )
 
 type apkConfig struct {
-	Disabled bool   `yaml:"disabled,omitempty"`
-	Folder   string `yaml:"folder,omitempty"   validate:"omitempty,dirname"`
-	KeyName  string `yaml:"key-name,omitempty"`
+	Disabled     bool   `yaml:"disabled,omitempty"`
+	Folder       string `yaml:"folder,omitempty"        validate:"omitempty,dirname"`
+	KeyName      string `yaml:"key-name,omitempty"`
+	NextKeyName  string `yaml:"next-key-name,omitempty"`
+	SignPackages bool   `yaml:"sign-packages,omitempty"`
+	Branches     []struct {
+		Name       string `yaml:"name"                 validate:"required,dirname"`
+		Repository string `yaml:"repository,omitempty" validate:"omitempty,slug"`
+		Version    string `yaml:"version,omitempty"    validate:"omitempty,version_constraint"`
+	} `yaml:"branches,omitempty" validate:"dive"`
 }
 
 func getApk(c *config) (*apk.Config, error) {
-	var rsaKey *rsa.PrivateKey
-	if b, err := secret.Get("rsa_key"); err == nil {
-		rsaKey, err = rsa.UnmarshalPrivateKey(b)
+	var keys []apk.Key
+	for _, k := range []struct{ secret, field, name string }{
+		{"rsa_key", "key-name", c.Apk.KeyName},
+		{"rsa_key_next", "next-key-name", c.Apk.NextKeyName},
+	} {
+		b, err := secret.Get(k.secret)
+		if err != nil {
+			continue
+		}
+		key, err := rsa.UnmarshalPrivateKey(b)
 		if err != nil {
 			return nil, err
 		}
-		if c.Apk.KeyName == "" {
-			return nil, &Error{Errors: []string{"apk.key-name is required when rsa_key is set"}}
+		if k.name == "" {
+			return nil, &Error{Errors: []string{"apk." + k.field + " is required when " + k.secret + " is set"}}
 		}
+		keys = append(keys, apk.Key{Name: k.name, Key: key})
+	}
+
+	var branches []apk.Branch
+	for _, b := range c.Apk.Branches {
+		branches = append(branches, apk.Branch(b))
 	}
 
 	return &apk.Config{
-		Source:     c.source,
-		Target:     c.target.Sub(cmp.Or(c.Apk.Folder, "apk")),
-		Version:    c.Version,
-		Prerelease: c.Prerelease,
-		RSAKey:     rsaKey,
-		KeyName:    c.Apk.KeyName,
+		Source:       c.source,
+		Target:       c.target.Sub(cmp.Or(c.Apk.Folder, "apk")),
+		Version:      c.Version,
+		Prerelease:   c.Prerelease,
+		Keys:         keys,
+		SignPackages: c.Apk.SignPackages,
+		Branches:     branches,
 	}, nil
 }
@@ -18,6 +18,8 @@ func TestApk(t *testing.T) {
 	tgt, _ := target.New(target.Config{Path: dir})
 	key, _ := rsa.NewPrivateKey()
 	keyBytes, _ := rsa.MarshalPrivateKey(key)
+	next, _ := rsa.NewPrivateKey()
+	nextBytes, _ := rsa.MarshalPrivateKey(next)
 
 	runTest(t, []testCase{
 		{
@@ -65,17 +67,27 @@ func TestApk(t *testing.T) {
 					path: ` + dir + `
 				apk:
 					folder: test
-					key-name: test@example.com.rsa.pub
+					key-name: test@example.com
+					sign-packages: true
+					branches:
+						- name: v3.20
+							version: <2
+						- name: edge
+							repository: community
 			`,
 			hook: func() { secret.Put("rsa_key", keyBytes) },
 			want: &config.Config{
 				Apk: &apk.Config{
-					Source:     src,
-					Target:     tgt.Sub("test"),
-					Version:    "latest",
-					Prerelease: true,
-					RSAKey:     key,
-					KeyName:    "test@example.com.rsa.pub",
+					Source:       src,
+					Target:       tgt.Sub("test"),
+					Version:      "latest",
+					Prerelease:   true,
+					Keys:         []apk.Key{{Name: "test@example.com", Key: key}},
+					SignPackages: true,
+					Branches: []apk.Branch{
+						{Name: "v3.20", Version: "<2"},
+						{Name: "edge", Repository: "community"},
+					},
 				},
 			},
 		},
@@ -93,6 +105,52 @@ func TestApk(t *testing.T) {
 			hook: func() { secret.Put("rsa_key", keyBytes) },
 			err:  &config.Error{Errors: []string{"apk.key-name is required when rsa_key is set"}},
 		},
+		{
+			desc: "next rsa key",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				apk:
+					key-name: current@example.com
+					next-key-name: next@example.com
+			`,
+			hook: func() {
+				secret.Put("rsa_key", keyBytes)
+				secret.Put("rsa_key_next", nextBytes)
+			},
+			want: &config.Config{
+				Apk: &apk.Config{
+					Source: src,
+					Target: tgt.Sub("apk"),
+					Keys: []apk.Key{
+						{Name: "current@example.com", Key: key},
+						{Name: "next@example.com", Key: next},
+					},
+				},
+			},
+		},
+		{
+			desc: "missing next key name",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				apk:
+					key-name: current@example.com
+			`,
+			hook: func() {
+				secret.Put("rsa_key", keyBytes)
+				secret.Put("rsa_key_next", nextBytes)
+			},
+			err: &config.Error{Errors: []string{"apk.next-key-name is required when rsa_key_next is set"}},
+		},
 		{
 			desc: "invalid rsa key",
 			in: `
@@ -139,5 +197,25 @@ func TestApk(t *testing.T) {
 			`,
 			err: &config.Error{Errors: []string{"apk.folder must be a valid folder name"}},
 		},
+		{
+			desc: "invalid branches",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				apk:
+					branches:
+						- repository: 'main repo'
+							version: 'foo'
+			`,
+			err: &config.Error{Errors: []string{
+				"apk.branches[0].name is a required field",
+				"apk.branches[0].repository must only contain letters, numbers, dashes and underscores",
+				"apk.branches[0].version must be a valid version constraint",
+			}},
+		},
 	})
 }
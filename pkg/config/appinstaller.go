@@ -14,15 +14,44 @@ type appinstallerConfig struct {
 		ShowPrompt               bool `yaml:"show-prompt,omitempty"`
 		UpdateBlocksActivation   bool `yaml:"update-blocks-activation,omitempty"`
 	} `yaml:"on-launch,omitempty"`
-	AutomaticBackgroundTask   bool `yaml:"automatic-background-task,omitempty"`
-	ForceUpdateFromAnyVersion bool `yaml:"force-update-from-any-version,omitempty"`
+	AutomaticBackgroundTask   bool     `yaml:"automatic-background-task,omitempty"`
+	BackgroundTaskShowPrompt  bool     `yaml:"background-task-show-prompt,omitempty"`
+	ForceUpdateFromAnyVersion bool     `yaml:"force-update-from-any-version,omitempty"`
+	UpdateURIs                []string `yaml:"update-uris,omitempty"                   validate:"dive,http_url"`
+	RepairURIs                []string `yaml:"repair-uris,omitempty"                   validate:"dive,http_url"`
+	Dependencies              []struct {
+		Name                  string `yaml:"name"                             validate:"required"`
+		Publisher             string `yaml:"publisher,omitempty"`
+		Version               string `yaml:"version,omitempty"`
+		ProcessorArchitecture string `yaml:"processor-architecture,omitempty" validate:"omitempty,oneof=x86 x64 arm arm64 neutral" jsonschema:"enum=x86,enum=x64,enum=arm,enum=arm64,enum=neutral"` //nolint:lll
+		URI                   string `yaml:"uri"                              validate:"required,http_url"`
+	} `yaml:"dependencies,omitempty" validate:"dive"`
+	OptionalPackages []string `yaml:"optional-packages,omitempty"`
+	RelatedPackages  []string `yaml:"related-packages,omitempty"`
 }
 
 func getAppinstaller(c *config) *appinstaller.Config {
+	deps := make([]*appinstaller.Package, len(c.Appinstaller.Dependencies))
+	for i, d := range c.Appinstaller.Dependencies {
+		deps[i] = &appinstaller.Package{
+			Name:                  d.Name,
+			Publisher:             d.Publisher,
+			Version:               d.Version,
+			ProcessorArchitecture: d.ProcessorArchitecture,
+			URI:                   d.URI,
+		}
+	}
+
 	return &appinstaller.Config{
 		OnLaunch:                  (*appinstaller.OnLaunchConfig)(c.Appinstaller.OnLaunch),
 		AutomaticBackgroundTask:   c.Appinstaller.AutomaticBackgroundTask,
+		BackgroundTaskShowPrompt:  c.Appinstaller.BackgroundTaskShowPrompt,
 		ForceUpdateFromAnyVersion: c.Appinstaller.ForceUpdateFromAnyVersion,
+		UpdateURIs:                c.Appinstaller.UpdateURIs,
+		RepairURIs:                c.Appinstaller.RepairURIs,
+		Dependencies:              deps,
+		OptionalPackages:          c.Appinstaller.OptionalPackages,
+		RelatedPackages:           c.Appinstaller.RelatedPackages,
 
 		Source:         c.source,
 		Target:         c.target.Sub(cmp.Or(c.Appinstaller.Folder, "appinstaller")),
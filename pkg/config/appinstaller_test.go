@@ -42,8 +42,9 @@ func TestAppInstaller(t *testing.T) {
 			`,
 			want: &config.Config{
 				Appinstaller: &appinstaller.Config{
-					Source: src,
-					Target: tgt.Sub("appinstaller"),
+					Dependencies: []*appinstaller.Package{},
+					Source:       src,
+					Target:       tgt.Sub("appinstaller"),
 				},
 			},
 		},
@@ -66,7 +67,20 @@ func TestAppInstaller(t *testing.T) {
 						show-prompt: true
 						update-blocks-activation: true
 					automatic-background-task: true
+					background-task-show-prompt: true
 					force-update-from-any-version: true
+					update-uris: [https://mirror.example.com/appinstaller]
+					repair-uris: [https://example.com/appinstaller]
+					dependencies:
+						- name: Microsoft.VCLibs.140.00.UWPDesktop
+							processor-architecture: x64
+							uri: https://example.com/VCLibs_x64.appx
+						- name: Microsoft.WindowsAppRuntime.1.5
+							publisher: CN=Microsoft Corporation
+							version: 5001.178.1908.0
+							uri: https://example.com/WindowsAppRuntime.msix
+					optional-packages: ['*.Optional_*.msix']
+					related-packages: ['*.Related.msix']
 			`,
 			want: &config.Config{
 				Appinstaller: &appinstaller.Config{
@@ -76,7 +90,25 @@ func TestAppInstaller(t *testing.T) {
 						UpdateBlocksActivation:   true,
 					},
 					AutomaticBackgroundTask:   true,
+					BackgroundTaskShowPrompt:  true,
 					ForceUpdateFromAnyVersion: true,
+					UpdateURIs:                []string{"https://mirror.example.com/appinstaller"},
+					RepairURIs:                []string{"https://example.com/appinstaller"},
+					Dependencies: []*appinstaller.Package{
+						{
+							Name:                  "Microsoft.VCLibs.140.00.UWPDesktop",
+							ProcessorArchitecture: "x64",
+							URI:                   "https://example.com/VCLibs_x64.appx",
+						},
+						{
+							Name:      "Microsoft.WindowsAppRuntime.1.5",
+							Publisher: "CN=Microsoft Corporation",
+							Version:   "5001.178.1908.0",
+							URI:       "https://example.com/WindowsAppRuntime.msix",
+						},
+					},
+					OptionalPackages: []string{"*.Optional_*.msix"},
+					RelatedPackages:  []string{"*.Related.msix"},
 
 					Source:         src,
 					Target:         tgt.Sub("test"),
@@ -132,5 +164,29 @@ func TestAppInstaller(t *testing.T) {
 			`,
 			err: &config.Error{Errors: []string{"appinstaller.on-launch.hours-between-update-checks must be 255 or less"}},
 		},
+		{
+			desc: "invalid dependencies",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				appinstaller:
+					update-uris: [nope]
+					repair-uris: [nope]
+					dependencies:
+						- processor-architecture: x65
+							uri: nope
+			`,
+			err: &config.Error{Errors: []string{
+				"appinstaller.update-uris[0] must be a valid URL",
+				"appinstaller.repair-uris[0] must be a valid URL",
+				"appinstaller.dependencies[0].name is a required field",
+				"appinstaller.dependencies[0].processor-architecture must be one of [x86 x64 arm arm64 neutral]",
+				"appinstaller.dependencies[0].uri must be a valid URL",
+			}},
+		},
 	})
 }
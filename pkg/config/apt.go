@@ -3,25 +3,29 @@ package config
 import (
 	"cmp"
 	"fmt"
+	"time"
 
 	"github.com/kubri/kubri/integrations/apt"
-	"github.com/kubri/kubri/pkg/crypto/pgp"
-	"github.com/kubri/kubri/pkg/secret"
 )
 
 type aptConfig struct {
-	Disabled bool     `yaml:"disabled,omitempty"`
-	Folder   string   `yaml:"folder,omitempty"   validate:"omitempty,dirname"`
-	Compress []string `yaml:"compress,omitempty" validate:"dive,oneof=none gzip bzip2 xz lzma lz4 zstd" jsonschema:"enum=none,enum=gzip,enum=bzip2,enum=xz,enum=lzma,enum=lz4,enum=zstd"` //nolint:lll
+	Disabled             bool          `yaml:"disabled,omitempty"`
+	Folder               string        `yaml:"folder,omitempty"                 validate:"omitempty,dirname"`
+	Compress             []string      `yaml:"compress,omitempty"               validate:"dive,oneof=none gzip bzip2 xz lzma lz4 zstd" jsonschema:"enum=none,enum=gzip,enum=bzip2,enum=xz,enum=lzma,enum=lz4,enum=zstd"` //nolint:lll
+	Name                 string        `yaml:"name,omitempty"                   validate:"required_with=InstallScript,omitempty,slug"`
+	InstallScript        bool          `yaml:"install-script,omitempty"`
+	Origin               string        `yaml:"origin,omitempty"`
+	Label                string        `yaml:"label,omitempty"`
+	Description          string        `yaml:"description,omitempty"`
+	ValidFor             time.Duration `yaml:"valid-for,omitempty"              validate:"gte=0"                                       jsonschema:"type=string"` //nolint:lll
+	NotAutomatic         bool          `yaml:"not-automatic,omitempty"`
+	ButAutomaticUpgrades bool          `yaml:"but-automatic-upgrades,omitempty"`
 }
 
 func getApt(c *config) (*apt.Config, error) {
-	var pgpKey *pgp.PrivateKey
-	if b, err := secret.Get("pgp_key"); err == nil {
-		pgpKey, err = pgp.UnmarshalPrivateKey(b)
-		if err != nil {
-			return nil, err
-		}
+	pgpKeys, err := getPGPKeys()
+	if err != nil {
+		return nil, err
 	}
 
 	var algos apt.CompressionAlgo
@@ -47,11 +51,19 @@ func getApt(c *config) (*apt.Config, error) {
 	}
 
 	return &apt.Config{
-		Source:     c.source,
-		Target:     c.target.Sub(cmp.Or(c.Apt.Folder, "apt")),
-		Version:    c.Version,
-		Prerelease: c.Prerelease,
-		PGPKey:     pgpKey,
-		Compress:   algos,
+		Source:               c.source,
+		Target:               c.target.Sub(cmp.Or(c.Apt.Folder, "apt")),
+		Version:              c.Version,
+		Prerelease:           c.Prerelease,
+		PGPKeys:              pgpKeys,
+		Compress:             algos,
+		Name:                 c.Apt.Name,
+		InstallScript:        c.Apt.InstallScript,
+		Origin:               c.Apt.Origin,
+		Label:                c.Apt.Label,
+		Description:          c.Apt.Description,
+		ValidFor:             c.Apt.ValidFor,
+		NotAutomatic:         c.Apt.NotAutomatic,
+		ButAutomaticUpgrades: c.Apt.ButAutomaticUpgrades,
 	}, nil
 }
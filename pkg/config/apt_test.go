@@ -3,6 +3,7 @@ package config_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/kubri/kubri/integrations/apt"
 	"github.com/kubri/kubri/pkg/config"
@@ -19,6 +20,10 @@ func TestApt(t *testing.T) {
 	tgt, _ := target.New(target.Config{Path: dir})
 	key, _ := pgp.NewPrivateKey("test", "test@example.com")
 	keyBytes, _ := pgp.MarshalPrivateKey(key)
+	next, _ := pgp.NewPrivateKey("next", "next@example.com")
+	nextBytes, _ := pgp.MarshalPrivateKey(next)
+	third, _ := pgp.NewPrivateKey("third", "third@example.com")
+	thirdBytes, _ := pgp.MarshalPrivateKey(third)
 
 	runTest(t, []testCase{
 		{
@@ -93,16 +98,56 @@ func TestApt(t *testing.T) {
 						- lzma
 						- lz4
 						- zstd
+					name: test
+					install-script: true
+					origin: Test
+					label: Test Label
+					description: Test description
+					valid-for: 168h
+					not-automatic: true
+					but-automatic-upgrades: true
 			`,
 			hook: func() { secret.Put("pgp_key", keyBytes) },
 			want: &config.Config{
 				Apt: &apt.Config{
-					Source:     src,
-					Target:     tgt.Sub("test"),
-					Version:    "latest",
-					Prerelease: true,
-					PGPKey:     key,
-					Compress:   apt.GZIP | apt.BZIP2 | apt.XZ | apt.LZMA | apt.LZ4 | apt.ZSTD,
+					Source:               src,
+					Target:               tgt.Sub("test"),
+					Version:              "latest",
+					Prerelease:           true,
+					PGPKeys:              []*pgp.PrivateKey{key},
+					Compress:             apt.GZIP | apt.BZIP2 | apt.XZ | apt.LZMA | apt.LZ4 | apt.ZSTD,
+					Name:                 "test",
+					InstallScript:        true,
+					Origin:               "Test",
+					Label:                "Test Label",
+					Description:          "Test description",
+					ValidFor:             168 * time.Hour,
+					NotAutomatic:         true,
+					ButAutomaticUpgrades: true,
+				},
+			},
+		},
+		{
+			desc: "additional pgp keys",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				apt: {}
+			`,
+			hook: func() {
+				secret.Put("pgp_key", keyBytes)
+				secret.Put("pgp_key_next", nextBytes)
+				secret.Put("pgp_key_2", thirdBytes)
+			},
+			want: &config.Config{
+				Apt: &apt.Config{
+					Source:  src,
+					Target:  tgt.Sub("apt"),
+					PGPKeys: []*pgp.PrivateKey{key, next, third},
 				},
 			},
 		},
@@ -118,11 +163,31 @@ func TestApt(t *testing.T) {
 				apt:
 					folder: '*'
 					compress: [invalid]
+					name: '*'
 			`,
 			err: &config.Error{
 				Errors: []string{
 					"apt.folder must be a valid folder name",
 					"apt.compress[0] must be one of [none gzip bzip2 xz lzma lz4 zstd]",
+					"apt.name must only contain letters, numbers, dashes and underscores",
+				},
+			},
+		},
+		{
+			desc: "install script without name",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				apt:
+					install-script: true
+			`,
+			err: &config.Error{
+				Errors: []string{
+					"apt.name is a required field",
 				},
 			},
 		},
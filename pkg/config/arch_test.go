@@ -70,16 +70,20 @@ func TestArch(t *testing.T) {
 				arch:
 					folder: test
 					repo-name: kubri-test
+					keep-versions: 3
+					debug-repo: true
 			`,
 			hook: func() { secret.Put("pgp_key", keyBytes) },
 			want: &config.Config{
 				Arch: &arch.Config{
-					RepoName:   "kubri-test",
-					Source:     src,
-					Target:     tgt.Sub("test"),
-					Version:    "latest",
-					Prerelease: true,
-					PGPKey:     key,
+					RepoName:     "kubri-test",
+					Source:       src,
+					Target:       tgt.Sub("test"),
+					Version:      "latest",
+					Prerelease:   true,
+					PGPKeys:      []*pgp.PrivateKey{key},
+					KeepVersions: 3,
+					DebugRepo:    true,
 				},
 			},
 		},
@@ -110,6 +114,21 @@ func TestArch(t *testing.T) {
 			`,
 			err: &config.Error{Errors: []string{"arch.repo-name must only contain letters, numbers, dashes and underscores"}},
 		},
+		{
+			desc: "invalid keep versions",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				arch:
+					repo-name: kubri-test
+					keep-versions: -1
+			`,
+			err: &config.Error{Errors: []string{"arch.keep-versions must be 0 or greater"}},
+		},
 		{
 			desc: "invalid pgp key",
 			in: `
@@ -12,6 +12,8 @@ import (
 	"github.com/kubri/kubri/integrations/appinstaller"
 	"github.com/kubri/kubri/integrations/apt"
 	"github.com/kubri/kubri/integrations/arch"
+	"github.com/kubri/kubri/integrations/homebrew"
+	"github.com/kubri/kubri/integrations/scoop"
 	"github.com/kubri/kubri/integrations/sparkle"
 	"github.com/kubri/kubri/integrations/yum"
 	"github.com/kubri/kubri/source"
@@ -25,6 +27,8 @@ type Config struct {
 	Arch         *arch.Config
 	Yum          *yum.Config
 	Sparkle      *sparkle.Config
+	Homebrew     *homebrew.Config
+	Scoop        *scoop.Config
 }
 
 func Load(path string) (*Config, error) {
@@ -81,6 +85,12 @@ func Load(path string) (*Config, error) {
 			return nil, err
 		}
 	}
+	if c.Homebrew != nil && !c.Homebrew.Disabled {
+		p.Homebrew = getHomebrew(c)
+	}
+	if c.Scoop != nil && !c.Scoop.Disabled {
+		p.Scoop = getScoop(c)
+	}
 
 	return &p, nil
 }
@@ -127,6 +137,8 @@ type config struct {
 	Yum            *yumConfig          `yaml:"yum,omitempty"`
 	Sparkle        *sparkleConfig      `yaml:"sparkle,omitempty"`
 	Appinstaller   *appinstallerConfig `yaml:"appinstaller,omitempty"`
+	Homebrew       *homebrewConfig     `yaml:"homebrew,omitempty"`
+	Scoop          *scoopConfig        `yaml:"scoop,omitempty"`
 
 	source *source.Source
 	target target.Target
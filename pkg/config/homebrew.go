@@ -0,0 +1,102 @@
+package config
+
+import (
+	"cmp"
+
+	"github.com/kubri/kubri/integrations/homebrew"
+)
+
+type homebrewConfig struct {
+	Disabled bool   `yaml:"disabled,omitempty"`
+	Folder   string `yaml:"folder,omitempty"   validate:"omitempty,dirname"`
+	Formulae []struct {
+		Name        string `yaml:"name"                   validate:"required,excludesall=/"`
+		Description string `yaml:"description,omitempty"`
+		Homepage    string `yaml:"homepage,omitempty"     validate:"omitempty,http_url"`
+		License     string `yaml:"license,omitempty"`
+		Assets      struct {
+			MacOS      string `yaml:"macos,omitempty"       validate:"excluded_with=MacOSARM64 MacOSX64"`
+			MacOSARM64 string `yaml:"macos-arm64,omitempty"`
+			MacOSX64   string `yaml:"macos-x64,omitempty"`
+			LinuxARM64 string `yaml:"linux-arm64,omitempty"`
+			LinuxX64   string `yaml:"linux-x64,omitempty"`
+		} `yaml:"assets" validate:"required"`
+		Dependencies []string `yaml:"dependencies,omitempty"`
+		Binaries     []string `yaml:"binaries,omitempty"`
+		Install      string   `yaml:"install,omitempty"`
+		Test         string   `yaml:"test,omitempty"`
+	} `yaml:"formulae,omitempty" validate:"unique=Name,dive"`
+	Casks []struct {
+		Name        string `yaml:"name"                   validate:"required,excludesall=/"`
+		DisplayName string `yaml:"display-name,omitempty"`
+		Description string `yaml:"description,omitempty"`
+		Homepage    string `yaml:"homepage,omitempty"     validate:"omitempty,http_url"`
+		Assets      struct {
+			MacOS      string `yaml:"macos,omitempty"       validate:"excluded_with=MacOSARM64 MacOSX64"`
+			MacOSARM64 string `yaml:"macos-arm64,omitempty"`
+			MacOSX64   string `yaml:"macos-x64,omitempty"`
+		} `yaml:"assets" validate:"required"`
+		App      string   `yaml:"app,omitempty"`
+		Binaries []string `yaml:"binaries,omitempty"`
+	} `yaml:"casks,omitempty" validate:"unique=Name,dive"`
+}
+
+func getHomebrew(c *config) *homebrew.Config {
+	formulae := make([]*homebrew.Formula, len(c.Homebrew.Formulae))
+	for i, f := range c.Homebrew.Formulae {
+		formulae[i] = &homebrew.Formula{
+			Name:        f.Name,
+			Description: f.Description,
+			Homepage:    f.Homepage,
+			License:     f.License,
+			Assets: getHomebrewAssets(map[homebrew.Platform]string{
+				homebrew.MacOS:      f.Assets.MacOS,
+				homebrew.MacOSARM64: f.Assets.MacOSARM64,
+				homebrew.MacOSX64:   f.Assets.MacOSX64,
+				homebrew.LinuxARM64: f.Assets.LinuxARM64,
+				homebrew.LinuxX64:   f.Assets.LinuxX64,
+			}),
+			Dependencies: f.Dependencies,
+			Binaries:     f.Binaries,
+			Install:      f.Install,
+			Test:         f.Test,
+		}
+	}
+
+	casks := make([]*homebrew.Cask, len(c.Homebrew.Casks))
+	for i, cask := range c.Homebrew.Casks {
+		casks[i] = &homebrew.Cask{
+			Name:        cask.Name,
+			DisplayName: cask.DisplayName,
+			Description: cask.Description,
+			Homepage:    cask.Homepage,
+			Assets: getHomebrewAssets(map[homebrew.Platform]string{
+				homebrew.MacOS:      cask.Assets.MacOS,
+				homebrew.MacOSARM64: cask.Assets.MacOSARM64,
+				homebrew.MacOSX64:   cask.Assets.MacOSX64,
+			}),
+			App:      cask.App,
+			Binaries: cask.Binaries,
+		}
+	}
+
+	return &homebrew.Config{
+		Formulae:       formulae,
+		Casks:          casks,
+		Source:         c.source,
+		Target:         c.target.Sub(cmp.Or(c.Homebrew.Folder, "homebrew")),
+		Version:        c.Version,
+		Prerelease:     c.Prerelease,
+		UploadPackages: c.UploadPackages,
+	}
+}
+
+// getHomebrewAssets removes platforms without a glob.
+func getHomebrewAssets(assets map[homebrew.Platform]string) map[homebrew.Platform]string {
+	for p, glob := range assets {
+		if glob == "" {
+			delete(assets, p)
+		}
+	}
+	return assets
+}
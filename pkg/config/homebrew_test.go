@@ -0,0 +1,189 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/kubri/kubri/integrations/homebrew"
+	"github.com/kubri/kubri/pkg/config"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestHomebrew(t *testing.T) {
+	dir := t.TempDir()
+	src, _ := source.New(source.Config{Path: dir})
+	tgt, _ := target.New(target.Config{Path: dir})
+
+	runTest(t, []testCase{
+		{
+			desc: "disabled",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				homebrew:
+					disabled: true
+			`,
+			want: &config.Config{},
+		},
+		{
+			desc: "defaults",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				homebrew: {}
+			`,
+			want: &config.Config{
+				Homebrew: &homebrew.Config{
+					Formulae: []*homebrew.Formula{},
+					Casks:    []*homebrew.Cask{},
+					Source:   src,
+					Target:   tgt.Sub("homebrew"),
+				},
+			},
+		},
+		{
+			desc: "full",
+			in: `
+				version: latest
+				prerelease: true
+				upload-packages: true
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				homebrew:
+					folder: .
+					formulae:
+						- name: app
+							description: My app
+							homepage: https://example.com
+							license: MIT
+							assets:
+								macos-arm64: '*_darwin_arm64.tar.gz'
+								macos-x64: '*_darwin_amd64.tar.gz'
+								linux-arm64: '*_linux_arm64.tar.gz'
+								linux-x64: '*_linux_amd64.tar.gz'
+							dependencies: [git]
+							binaries: [app]
+							install: bin.install "app"
+							test: system "#{bin}/app", "--version"
+					casks:
+						- name: app
+							display-name: My App
+							description: My app
+							homepage: https://example.com
+							assets:
+								macos: '*.dmg'
+							app: My App.app
+							binaries: [My App.app/Contents/MacOS/app]
+			`,
+			want: &config.Config{
+				Homebrew: &homebrew.Config{
+					Formulae: []*homebrew.Formula{
+						{
+							Name:        "app",
+							Description: "My app",
+							Homepage:    "https://example.com",
+							License:     "MIT",
+							Assets: map[homebrew.Platform]string{
+								homebrew.MacOSARM64: "*_darwin_arm64.tar.gz",
+								homebrew.MacOSX64:   "*_darwin_amd64.tar.gz",
+								homebrew.LinuxARM64: "*_linux_arm64.tar.gz",
+								homebrew.LinuxX64:   "*_linux_amd64.tar.gz",
+							},
+							Dependencies: []string{"git"},
+							Binaries:     []string{"app"},
+							Install:      `bin.install "app"`,
+							Test:         `system "#{bin}/app", "--version"`,
+						},
+					},
+					Casks: []*homebrew.Cask{
+						{
+							Name:        "app",
+							DisplayName: "My App",
+							Description: "My app",
+							Homepage:    "https://example.com",
+							Assets:      map[homebrew.Platform]string{homebrew.MacOS: "*.dmg"},
+							App:         "My App.app",
+							Binaries:    []string{"My App.app/Contents/MacOS/app"},
+						},
+					},
+					Source:         src,
+					Target:         tgt.Sub("."),
+					Version:        "latest",
+					Prerelease:     true,
+					UploadPackages: true,
+				},
+			},
+		},
+		{
+			desc: "invalid folder",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				homebrew:
+					folder: '*'
+			`,
+			err: &config.Error{Errors: []string{"homebrew.folder must be a valid folder name"}},
+		},
+		{
+			desc: "invalid formulae",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				homebrew:
+					formulae:
+						- homepage: nope
+						- name: foo/bar
+							assets:
+								macos: '*.tar.gz'
+								macos-x64: '*_x64.tar.gz'
+			`,
+			err: &config.Error{Errors: []string{
+				"homebrew.formulae[0].name is a required field",
+				"homebrew.formulae[0].homepage must be a valid URL",
+				"homebrew.formulae[0].assets is a required field",
+				"homebrew.formulae[1].name cannot contain any of the following characters '/'",
+				"homebrew.formulae[1].assets.macos is an excluded field",
+			}},
+		},
+		{
+			desc: "duplicate casks",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				homebrew:
+					casks:
+						- name: app
+							assets:
+								macos: '*.dmg'
+						- name: app
+							assets:
+								macos-arm64: '*.dmg'
+			`,
+			err: &config.Error{Errors: []string{"homebrew.casks must contain unique values"}},
+		},
+	})
+}
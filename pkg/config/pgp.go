@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/kubri/kubri/pkg/crypto/pgp"
+	"github.com/kubri/kubri/pkg/secret"
+)
+
+// pgpKeyName returns the name of the secret the nth PGP key is loaded from.
+// Keys are loaded from pgp_key and pgp_key_next, followed by any number of
+// keys in pgp_key_2, pgp_key_3 etc. Additional keys allow rotating keys by
+// signing with all of them until clients trust the new one.
+func pgpKeyName(n int) string {
+	switch n {
+	case 0:
+		return "pgp_key"
+	case 1:
+		return "pgp_key_next"
+	default:
+		return "pgp_key_" + strconv.Itoa(n)
+	}
+}
+
+func getPGPKeys() ([]*pgp.PrivateKey, error) {
+	var keys []*pgp.PrivateKey
+	for n := 0; ; n++ {
+		b, err := secret.Get(pgpKeyName(n))
+		if err != nil {
+			// Numbered keys end at the first one missing.
+			if n < 2 {
+				continue
+			}
+			return keys, nil
+		}
+		key, err := pgp.UnmarshalPrivateKey(b)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+}
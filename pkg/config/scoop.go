@@ -0,0 +1,65 @@
+package config
+
+import (
+	"cmp"
+
+	"github.com/kubri/kubri/integrations/scoop"
+)
+
+type scoopConfig struct {
+	Disabled bool   `yaml:"disabled,omitempty"`
+	Folder   string `yaml:"folder,omitempty"   validate:"omitempty,dirname"`
+	Apps     []struct {
+		Name        string `yaml:"name"                  validate:"required,excludesall=/"`
+		Description string `yaml:"description,omitempty"`
+		Homepage    string `yaml:"homepage,omitempty"    validate:"omitempty,http_url"`
+		License     string `yaml:"license,omitempty"`
+		Assets      struct {
+			X64   string `yaml:"64bit,omitempty"`
+			X86   string `yaml:"32bit,omitempty"`
+			ARM64 string `yaml:"arm64,omitempty"`
+		} `yaml:"assets" validate:"required"`
+		Bin      []string `yaml:"bin,omitempty"`
+		CheckVer *struct {
+			GitHub   string `yaml:"github,omitempty"   validate:"omitempty,http_url"`
+			URL      string `yaml:"url,omitempty"      validate:"required_without=GitHub,excluded_with=GitHub,omitempty,http_url"`
+			Regex    string `yaml:"regex,omitempty"`
+			JSONPath string `yaml:"jsonpath,omitempty"`
+		} `yaml:"checkver,omitempty"`
+	} `yaml:"apps,omitempty" validate:"unique=Name,dive"`
+}
+
+func getScoop(c *config) *scoop.Config {
+	apps := make([]*scoop.App, len(c.Scoop.Apps))
+	for i, app := range c.Scoop.Apps {
+		assets := map[scoop.Arch]string{}
+		for arch, glob := range map[scoop.Arch]string{
+			scoop.X64:   app.Assets.X64,
+			scoop.X86:   app.Assets.X86,
+			scoop.ARM64: app.Assets.ARM64,
+		} {
+			if glob != "" {
+				assets[arch] = glob
+			}
+		}
+
+		apps[i] = &scoop.App{
+			Name:        app.Name,
+			Description: app.Description,
+			Homepage:    app.Homepage,
+			License:     app.License,
+			Assets:      assets,
+			Bin:         app.Bin,
+			CheckVer:    (*scoop.CheckVer)(app.CheckVer),
+		}
+	}
+
+	return &scoop.Config{
+		Apps:           apps,
+		Source:         c.source,
+		Target:         c.target.Sub(cmp.Or(c.Scoop.Folder, "scoop")),
+		Version:        c.Version,
+		Prerelease:     c.Prerelease,
+		UploadPackages: c.UploadPackages,
+	}
+}
@@ -0,0 +1,178 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/kubri/kubri/integrations/scoop"
+	"github.com/kubri/kubri/pkg/config"
+	source "github.com/kubri/kubri/source/file"
+	target "github.com/kubri/kubri/target/file"
+)
+
+func TestScoop(t *testing.T) {
+	dir := t.TempDir()
+	src, _ := source.New(source.Config{Path: dir})
+	tgt, _ := target.New(target.Config{Path: dir})
+
+	runTest(t, []testCase{
+		{
+			desc: "disabled",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				scoop:
+					disabled: true
+			`,
+			want: &config.Config{},
+		},
+		{
+			desc: "defaults",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				scoop: {}
+			`,
+			want: &config.Config{
+				Scoop: &scoop.Config{
+					Apps:   []*scoop.App{},
+					Source: src,
+					Target: tgt.Sub("scoop"),
+				},
+			},
+		},
+		{
+			desc: "full",
+			in: `
+				version: latest
+				prerelease: true
+				upload-packages: true
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				scoop:
+					folder: bucket
+					apps:
+						- name: app
+							description: My app
+							homepage: https://example.com
+							license: MIT
+							assets:
+								64bit: '*_windows_amd64.zip'
+								32bit: '*_windows_386.zip'
+								arm64: '*_windows_arm64.zip'
+							bin: [app.exe]
+							checkver:
+								url: https://example.com/version.json
+								jsonpath: $.version
+						- name: app-cli
+							assets:
+								64bit: '*_cli_windows_amd64.zip'
+							checkver:
+								github: https://github.com/owner/repo
+			`,
+			want: &config.Config{
+				Scoop: &scoop.Config{
+					Apps: []*scoop.App{
+						{
+							Name:        "app",
+							Description: "My app",
+							Homepage:    "https://example.com",
+							License:     "MIT",
+							Assets: map[scoop.Arch]string{
+								scoop.X64:   "*_windows_amd64.zip",
+								scoop.X86:   "*_windows_386.zip",
+								scoop.ARM64: "*_windows_arm64.zip",
+							},
+							Bin:      []string{"app.exe"},
+							CheckVer: &scoop.CheckVer{URL: "https://example.com/version.json", JSONPath: "$.version"},
+						},
+						{
+							Name:     "app-cli",
+							Assets:   map[scoop.Arch]string{scoop.X64: "*_cli_windows_amd64.zip"},
+							CheckVer: &scoop.CheckVer{GitHub: "https://github.com/owner/repo"},
+						},
+					},
+					Source:         src,
+					Target:         tgt.Sub("bucket"),
+					Version:        "latest",
+					Prerelease:     true,
+					UploadPackages: true,
+				},
+			},
+		},
+		{
+			desc: "invalid folder",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				scoop:
+					folder: '*'
+			`,
+			err: &config.Error{Errors: []string{"scoop.folder must be a valid folder name"}},
+		},
+		{
+			desc: "invalid apps",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				scoop:
+					apps:
+						- homepage: nope
+							checkver: {}
+						- name: foo/bar
+							assets:
+								64bit: '*.zip'
+							checkver:
+								github: https://github.com/owner/repo
+								url: nope
+			`,
+			err: &config.Error{Errors: []string{
+				"scoop.apps[0].name is a required field",
+				"scoop.apps[0].homepage must be a valid URL",
+				"scoop.apps[0].assets is a required field",
+				"scoop.apps[0].checkver.url is a required field",
+				"scoop.apps[1].name cannot contain any of the following characters '/'",
+				"scoop.apps[1].checkver.url is an excluded field",
+			}},
+		},
+		{
+			desc: "duplicate apps",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				scoop:
+					apps:
+						- name: app
+							assets:
+								64bit: '*.zip'
+						- name: app
+							assets:
+								arm64: '*.zip'
+			`,
+			err: &config.Error{Errors: []string{"scoop.apps must contain unique values"}},
+		},
+	})
+}
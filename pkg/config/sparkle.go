@@ -11,14 +11,27 @@ import (
 )
 
 type sparkleConfig struct {
-	Disabled    bool                  `yaml:"disabled,omitempty"`
-	Folder      string                `yaml:"folder,omitempty"`
-	Title       string                `yaml:"title,omitempty"`
-	Description string                `yaml:"description,omitempty"`
-	Filename    string                `yaml:"filename,omitempty"`
-	DetectOS    map[sparkle.OS]string `yaml:"detect-os,omitempty"`
-	Params      []struct {
-		OS       sparkle.OS `yaml:"os,omitempty"      jsonschema:"type=string,enum=macos,enum=windows,enum=windows-x86,enum=windows-x64"` //nolint:lll
+	Disabled          bool                  `yaml:"disabled,omitempty"`
+	Folder            string                `yaml:"folder,omitempty"`
+	Title             string                `yaml:"title,omitempty"`
+	Description       string                `yaml:"description,omitempty"`
+	Filename          string                `yaml:"filename,omitempty"`
+	DetectOS          map[sparkle.OS]string `yaml:"detect-os,omitempty"`
+	Deltas            int                   `yaml:"deltas,omitempty"             validate:"gte=0" jsonschema:"minimum=0"`
+	PrereleaseChannel string                `yaml:"prerelease-channel,omitempty"`
+	Feeds             []struct {
+		Filename string     `yaml:"filename"          validate:"required"`
+		OS       sparkle.OS `yaml:"os,omitempty"      jsonschema:"type=string,enum=macos,enum=windows,enum=windows-x86,enum=windows-x64,enum=windows-arm64,enum=linux"` //nolint:lll
+		Assets   string     `yaml:"assets,omitempty"`
+		Version  string     `yaml:"version,omitempty" validate:"omitempty,version_constraint"`
+	} `yaml:"feeds,omitempty"              validate:"unique=Filename,dive"`
+	ReleaseNotes *struct {
+		Folder  string `yaml:"folder,omitempty"   validate:"omitempty,dirname"`
+		Dir     string `yaml:"dir,omitempty"      validate:"omitempty,dir"`
+		FullURL string `yaml:"full-url,omitempty" validate:"omitempty,http_url"`
+	} `yaml:"release-notes,omitempty"`
+	Params []struct {
+		OS       sparkle.OS `yaml:"os,omitempty"      jsonschema:"type=string,enum=macos,enum=windows,enum=windows-x86,enum=windows-x64,enum=windows-arm64,enum=linux"` //nolint:lll
 		Version  string     `yaml:"version,omitempty"`
 		Settings *struct {
 			InstallerArguments                string `yaml:"installer-arguments,omitempty"`
@@ -27,8 +40,10 @@ type sparkleConfig struct {
 			IgnoreSkippedUpgradesBelowVersion string `yaml:"ignore-skipped-upgrades-below-version,omitempty"`
 			CriticalUpdate                    bool   `yaml:"critical-update,omitempty"`
 			CriticalUpdateBelowVersion        string `yaml:"critical-update-below-version,omitempty"`
+			Channel                           string `yaml:"channel,omitempty"`
+			PhasedRolloutInterval             int    `yaml:"phased-rollout-interval,omitempty"               validate:"gte=0" jsonschema:"minimum=0"` //nolint:lll
 		} `yaml:",inline"`
-	} `yaml:"params,omitempty"`
+	} `yaml:"params,omitempty"             validate:"dive"`
 }
 
 func getSparkle(c *config) (*sparkle.Config, error) {
@@ -60,6 +75,16 @@ func getSparkle(c *config) (*sparkle.Config, error) {
 		}
 	}
 
+	feeds := make([]*sparkle.Feed, len(c.Sparkle.Feeds))
+	for i, f := range c.Sparkle.Feeds {
+		feeds[i] = &sparkle.Feed{
+			FileName: f.Filename,
+			OS:       f.OS,
+			Assets:   f.Assets,
+			Version:  f.Version,
+		}
+	}
+
 	params := make([]sparkle.Rule, len(c.Sparkle.Params))
 	for i, p := range c.Sparkle.Params {
 		params[i] = sparkle.Rule{
@@ -70,13 +95,17 @@ func getSparkle(c *config) (*sparkle.Config, error) {
 	}
 
 	return &sparkle.Config{
-		Title:       cmp.Or(c.Sparkle.Title, c.Title),
-		Description: cmp.Or(c.Sparkle.Description, c.Description),
-		FileName:    cmp.Or(c.Sparkle.Filename, "appcast.xml"),
-		DSAKey:      dsaKey,
-		Ed25519Key:  edKey,
-		Settings:    params,
-		DetectOS:    detectOS,
+		Title:             cmp.Or(c.Sparkle.Title, c.Title),
+		Description:       cmp.Or(c.Sparkle.Description, c.Description),
+		FileName:          cmp.Or(c.Sparkle.Filename, "appcast.xml"),
+		DSAKey:            dsaKey,
+		Ed25519Key:        edKey,
+		Settings:          params,
+		DetectOS:          detectOS,
+		Deltas:            c.Sparkle.Deltas,
+		PrereleaseChannel: c.Sparkle.PrereleaseChannel,
+		Feeds:             feeds,
+		ReleaseNotes:      (*sparkle.ReleaseNotes)(c.Sparkle.ReleaseNotes),
 
 		Source:         c.source,
 		Target:         c.target.Sub(cmp.Or(c.Sparkle.Folder, "sparkle")),
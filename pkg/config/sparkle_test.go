@@ -62,6 +62,7 @@ func TestSparkle(t *testing.T) {
 					Description: "description",
 					FileName:    "appcast.xml",
 					Settings:    []sparkle.Rule{},
+					Feeds:       []*sparkle.Feed{},
 					Source:      src,
 					Target:      tgt.Sub("sparkle"),
 				},
@@ -86,6 +87,19 @@ func TestSparkle(t *testing.T) {
 					description: description
 					folder: test
 					filename: test.xml
+					deltas: 3
+					prerelease-channel: beta
+					feeds:
+						- filename: macos-arm64.xml
+							os: macos
+							assets: '*_arm64.dmg'
+						- filename: windows.xml
+							os: windows
+							version: '>= 1.1.0'
+					release-notes:
+						folder: notes
+						dir: ` + dir + `
+						full-url: https://example.com/changelog.html
 					params:
 						- os: windows
 							installer-arguments: /passive
@@ -98,6 +112,9 @@ func TestSparkle(t *testing.T) {
 							minimum-autoupdate-version: '1.0.0'
 						- version: '1.1.0'
 							ignore-skipped-upgrades-below-version: '1.1.0'
+						- version: '> 1.1.0'
+							channel: staging
+							phased-rollout-interval: 86400
 			`,
 			hook: func() {
 				secret.Put("dsa_key", dsaBytes)
@@ -105,11 +122,22 @@ func TestSparkle(t *testing.T) {
 			},
 			want: &config.Config{
 				Sparkle: &sparkle.Config{
-					Title:       "title",
-					Description: "description",
-					FileName:    "test.xml",
-					DSAKey:      dsaKey,
-					Ed25519Key:  edKey,
+					Title:             "title",
+					Description:       "description",
+					FileName:          "test.xml",
+					DSAKey:            dsaKey,
+					Ed25519Key:        edKey,
+					Deltas:            3,
+					PrereleaseChannel: "beta",
+					Feeds: []*sparkle.Feed{
+						{FileName: "macos-arm64.xml", OS: sparkle.MacOS, Assets: "*_arm64.dmg"},
+						{FileName: "windows.xml", OS: sparkle.Windows, Version: ">= 1.1.0"},
+					},
+					ReleaseNotes: &sparkle.ReleaseNotes{
+						Folder:  "notes",
+						Dir:     dir,
+						FullURL: "https://example.com/changelog.html",
+					},
 					Settings: []sparkle.Rule{
 						{
 							OS: sparkle.Windows,
@@ -142,6 +170,13 @@ func TestSparkle(t *testing.T) {
 								IgnoreSkippedUpgradesBelowVersion: "1.1.0",
 							},
 						},
+						{
+							Version: "> 1.1.0",
+							Settings: &sparkle.Settings{
+								Channel:               "staging",
+								PhasedRolloutInterval: 86400,
+							},
+						},
 					},
 					Source:         src,
 					Target:         tgt.Sub("test"),
@@ -151,6 +186,92 @@ func TestSparkle(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "invalid deltas",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				sparkle:
+					deltas: -1
+			`,
+			err: &config.Error{Errors: []string{"sparkle.deltas must be 0 or greater"}},
+		},
+		{
+			desc: "invalid feeds",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				sparkle:
+					feeds:
+						- filename: test.xml
+							version: nope
+						- os: macos
+			`,
+			err: &config.Error{Errors: []string{
+				"sparkle.feeds[0].version must be a valid version constraint",
+				"sparkle.feeds[1].filename is a required field",
+			}},
+		},
+		{
+			desc: "duplicate feeds",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				sparkle:
+					feeds:
+						- filename: test.xml
+						- filename: test.xml
+			`,
+			err: &config.Error{Errors: []string{"sparkle.feeds must contain unique values"}},
+		},
+		{
+			desc: "invalid release notes",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				sparkle:
+					release-notes:
+						folder: ../notes
+						dir: ` + filepath.Join(dir, "nope") + `
+						full-url: nope
+			`,
+			err: &config.Error{Errors: []string{
+				"sparkle.release-notes.folder must be a valid folder name",
+				"sparkle.release-notes.dir must be a valid path to a directory",
+				"sparkle.release-notes.full-url must be a valid URL",
+			}},
+		},
+		{
+			desc: "invalid phased rollout interval",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				sparkle:
+					params:
+						- phased-rollout-interval: -1
+			`,
+			err: &config.Error{Errors: []string{"sparkle.params[0].phased-rollout-interval must be 0 or greater"}},
+		},
 		{
 			desc: "invalid dsa key",
 			in: `
@@ -194,6 +315,7 @@ func TestSparkleDetectOS(t *testing.T) {
 			detect-os:
 				macos: '*.dmg'
 				windows: '*.exe'
+				linux: '*.zip'
 	`
 
 	tests := []struct {
@@ -208,6 +330,10 @@ func TestSparkleDetectOS(t *testing.T) {
 			in:   "test.exe",
 			want: sparkle.Windows,
 		},
+		{
+			in:   "test.zip",
+			want: sparkle.Linux,
+		},
 		{
 			in:   "unknown",
 			want: sparkle.Unknown,
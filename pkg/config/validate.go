@@ -68,6 +68,10 @@ func registerTranslations(v *validator.Validate, trans ut.Translator) error {
 			name:    "dir",
 			message: "{0} must be a valid path to a directory",
 		},
+		{
+			name:    "file",
+			message: "{0} must be a valid path to a file",
+		},
 		{
 			name:    "dirname",
 			message: "{0} must be a valid folder name",
@@ -2,31 +2,67 @@ package config
 
 import (
 	"cmp"
+	"os"
 
 	"github.com/kubri/kubri/integrations/yum"
-	"github.com/kubri/kubri/pkg/crypto/pgp"
-	"github.com/kubri/kubri/pkg/secret"
 )
 
 type yumConfig struct {
-	Disabled bool   `yaml:"disabled,omitempty"`
-	Folder   string `yaml:"folder,omitempty"   validate:"omitempty,dirname"`
+	Disabled     bool              `yaml:"disabled,omitempty"`
+	Folder       string            `yaml:"folder,omitempty"        validate:"omitempty,dirname"`
+	SignPackages bool              `yaml:"sign-packages,omitempty"`
+	Name         string            `yaml:"name,omitempty"          validate:"omitempty,slug"`
+	Title        string            `yaml:"title,omitempty"`
+	RepoOptions  map[string]string `yaml:"repo-options,omitempty"`
+	UpdateInfo   bool              `yaml:"updateinfo,omitempty"`
+	SQLite       string            `yaml:"sqlite,omitempty"        validate:"omitempty,oneof=bzip2 xz" jsonschema:"enum=bzip2,enum=xz"`
+	Advisories   []struct {
+		Version  string   `yaml:"version"            validate:"required,version_constraint"`
+		Type     string   `yaml:"type,omitempty"     validate:"omitempty,oneof=bugfix enhancement newpackage security" jsonschema:"enum=bugfix,enum=enhancement,enum=newpackage,enum=security"` //nolint:lll
+		Severity string   `yaml:"severity,omitempty" validate:"omitempty,oneof=Critical Important Moderate Low"        jsonschema:"enum=Critical,enum=Important,enum=Moderate,enum=Low"`        //nolint:lll
+		CVEs     []string `yaml:"cves,omitempty"`
+	} `yaml:"advisories,omitempty" validate:"dive"`
+	Groups  string `yaml:"groups,omitempty"  validate:"omitempty,file"`
+	Modules string `yaml:"modules,omitempty" validate:"omitempty,file"`
 }
 
 func getYum(c *config) (*yum.Config, error) {
-	var pgpKey *pgp.PrivateKey
-	if b, err := secret.Get("pgp_key"); err == nil {
-		pgpKey, err = pgp.UnmarshalPrivateKey(b)
-		if err != nil {
+	pgpKeys, err := getPGPKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var groups, modules []byte
+	if c.Yum.Groups != "" {
+		if groups, err = os.ReadFile(c.Yum.Groups); err != nil {
 			return nil, err
 		}
 	}
+	if c.Yum.Modules != "" {
+		if modules, err = os.ReadFile(c.Yum.Modules); err != nil {
+			return nil, err
+		}
+	}
+
+	var advisories []yum.Advisory
+	for _, a := range c.Yum.Advisories {
+		advisories = append(advisories, yum.Advisory(a))
+	}
 
 	return &yum.Config{
-		Source:     c.source,
-		Target:     c.target.Sub(cmp.Or(c.Yum.Folder, "yum")),
-		Version:    c.Version,
-		Prerelease: c.Prerelease,
-		PGPKey:     pgpKey,
+		Source:       c.source,
+		Target:       c.target.Sub(cmp.Or(c.Yum.Folder, "yum")),
+		Version:      c.Version,
+		Prerelease:   c.Prerelease,
+		PGPKeys:      pgpKeys,
+		SignPackages: c.Yum.SignPackages,
+		Name:         c.Yum.Name,
+		Title:        c.Yum.Title,
+		RepoOptions:  c.Yum.RepoOptions,
+		UpdateInfo:   c.Yum.UpdateInfo,
+		SQLite:       c.Yum.SQLite,
+		Advisories:   advisories,
+		Groups:       groups,
+		Modules:      modules,
 	}, nil
 }
@@ -2,6 +2,8 @@ package config_test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kubri/kubri/integrations/yum"
@@ -19,6 +21,10 @@ func TestYum(t *testing.T) {
 	tgt, _ := target.New(target.Config{Path: dir})
 	key, _ := pgp.NewPrivateKey("test", "test@example.com")
 	keyBytes, _ := pgp.MarshalPrivateKey(key)
+	groups := []byte("<comps></comps>")
+	modules := []byte("document: modulemd\n")
+	_ = os.WriteFile(filepath.Join(dir, "comps.xml"), groups, 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "modules.yaml"), modules, 0o600)
 
 	runTest(t, []testCase{
 		{
@@ -66,15 +72,43 @@ func TestYum(t *testing.T) {
 					path: ` + dir + `
 				yum:
 					folder: test
+					sign-packages: true
+					name: test
+					title: Test
+					repo-options:
+						priority: '10'
+					updateinfo: true
+					sqlite: xz
+					advisories:
+						- version: '>=1.2.0, <1.2.3'
+							type: security
+							severity: Important
+							cves: [CVE-2023-0001]
+						- version: '2'
+							type: enhancement
+					groups: ` + filepath.Join(dir, "comps.xml") + `
+					modules: ` + filepath.Join(dir, "modules.yaml") + `
 			`,
 			hook: func() { secret.Put("pgp_key", keyBytes) },
 			want: &config.Config{
 				Yum: &yum.Config{
-					Source:     src,
-					Target:     tgt.Sub("test"),
-					Version:    "latest",
-					Prerelease: true,
-					PGPKey:     key,
+					Source:       src,
+					Target:       tgt.Sub("test"),
+					Version:      "latest",
+					Prerelease:   true,
+					PGPKeys:      []*pgp.PrivateKey{key},
+					SignPackages: true,
+					Name:         "test",
+					Title:        "Test",
+					RepoOptions:  map[string]string{"priority": "10"},
+					UpdateInfo:   true,
+					SQLite:       "xz",
+					Advisories: []yum.Advisory{
+						{Version: ">=1.2.0, <1.2.3", Type: "security", Severity: "Important", CVEs: []string{"CVE-2023-0001"}},
+						{Version: "2", Type: "enhancement"},
+					},
+					Groups:  groups,
+					Modules: modules,
 				},
 			},
 		},
@@ -106,5 +140,33 @@ func TestYum(t *testing.T) {
 			`,
 			err: &config.Error{Errors: []string{"yum.folder must be a valid folder name"}},
 		},
+		{
+			desc: "invalid fields",
+			in: `
+				source:
+					type: file
+					path: ` + dir + `
+				target:
+					type: file
+					path: ` + dir + `
+				yum:
+					name: '*'
+					sqlite: gzip
+					advisories:
+						- type: nope
+							severity: high
+					groups: ` + filepath.Join(dir, "nope.xml") + `
+			`,
+			err: &config.Error{
+				Errors: []string{
+					"yum.name must only contain letters, numbers, dashes and underscores",
+					"yum.sqlite must be one of [bzip2 xz]",
+					"yum.advisories[0].version is a required field",
+					"yum.advisories[0].type must be one of [bugfix enhancement newpackage security]",
+					"yum.advisories[0].severity must be one of [Critical Important Moderate Low]",
+					"yum.groups must be a valid path to a file",
+				},
+			},
+		},
 	})
 }
@@ -59,6 +59,24 @@ func Public(key *PrivateKey) *PublicKey {
 	return pub
 }
 
+// PublicKeys returns the public keys of the private keys.
+func PublicKeys(keys []*PrivateKey) []*PublicKey {
+	pub := make([]*PublicKey, len(keys))
+	for i, key := range keys {
+		pub[i] = Public(key)
+	}
+	return pub
+}
+
+// Keys returns the keys, or only key if keys is empty. It is used to support
+// deprecated config fields holding a single key.
+func Keys(keys []*PrivateKey, key *PrivateKey) []*PrivateKey {
+	if key == nil || len(keys) > 0 {
+		return keys
+	}
+	return []*PrivateKey{key}
+}
+
 // MarshalPublicKey returns the armored public key.
 func MarshalPublicKey(key *PublicKey) ([]byte, error) {
 	if key == nil {
@@ -74,6 +92,42 @@ func MarshalPublicKey(key *PublicKey) ([]byte, error) {
 	return []byte(s), nil
 }
 
+// MarshalKeyring returns the public keys as a binary keyring, such as the ones
+// referenced by APT's signed-by option.
+func MarshalKeyring(keys ...*PublicKey) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, crypto.ErrInvalidKey
+	}
+	var b []byte
+	for _, key := range keys {
+		if key == nil {
+			return nil, crypto.ErrInvalidKey
+		}
+		if key.IsPrivate() {
+			return nil, crypto.ErrWrongKeyType
+		}
+		pub, err := key.GetPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, pub...)
+	}
+	return b, nil
+}
+
+// MarshalPublicKeys returns the public keys in a single armored block.
+func MarshalPublicKeys(keys ...*PublicKey) ([]byte, error) {
+	b, err := MarshalKeyring(keys...)
+	if err != nil {
+		return nil, err
+	}
+	s, err := armor.ArmorWithTypeAndCustomHeaders(b, constants.PublicKeyHeader, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
 // UnmarshalPublicKey returns a public key from an armored key.
 func UnmarshalPublicKey(b []byte) (*PublicKey, error) {
 	key, err := pgpcrypto.NewKeyFromArmoredReader(bytes.NewReader(b))
@@ -89,20 +143,36 @@ func UnmarshalPublicKey(b []byte) (*PublicKey, error) {
 // Sign signs the data with the private key and returns the binary signature.
 // Data is considered binary and not canonicalised.
 func Sign(key *PrivateKey, data []byte) ([]byte, error) {
-	return sign(key, data, false)
+	return sign([]*PrivateKey{key}, data, false)
 }
 
 // SignText signs the data with the private key and wraps it in an armored signature.
 // Data is considered text and canonicalised with CRLF line endings.
 func SignText(key *PrivateKey, data []byte) ([]byte, error) {
-	return sign(key, data, true)
+	return sign([]*PrivateKey{key}, data, true)
 }
 
 // ClearSign signs the data with the private key and wraps it in a signed message.
 // Data is considered text and canonicalised with CRLF line endings.
 func ClearSign(key *PrivateKey, data []byte) ([]byte, error) {
+	return clearSign([]*PrivateKey{key}, data)
+}
+
+// MultiSignText is like SignText but signs the data with each of the private
+// keys, returning all signatures in a single armored signature.
+func MultiSignText(keys []*PrivateKey, data []byte) ([]byte, error) {
+	return sign(keys, data, true)
+}
+
+// MultiClearSign is like ClearSign but signs the data with each of the private
+// keys, returning all signatures in a single signed message.
+func MultiClearSign(keys []*PrivateKey, data []byte) ([]byte, error) {
+	return clearSign(keys, data)
+}
+
+func clearSign(keys []*PrivateKey, data []byte) ([]byte, error) {
 	data = bytes.ReplaceAll(data, lf, crlf)
-	sig, err := sign(key, data, true)
+	sig, err := sign(keys, data, true)
 	if err != nil {
 		return nil, err
 	}
@@ -114,34 +184,44 @@ func ClearSign(key *PrivateKey, data []byte) ([]byte, error) {
 	return b, nil
 }
 
-func sign(key *PrivateKey, data []byte, text bool) ([]byte, error) {
-	if key == nil {
+func sign(keys []*PrivateKey, data []byte, text bool) ([]byte, error) {
+	if len(keys) == 0 {
 		return nil, crypto.ErrInvalidKey
 	}
-	if !key.IsPrivate() {
-		return nil, crypto.ErrWrongKeyType
-	}
-
-	// TODO: Unlock locked key using env var passphrase.
-
-	keyring, err := pgpcrypto.NewKeyRing(key)
-	if err != nil {
-		return nil, err
-	}
 
 	msg := pgpcrypto.NewPlainMessage(data)
 	msg.TextType = text
 
-	signature, err := keyring.SignDetached(msg)
-	if err != nil {
-		return nil, err
+	var sigs []byte
+	for _, key := range keys {
+		if key == nil {
+			return nil, crypto.ErrInvalidKey
+		}
+		if !key.IsPrivate() {
+			return nil, crypto.ErrWrongKeyType
+		}
+
+		// TODO: Unlock locked key using env var passphrase.
+
+		keyring, err := pgpcrypto.NewKeyRing(key)
+		if err != nil {
+			return nil, err
+		}
+
+		signature, err := keyring.SignDetached(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		// Signature packets can simply be concatenated to form a multi-signature.
+		sigs = append(sigs, signature.Data...)
 	}
 
 	if !text {
-		return signature.Data, nil
+		return sigs, nil
 	}
 
-	sig, err := armor.ArmorWithTypeAndCustomHeaders(signature.Data, constants.PGPSignatureHeader, "", "")
+	sig, err := armor.ArmorWithTypeAndCustomHeaders(sigs, constants.PGPSignatureHeader, "", "")
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,15 @@
 package pgp_test
 
 import (
+	"bytes"
 	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/kubri/kubri/internal/test"
@@ -110,6 +113,110 @@ func TestPGP(t *testing.T) {
 		}
 	})
 
+	t.Run("MarshalKeyring", func(t *testing.T) {
+		priv2, _ := pgp.NewPrivateKey("test2", "test2@example.com")
+		pub2 := pgp.Public(priv2)
+
+		b, err := pgp.MarshalKeyring(pub, pub2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entities, err := openpgp.ReadKeyRing(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entities) != 2 {
+			t.Fatalf("should contain 2 keys got %d", len(entities))
+		}
+
+		if _, err := pgp.MarshalKeyring(nil); !errors.Is(err, crypto.ErrInvalidKey) {
+			t.Errorf("nil key should return error %q got %q", crypto.ErrInvalidKey, err)
+		}
+		if _, err := pgp.MarshalKeyring(priv); !errors.Is(err, crypto.ErrWrongKeyType) {
+			t.Errorf("private key should return error %q got %q", crypto.ErrWrongKeyType, err)
+		}
+	})
+
+	t.Run("MarshalPublicKeys", func(t *testing.T) {
+		priv2, _ := pgp.NewPrivateKey("test2", "test2@example.com")
+		pub2 := pgp.Public(priv2)
+
+		b, err := pgp.MarshalPublicKeys(pub, pub2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entities) != 2 {
+			t.Fatalf("should contain 2 keys got %d", len(entities))
+		}
+
+		got, err := pgp.MarshalPublicKeys(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(pubBytes), string(got)); diff != "" {
+			t.Error("single key should match MarshalPublicKey", diff)
+		}
+	})
+
+	t.Run("MultiSign", func(t *testing.T) {
+		priv2, _ := pgp.NewPrivateKey("test2", "test2@example.com")
+		pub2 := pgp.Public(priv2)
+		keys := []*pgp.PrivateKey{priv, priv2}
+
+		sigAsc, err := pgp.MultiSignText(keys, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := pgp.MultiClearSign(keys, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotData, gotSig, err := pgp.Split(signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(data), string(gotData)); diff != "" {
+			t.Error(diff)
+		}
+
+		for i, key := range []*pgp.PublicKey{pub, pub2} {
+			if !pgp.VerifyText(key, data, sigAsc) {
+				t.Errorf("key %d should verify armored signature", i)
+			}
+			if !pgp.VerifyText(key, gotData, gotSig) {
+				t.Errorf("key %d should verify signed message", i)
+			}
+		}
+
+		if _, err := pgp.MultiSignText(nil, data); !errors.Is(err, crypto.ErrInvalidKey) {
+			t.Errorf("no keys should return error %q got %q", crypto.ErrInvalidKey, err)
+		}
+		if _, err := pgp.MultiSignText([]*pgp.PrivateKey{priv, pub}, data); !errors.Is(err, crypto.ErrWrongKeyType) {
+			t.Errorf("public key should return error %q got %q", crypto.ErrWrongKeyType, err)
+		}
+	})
+
+	t.Run("Keys", func(t *testing.T) {
+		priv2, _ := pgp.NewPrivateKey("test2", "test2@example.com")
+		keys := []*pgp.PrivateKey{priv2}
+
+		if got := pgp.Keys(keys, priv); !slices.Equal(got, keys) {
+			t.Error("should return keys if set")
+		}
+		if got := pgp.Keys(nil, priv); !slices.Equal(got, []*pgp.PrivateKey{priv}) {
+			t.Error("should return key if keys are empty")
+		}
+		if got := pgp.Keys(nil, nil); got != nil {
+			t.Error("should return nil without keys")
+		}
+	})
+
 	t.Run("Split", func(t *testing.T) {
 		tests := []struct {
 			name string
@@ -108,6 +108,15 @@ func (s *githubSource) UploadAsset(ctx context.Context, version, name string, da
 }
 
 func (s *githubSource) DownloadAsset(ctx context.Context, version, name string) ([]byte, error) {
+	r, err := s.OpenAsset(ctx, version, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (s *githubSource) OpenAsset(ctx context.Context, version, name string) (io.ReadCloser, error) {
 	release, _, err := s.client.Repositories.GetReleaseByTag(ctx, s.owner, s.repo, version)
 	if err != nil {
 		return nil, err
@@ -116,11 +125,7 @@ func (s *githubSource) DownloadAsset(ctx context.Context, version, name string)
 	for _, asset := range release.Assets {
 		if asset.GetName() == name {
 			r, _, err := s.client.Repositories.DownloadReleaseAsset(ctx, s.owner, s.repo, asset.GetID(), s.client.Client())
-			if err != nil {
-				return nil, err
-			}
-			defer r.Close()
-			return io.ReadAll(r)
+			return r, err
 		}
 	}
 
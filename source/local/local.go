@@ -3,6 +3,7 @@ package local
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -81,23 +82,39 @@ func (s *localSource) UploadAsset(_ context.Context, _, name string, data []byte
 }
 
 func (s *localSource) DownloadAsset(_ context.Context, _, name string) ([]byte, error) {
+	path, err := s.getPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (s *localSource) OpenAsset(_ context.Context, _, name string) (io.ReadCloser, error) {
+	path, err := s.getPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *localSource) getPath(name string) (string, error) {
 	path := filepath.Join(s.root, name)
 	if _, err := os.Stat(path); err == nil {
-		return os.ReadFile(path)
+		return path, nil
 	}
 
 	files, err := getFiles(s.path)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	for _, path := range files {
 		if filepath.Base(path) == name {
-			return os.ReadFile(path)
+			return path, nil
 		}
 	}
 
-	return nil, source.ErrAssetNotFound
+	return "", source.ErrAssetNotFound
 }
 
 func getFiles(path string) ([]string, error) {
@@ -1,7 +1,9 @@
 package source
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"log"
 	"sort"
 	"time"
@@ -34,6 +36,11 @@ type Driver interface {
 	UploadAsset(ctx context.Context, version, name string, data []byte) error
 }
 
+// AssetOpener is implemented by drivers which can stream assets.
+type AssetOpener interface {
+	OpenAsset(ctx context.Context, version, name string) (io.ReadCloser, error)
+}
+
 type Source struct {
 	s Driver
 }
@@ -125,6 +132,24 @@ func (s *Source) DownloadAsset(ctx context.Context, version, name string) ([]byt
 	return s.s.DownloadAsset(ctx, version, name)
 }
 
+// OpenAsset returns a reader for an asset. If the driver doesn't implement
+// AssetOpener, the asset is downloaded into memory.
+func (s *Source) OpenAsset(ctx context.Context, version, name string) (io.ReadCloser, error) {
+	if s == nil || s.s == nil {
+		return nil, ErrMissingSource
+	}
+
+	if o, ok := s.s.(AssetOpener); ok {
+		return o.OpenAsset(ctx, version, name)
+	}
+
+	b, err := s.s.DownloadAsset(ctx, version, name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
 func (s *Source) UploadAsset(ctx context.Context, version, name string, data []byte) error {
 	if s == nil || s.s == nil {
 		return ErrMissingSource
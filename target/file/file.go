@@ -4,6 +4,7 @@ package file
 import (
 	"context"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -49,6 +50,10 @@ func (t *fileTarget) NewReader(_ context.Context, filename string) (io.ReadClose
 	return os.Open(filepath.Join(t.path, filename))
 }
 
+func (t *fileTarget) ReadDir(_ context.Context, dir string) ([]fs.DirEntry, error) {
+	return os.ReadDir(filepath.Join(t.path, dir))
+}
+
 func (t *fileTarget) Remove(_ context.Context, filename string) error {
 	return os.Remove(filepath.Join(t.path, filename))
 }
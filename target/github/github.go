@@ -9,7 +9,10 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/google/go-github/v83/github"
 	"golang.org/x/oauth2"
@@ -87,6 +90,28 @@ func (t *githubTarget) NewReader(ctx context.Context, filename string) (io.ReadC
 	return io.NopCloser(strings.NewReader(content)), nil
 }
 
+func (t *githubTarget) ReadDir(ctx context.Context, dir string) ([]fs.DirEntry, error) {
+	opt := &github.RepositoryContentGetOptions{Ref: t.branch}
+	_, files, r, err := t.client.GetContents(ctx, t.owner, t.repo, path.Join(t.path, dir), opt)
+	if err != nil {
+		if r != nil && r.StatusCode == http.StatusNotFound {
+			return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+		}
+		return nil, err
+	}
+	if files == nil {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: syscall.ENOTDIR}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, fs.FileInfoToDirEntry(&fileInfo{f}))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return entries, nil
+}
+
 func (t *githubTarget) Remove(ctx context.Context, filename string) error {
 	path := path.Join(t.path, filename)
 	getOpt := &github.RepositoryContentGetOptions{Ref: t.branch}
@@ -115,6 +140,23 @@ func (t *githubTarget) URL(_ context.Context, filename string) (string, error) {
 	return "https://raw.githubusercontent.com/" + path.Join(t.owner, t.repo, t.branch, t.path, filename), nil
 }
 
+type fileInfo struct {
+	*github.RepositoryContent
+}
+
+func (fi *fileInfo) Name() string       { return fi.GetName() }
+func (fi *fileInfo) Size() int64        { return int64(fi.GetSize()) }
+func (fi *fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *fileInfo) IsDir() bool        { return fi.GetType() == "dir" }
+func (fi *fileInfo) Sys() any           { return fi.RepositoryContent }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.IsDir() {
+		return fs.ModeDir
+	}
+	return 0
+}
+
 type fileWriter struct {
 	bytes.Buffer
 
@@ -2,6 +2,7 @@ package target
 
 import (
 	"context"
+	"errors"
 	"io"
 	"io/fs"
 )
@@ -14,6 +15,22 @@ type Target interface {
 	URL(ctx context.Context, path string) (string, error)
 }
 
+// DirReader is implemented by targets which can list the contents of a
+// directory.
+type DirReader interface {
+	ReadDir(ctx context.Context, path string) ([]fs.DirEntry, error)
+}
+
+// ReadDir returns the entries of the directory at path on the target t, sorted
+// by name. It returns [errors.ErrUnsupported] if t doesn't implement
+// [DirReader].
+func ReadDir(ctx context.Context, t Target, path string) ([]fs.DirEntry, error) {
+	if r, ok := t.(DirReader); ok {
+		return r.ReadDir(ctx, path)
+	}
+	return nil, errors.ErrUnsupported
+}
+
 // CopyFS copies the file system fsys to the target t.
 func CopyFS(ctx context.Context, t Target, fsys fs.FS) error {
 	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {